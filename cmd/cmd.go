@@ -19,7 +19,9 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"github.com/apache/apisix-ingress-controller/cmd/diff"
 	"github.com/apache/apisix-ingress-controller/cmd/ingress"
+	"github.com/apache/apisix-ingress-controller/cmd/translate"
 	"github.com/apache/apisix-ingress-controller/pkg/version"
 )
 
@@ -50,6 +52,8 @@ func NewAPISIXIngressControllerCommand() *cobra.Command {
 	}
 
 	cmd.AddCommand(ingress.NewIngressCommand())
+	cmd.AddCommand(translate.NewTranslateCommand())
+	cmd.AddCommand(diff.NewDiffCommand())
 	cmd.AddCommand(newVersionCommand())
 	return cmd
 }
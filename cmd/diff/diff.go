@@ -0,0 +1,237 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package diff
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/yaml"
+
+	"github.com/apache/apisix-ingress-controller/pkg/apisix"
+	"github.com/apache/apisix-ingress-controller/pkg/config"
+	"github.com/apache/apisix-ingress-controller/pkg/kube"
+	clientset "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/client/clientset/versioned"
+	"github.com/apache/apisix-ingress-controller/pkg/offline"
+)
+
+const (
+	_outputText = "text"
+	_outputJSON = "json"
+	_outputYAML = "yaml"
+)
+
+func dief(template string, args ...interface{}) {
+	if !strings.HasSuffix(template, "\n") {
+		template += "\n"
+	}
+	fmt.Fprintf(os.Stderr, template, args...)
+	os.Exit(1)
+}
+
+type options struct {
+	files   []string
+	output  string
+	timeout time.Duration
+	cfg     *config.Config
+}
+
+// changes is the structured output of the diff command.
+type changes struct {
+	Added   *offline.Result `json:"added" yaml:"added"`
+	Changed *offline.Result `json:"changed" yaml:"changed"`
+	Deleted *offline.Result `json:"deleted" yaml:"deleted"`
+}
+
+// NewDiffCommand creates the diff sub command for apisix-ingress-controller.
+func NewDiffCommand() *cobra.Command {
+	opts := &options{
+		cfg: config.NewDefaultConfig(),
+	}
+	cmd := &cobra.Command{
+		Use: "diff [flags]",
+		Long: `show differences between the desired APISIX resources and the APISIX cluster
+
+The desired resources are translated from the given manifests (use -f), or from the
+objects in the Kubernetes cluster (use --kubeconfig, or in-cluster configuration will be used),
+then they are compared with the resources managed by apisix-ingress-controller in APISIX.
+Nothing will be changed, it's useful to preview the effect of a change or an upgrade.
+Objects which fail to be translated are reported after the differences, and the command
+exits with a non-zero status.
+
+    apisix-ingress-controller diff -f /path/to/manifests --default-apisix-cluster-base-url http://apisix-admin:9180/apisix/admin
+
+    apisix-ingress-controller diff --kubeconfig /path/to/kubeconfig --default-apisix-cluster-base-url http://apisix-admin:9180/apisix/admin
+
+Ingress class, Ingress version and ApisixRoute version options should be same as the running controller.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := run(context.Background(), cmd.OutOrStdout(), opts); err != nil {
+				dief("%s", err)
+			}
+		},
+	}
+
+	cmd.PersistentFlags().StringSliceVarP(&opts.files, "filename", "f", nil, "manifest files or directories which contain the manifests, objects in Kubernetes will be used if it's empty")
+	cmd.PersistentFlags().StringVarP(&opts.output, "output", "o", _outputText, "output format, text, json or yaml")
+	cmd.PersistentFlags().DurationVar(&opts.timeout, "timeout", time.Minute, "timeout for listing objects from Kubernetes and APISIX")
+	cmd.PersistentFlags().StringVar(&opts.cfg.Kubernetes.Kubeconfig, "kubeconfig", "", "Kubernetes configuration file (by default in-cluster configuration will be used)")
+	cmd.PersistentFlags().StringVar(&opts.cfg.Kubernetes.IngressClass, "ingress-class", config.IngressClass, "the class of Ingresses to be translated")
+	cmd.PersistentFlags().StringVar(&opts.cfg.Kubernetes.IngressVersion, "ingress-version", config.IngressNetworkingV1, "the supported ingress api group version, can be \"networking/v1beta1\", \"networking/v1\" (for Kubernetes version v1.19.0 or higher) and \"extensions/v1beta1\"")
+	cmd.PersistentFlags().StringVar(&opts.cfg.Kubernetes.ApisixRouteVersion, "apisix-route-version", config.ApisixRouteV2alpha1, "the supported apisixroute api group version, can be \"apisix.apache.org/v1\", \"apisix.apache.org/v2alpha1\" or \"apisix.apache.org/v2beta1\"")
+	cmd.PersistentFlags().StringVar(&opts.cfg.APISIX.DefaultClusterBaseURL, "default-apisix-cluster-base-url", "", "the base URL of admin api / manager api for the default APISIX cluster")
+	cmd.PersistentFlags().StringVar(&opts.cfg.APISIX.DefaultClusterAdminKey, "default-apisix-cluster-admin-key", "", "admin key used for the authorization of admin api / manager api for the default APISIX cluster")
+
+	return cmd
+}
+
+func run(ctx context.Context, w io.Writer, opts *options) error {
+	if opts.output != _outputText && opts.output != _outputJSON && opts.output != _outputYAML {
+		return fmt.Errorf("unsupported output format: %s", opts.output)
+	}
+	if opts.cfg.APISIX.DefaultClusterBaseURL == "" {
+		return errors.New("no APISIX cluster specified, use --default-apisix-cluster-base-url to specify it")
+	}
+	ctx, cancel := context.WithTimeout(ctx, opts.timeout)
+	defer cancel()
+
+	var (
+		kubeClient   kubernetes.Interface
+		apisixClient clientset.Interface
+	)
+	if len(opts.files) > 0 {
+		objs, err := offline.LoadObjects(opts.files...)
+		if err != nil {
+			return fmt.Errorf("failed to load manifests: %s", err)
+		}
+		kubeClient, apisixClient = offline.NewFakeClients(objs)
+	} else {
+		kubeCli, err := kube.NewKubeClient(opts.cfg)
+		if err != nil {
+			return fmt.Errorf("failed to create Kubernetes client: %s", err)
+		}
+		kubeClient, apisixClient = kubeCli.Client, kubeCli.APISIXClient
+	}
+
+	desired, translateErr := offline.Translate(ctx, kubeClient, apisixClient, &offline.Options{
+		IngressClass:       opts.cfg.Kubernetes.IngressClass,
+		IngressVersion:     opts.cfg.Kubernetes.IngressVersion,
+		ApisixRouteVersion: opts.cfg.Kubernetes.ApisixRouteVersion,
+	})
+	if desired == nil {
+		return fmt.Errorf("failed to translate objects: %s", translateErr)
+	}
+
+	cli, err := apisix.NewClient()
+	if err != nil {
+		return err
+	}
+	if err := cli.AddCluster(&apisix.ClusterOptions{
+		Name:     opts.cfg.APISIX.DefaultClusterName,
+		BaseURL:  opts.cfg.APISIX.DefaultClusterBaseURL,
+		AdminKey: opts.cfg.APISIX.DefaultClusterAdminKey,
+	}); err != nil {
+		return fmt.Errorf("failed to add APISIX cluster: %s", err)
+	}
+	current, err := offline.Fetch(ctx, cli.Cluster(opts.cfg.APISIX.DefaultClusterName))
+	if err != nil {
+		return fmt.Errorf("failed to list resources from APISIX: %s", err)
+	}
+
+	added, changed, deleted := offline.Diff(current, desired)
+	if err := printChanges(w, &changes{
+		Added:   added,
+		Changed: changed,
+		Deleted: deleted,
+	}, opts.output); err != nil {
+		return err
+	}
+	// Objects which can't be translated are absent from the desired ones,
+	// so their resources are shown as deleted.
+	if translateErr != nil {
+		return fmt.Errorf("some objects failed to be translated, their resources might be shown as deleted: %s", translateErr)
+	}
+	return nil
+}
+
+func printChanges(w io.Writer, c *changes, output string) error {
+	if output == _outputText {
+		return printText(w, c)
+	}
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	if output == _outputYAML {
+		if data, err = yaml.JSONToYAML(data); err != nil {
+			return err
+		}
+	} else {
+		data = append(data, '\n')
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+func printText(w io.Writer, c *changes) error {
+	if c.Added.Empty() && c.Changed.Empty() && c.Deleted.Empty() {
+		_, err := fmt.Fprintln(w, "no differences")
+		return err
+	}
+
+	var lines []string
+	marks := []struct {
+		sign   string
+		result *offline.Result
+	}{
+		{"+", c.Added},
+		{"~", c.Changed},
+		{"-", c.Deleted},
+	}
+	for _, m := range marks {
+		for _, r := range m.result.Routes {
+			lines = append(lines, fmt.Sprintf("%s route %s %s", m.sign, r.ID, r.Name))
+		}
+	}
+	for _, m := range marks {
+		for _, u := range m.result.Upstreams {
+			lines = append(lines, fmt.Sprintf("%s upstream %s %s", m.sign, u.ID, u.Name))
+		}
+	}
+	for _, m := range marks {
+		for _, ssl := range m.result.SSLs {
+			lines = append(lines, fmt.Sprintf("%s ssl %s %s", m.sign, ssl.ID, strings.Join(ssl.Snis, ",")))
+		}
+	}
+	for _, m := range marks {
+		for _, sr := range m.result.StreamRoutes {
+			lines = append(lines, fmt.Sprintf("%s stream_route %s %s", m.sign, sr.ID, sr.Desc))
+		}
+	}
+	for _, m := range marks {
+		for _, consumer := range m.result.Consumers {
+			lines = append(lines, fmt.Sprintf("%s consumer %s", m.sign, consumer.Username))
+		}
+	}
+	_, err := fmt.Fprintln(w, strings.Join(lines, "\n"))
+	return err
+}
@@ -0,0 +1,195 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package diff
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/apache/apisix-ingress-controller/pkg/config"
+	"github.com/apache/apisix-ingress-controller/pkg/offline"
+	apisixv1 "github.com/apache/apisix-ingress-controller/pkg/types/apisix/v1"
+)
+
+const _manifests = "../../pkg/offline/testdata/manifests.yaml"
+
+type fakeItem struct {
+	Key   string          `json:"key"`
+	Value json.RawMessage `json:"value"`
+}
+
+// fakeAdminAPI serves the list requests of APISIX Admin API.
+type fakeAdminAPI struct {
+	resources map[string][]interface{}
+}
+
+func (f *fakeAdminAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	kind := strings.TrimPrefix(r.URL.Path, "/apisix/admin/")
+	items := []fakeItem{}
+	for i, obj := range f.resources[kind] {
+		data, _ := json.Marshal(obj)
+		items = append(items, fakeItem{
+			Key:   "/apisix/" + kind + "/" + strconv.Itoa(i),
+			Value: data,
+		})
+	}
+	resp := map[string]interface{}{
+		"count": strconv.Itoa(len(items)),
+		"node": map[string]interface{}{
+			"key":   "/apisix/" + kind,
+			"nodes": items,
+		},
+	}
+	data, _ := json.Marshal(resp)
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(data)
+}
+
+func TestDiff(t *testing.T) {
+	objs, err := offline.LoadObjects(_manifests)
+	assert.Nil(t, err)
+	kubeClient, apisixClient := offline.NewFakeClients(objs)
+	desired, err := offline.Translate(context.Background(), kubeClient, apisixClient, &offline.Options{
+		IngressClass: config.IngressClass,
+	})
+	assert.Nil(t, err)
+	assert.Len(t, desired.Routes, 2)
+
+	changedRoute := *desired.Routes[1]
+	changedRoute.Uris = []string{"/changed"}
+	staleRoute := apisixv1.NewDefaultRoute()
+	staleRoute.ID = "1234"
+	staleRoute.Name = "stale"
+	unmanagedRoute := &apisixv1.Route{
+		Metadata: apisixv1.Metadata{
+			ID:   "5678",
+			Name: "unmanaged",
+		},
+	}
+	api := &fakeAdminAPI{
+		resources: map[string][]interface{}{
+			"routes":    {desired.Routes[0], &changedRoute, staleRoute, unmanagedRoute},
+			"upstreams": {desired.Upstreams[0]},
+		},
+	}
+	srv := httptest.NewServer(api)
+	defer srv.Close()
+
+	opts := &options{
+		files:   []string{_manifests},
+		output:  _outputText,
+		timeout: 10 * time.Second,
+		cfg:     config.NewDefaultConfig(),
+	}
+	opts.cfg.Kubernetes.ApisixRouteVersion = config.ApisixRouteV2beta1
+	opts.cfg.APISIX.DefaultClusterBaseURL = srv.URL + "/apisix/admin"
+
+	var out bytes.Buffer
+	assert.Nil(t, run(context.Background(), &out, opts))
+	assert.Equal(t, "~ route "+changedRoute.ID+" "+changedRoute.Name+"\n- route 1234 stale\n", out.String())
+
+	// The ingress route only, as ApisixRoute v2alpha1 is used.
+	out.Reset()
+	opts.cfg.Kubernetes.ApisixRouteVersion = config.ApisixRouteV2alpha1
+	opts.output = _outputJSON
+	assert.Nil(t, run(context.Background(), &out, opts))
+	var c changes
+	assert.Nil(t, json.Unmarshal(out.Bytes(), &c))
+	assert.Len(t, c.Added.Routes, 0)
+	assert.Len(t, c.Changed.Routes, 0)
+	assert.Len(t, c.Deleted.Routes, 2)
+
+	api.resources["routes"] = []interface{}{desired.Routes[0], desired.Routes[1]}
+	out.Reset()
+	opts.cfg.Kubernetes.ApisixRouteVersion = config.ApisixRouteV2beta1
+	opts.output = _outputText
+	assert.Nil(t, run(context.Background(), &out, opts))
+	assert.Equal(t, "no differences\n", out.String())
+}
+
+func TestDiffWithoutCluster(t *testing.T) {
+	opts := &options{
+		files:   []string{_manifests},
+		output:  _outputText,
+		timeout: time.Second,
+		cfg:     config.NewDefaultConfig(),
+	}
+	err := run(context.Background(), &bytes.Buffer{}, opts)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "no APISIX cluster specified")
+}
+
+func TestDiffWithTranslationErrors(t *testing.T) {
+	data, err := ioutil.ReadFile(_manifests)
+	assert.Nil(t, err)
+	// The Service of the ApisixRoute doesn't exist.
+	data = append(data, []byte(`
+---
+apiVersion: apisix.apache.org/v2beta1
+kind: ApisixRoute
+metadata:
+  name: broken
+spec:
+  http:
+  - name: rule1
+    match:
+      paths:
+      - /broken
+    backends:
+    - serviceName: nonexistent
+      servicePort: 80
+`)...)
+	tmp, err := ioutil.TempFile("", "manifests-*.yaml")
+	assert.Nil(t, err)
+	defer os.Remove(tmp.Name())
+	_, err = tmp.Write(data)
+	assert.Nil(t, err)
+	assert.Nil(t, tmp.Close())
+
+	srv := httptest.NewServer(&fakeAdminAPI{})
+	defer srv.Close()
+	opts := &options{
+		files:   []string{tmp.Name()},
+		output:  _outputJSON,
+		timeout: 10 * time.Second,
+		cfg:     config.NewDefaultConfig(),
+	}
+	opts.cfg.Kubernetes.ApisixRouteVersion = config.ApisixRouteV2beta1
+	opts.cfg.APISIX.DefaultClusterBaseURL = srv.URL + "/apisix/admin"
+
+	// Resources of the other objects are still compared.
+	var out bytes.Buffer
+	err = run(context.Background(), &out, opts)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "ApisixRoute default/broken")
+	var c changes
+	assert.Nil(t, json.Unmarshal(out.Bytes(), &c))
+	assert.Len(t, c.Added.Routes, 2)
+}
@@ -15,7 +15,6 @@
 package ingress
 
 import (
-	"encoding/json"
 	"fmt"
 	"os"
 	"os/signal"
@@ -24,6 +23,9 @@ import (
 	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v2"
 
 	"github.com/apache/apisix-ingress-controller/pkg/config"
 	controller "github.com/apache/apisix-ingress-controller/pkg/ingress"
@@ -31,6 +33,10 @@ import (
 	"github.com/apache/apisix-ingress-controller/pkg/version"
 )
 
+// _configReloadInterval is the interval to check whether the configuration
+// file is changed.
+const _configReloadInterval = 10 * time.Second
+
 func dief(template string, args ...interface{}) {
 	if !strings.HasSuffix(template, "\n") {
 		template += "\n"
@@ -50,15 +56,26 @@ func waitForSignal(stopCh chan struct{}) {
 
 // NewIngressCommand creates the ingress sub command for apisix-ingress-controller.
 func NewIngressCommand() *cobra.Command {
-	var configPath string
+	return newIngressCommand(os.LookupEnv)
+}
+
+// newIngressCommand creates the ingress sub command, environment variables
+// are looked up by lookupEnv.
+func newIngressCommand(lookupEnv func(string) (string, bool)) *cobra.Command {
+	var (
+		configPath  string
+		printConfig bool
+	)
 	cfg := config.NewDefaultConfig()
 
 	cmd := &cobra.Command{
 		Use: "ingress [flags]",
 		Long: `launch the ingress controller
 
-You can run apisix-ingress-controller from configuration file or command line options,
-if you run it from configuration file, other command line options will be ignored.
+You can run apisix-ingress-controller from configuration file, environment variables or
+command line options. The configuration is merged in layers: the default values, then the
+configuration file, then the environment variables, and then the command line options which
+are set explicitly, later layers take precedence.
 
 Run from configuration file:
 
@@ -66,6 +83,20 @@ Run from configuration file:
 
 Both json and yaml are supported as the configuration file format.
 
+The configuration file is reloaded once it's changed, log level, app namespaces and options
+of the default APISIX cluster are applied at runtime, changes of other items require a restart.
+
+Each configuration item can be set by an environment variable, which is named after its path
+in the configuration file, e.g. kubernetes.app_namespaces is set by
+APISIX_INGRESS_KUBERNETES_APP_NAMESPACES (items of lists are separated by commas):
+
+    APISIX_INGRESS_APISIX_DEFAULT_CLUSTER_BASE_URL=http://apisix-service:9180/apisix/admin \
+      apisix-ingress-controller ingress --kubeconfig /path/to/kubeconfig
+
+Show the merged configuration (admin keys are redacted) without launching the controller:
+
+    apisix-ingress-controller ingress --config-path /path/to/config.yaml --print-config
+
 Run from command line options:
 
     apisix-ingress-controller ingress --apisix-base-url http://apisix-service:9180/apisix/admin --kubeconfig /path/to/kubeconfig
@@ -90,16 +121,34 @@ or if you run it inside cluster, leave it alone and in-cluster configuration wil
 Before you run apisix-ingress-controller, be sure all related resources, like CRDs (ApisixRoute, ApisixUpstream and etc),
 the apisix cluster and others are created`,
 		Run: func(cmd *cobra.Command, args []string) {
-			if configPath != "" {
-				c, err := config.NewConfigFromFile(configPath)
-				if err != nil {
-					dief("failed to initialize configuration: %s", err)
+			// Options bound to cfg override other layers only if they're set.
+			overrides := cfg
+			var keys []string
+			cmd.Flags().Visit(func(f *pflag.Flag) {
+				if key, ok := _flagKeys[f.Name]; ok {
+					keys = append(keys, key)
 				}
-				cfg = c
+			})
+			load := func() (*config.Config, error) {
+				return config.Load(configPath, lookupEnv, overrides, keys)
+			}
+			c, err := load()
+			if err != nil {
+				dief("failed to initialize configuration: %s", err)
 			}
-			if err := cfg.Validate(); err != nil {
+			if err := c.Validate(); err != nil {
 				dief("bad configuration: %s", err)
 			}
+			cfg = c
+
+			if printConfig {
+				data, err := yaml.Marshal(cfg.Redacted())
+				if err != nil {
+					dief("failed to show configuration: %s", err)
+				}
+				fmt.Fprint(cmd.OutOrStdout(), string(data))
+				return
+			}
 
 			logger, err := log.NewLogger(
 				log.WithLogLevel(cfg.LogLevel),
@@ -113,11 +162,9 @@ the apisix cluster and others are created`,
 
 			log.Info("version:\n", version.Long())
 
-			data, err := json.MarshalIndent(cfg, "", "\t")
-			if err != nil {
-				dief("failed to show configuration: %s", string(data))
-			}
-			log.Info("use configuration\n", string(data))
+			log.Infow("use configuration",
+				zap.Object("configuration", cfg),
+			)
 
 			stop := make(chan struct{})
 			ingress, err := controller.NewController(cfg)
@@ -129,6 +176,9 @@ the apisix cluster and others are created`,
 					dief("failed to run ingress controller: %s", err)
 				}
 			}()
+			if configPath != "" {
+				go config.WatchFile(configPath, load, cfg, _configReloadInterval, stop, ingress.Reload)
+			}
 
 			waitForSignal(stop)
 			log.Info("apisix ingress controller exited")
@@ -136,23 +186,80 @@ the apisix cluster and others are created`,
 	}
 
 	cmd.PersistentFlags().StringVar(&configPath, "config-path", "", "configuration file path for apisix-ingress-controller")
-	cmd.PersistentFlags().StringVar(&cfg.LogLevel, "log-level", "info", "error log level")
+	cmd.PersistentFlags().BoolVar(&printConfig, "print-config", false, "print the merged configuration (admin keys are redacted) and exit")
+	cmd.PersistentFlags().StringVar(&cfg.LogLevel, "log-level", cfg.LogLevel, "error log level")
 	cmd.PersistentFlags().StringVar(&cfg.LogOutput, "log-output", "stderr", "error log output file")
 	cmd.PersistentFlags().StringVar(&cfg.HTTPListen, "http-listen", ":8080", "the HTTP Server listen address")
 	cmd.PersistentFlags().BoolVar(&cfg.EnableProfiling, "enable-profiling", true, "enable profiling via web interface host:port/debug/pprof")
 	cmd.PersistentFlags().StringVar(&cfg.Kubernetes.Kubeconfig, "kubeconfig", "", "Kubernetes configuration file (by default in-cluster configuration will be used)")
-	cmd.PersistentFlags().DurationVar(&cfg.Kubernetes.ResyncInterval.Duration, "resync-interval", time.Minute, "the controller resync (with Kubernetes) interval, the minimum resync interval is 30s")
+	cmd.PersistentFlags().DurationVar(&cfg.Kubernetes.ResyncInterval.Duration, "resync-interval", cfg.Kubernetes.ResyncInterval.Duration, "the controller resync (with Kubernetes) interval, the minimum resync interval is 30s")
 	cmd.PersistentFlags().StringSliceVar(&cfg.Kubernetes.AppNamespaces, "app-namespace", []string{config.NamespaceAll}, "namespaces that controller will watch for resources")
+	cmd.PersistentFlags().StringVar(&cfg.Kubernetes.NamespaceSelector, "namespace-selector", "", "the label selector of namespaces that controller will watch for resources, namespaces are included or excluded once their labels change")
 	cmd.PersistentFlags().StringVar(&cfg.Kubernetes.IngressClass, "ingress-class", config.IngressClass, "the class of an Ingress object is set using the field IngressClassName in Kubernetes clusters version v1.18.0 or higher or the annotation \"kubernetes.io/ingress.class\" (deprecated)")
 	cmd.PersistentFlags().StringVar(&cfg.Kubernetes.ElectionID, "election-id", config.IngressAPISIXLeader, "election id used for campaign the controller leader")
+	cmd.PersistentFlags().StringVar(&cfg.Kubernetes.ControllerName, "controller-name", config.ControllerName, "the controller name, Ingresses whose IngressClass has this spec.controller are handled")
 	cmd.PersistentFlags().StringVar(&cfg.Kubernetes.IngressVersion, "ingress-version", config.IngressNetworkingV1, "the supported ingress api group version, can be \"networking/v1beta1\", \"networking/v1\" (for Kubernetes version v1.19.0 or higher) and \"extensions/v1beta1\"")
 	cmd.PersistentFlags().StringVar(&cfg.Kubernetes.ApisixRouteVersion, "apisix-route-version", config.ApisixRouteV2alpha1, "the supported apisixroute api group version, can be \"apisix.apache.org/v1\" or \"apisix.apache.org/v2alpha1\"")
 	cmd.PersistentFlags().BoolVar(&cfg.Kubernetes.WatchEndpointSlices, "watch-endpointslices", false, "whether to watch endpointslices rather than endpoints")
+	cmd.PersistentFlags().BoolVar(&cfg.Kubernetes.WatchApisixWithoutClass, "watch-apisix-without-class", true, "whether to handle Apisix* resources without the annotation \"kubernetes.io/ingress.class\"")
+	cmd.PersistentFlags().StringVar(&cfg.Kubernetes.PublishService, "publish-service", "", "the service (in the namespace/name format) of the APISIX gateway, its load balancer addresses or external IPs are written to the status of Ingresses")
+	cmd.PersistentFlags().StringSliceVar(&cfg.Kubernetes.PublishStatusAddress, "publish-status-address", nil, "the static addresses written to the status of Ingresses, it takes precedence over --publish-service")
 	cmd.PersistentFlags().StringVar(&cfg.APISIX.BaseURL, "apisix-base-url", "", "the base URL for APISIX admin api / manager api (deprecated, using --default-apisix-cluster-base-url instead)")
 	cmd.PersistentFlags().StringVar(&cfg.APISIX.AdminKey, "apisix-admin-key", "", "admin key used for the authorization of APISIX admin api / manager api (deprecated, using --default-apisix-cluster-admin-key instead)")
 	cmd.PersistentFlags().StringVar(&cfg.APISIX.DefaultClusterBaseURL, "default-apisix-cluster-base-url", "", "the base URL of admin api / manager api for the default APISIX cluster")
 	cmd.PersistentFlags().StringVar(&cfg.APISIX.DefaultClusterAdminKey, "default-apisix-cluster-admin-key", "", "admin key used for the authorization of admin api / manager api for the default APISIX cluster")
+	cmd.PersistentFlags().StringVar(&cfg.APISIX.DefaultClusterAdminKeySecretRef.Namespace, "default-apisix-cluster-admin-key-secret-namespace", "", "namespace of the secret which stores the admin key for the default APISIX cluster")
+	cmd.PersistentFlags().StringVar(&cfg.APISIX.DefaultClusterAdminKeySecretRef.Name, "default-apisix-cluster-admin-key-secret-name", "", "name of the secret which stores the admin key for the default APISIX cluster")
+	cmd.PersistentFlags().StringVar(&cfg.APISIX.DefaultClusterAdminKeySecretRef.Key, "default-apisix-cluster-admin-key-secret-key", "", "key in the secret which stores the admin key for the default APISIX cluster")
 	cmd.PersistentFlags().StringVar(&cfg.APISIX.DefaultClusterName, "default-apisix-cluster-name", "default", "name of the default apisix cluster")
+	cmd.PersistentFlags().StringVar(&cfg.APISIX.DefaultClusterCACert, "default-apisix-cluster-ca-cert", "", "path of the CA bundle to verify the admin api server certificate of the default APISIX cluster")
+	cmd.PersistentFlags().StringVar(&cfg.APISIX.DefaultClusterClientCert, "default-apisix-cluster-client-cert", "", "path of the client certificate for the admin api of the default APISIX cluster")
+	cmd.PersistentFlags().StringVar(&cfg.APISIX.DefaultClusterClientKey, "default-apisix-cluster-client-key", "", "path of the client key for the admin api of the default APISIX cluster")
+	cmd.PersistentFlags().StringVar(&cfg.APISIX.DefaultClusterServerName, "default-apisix-cluster-server-name", "", "server name to verify the admin api server certificate of the default APISIX cluster")
+	cmd.PersistentFlags().BoolVar(&cfg.APISIX.DefaultClusterInsecureSkipVerify, "default-apisix-cluster-insecure-skip-verify", false, "skip verifying the admin api server certificate of the default APISIX cluster")
+	cmd.PersistentFlags().DurationVar(&cfg.APISIX.ReconcileInterval.Duration, "apisix-reconcile-interval", 0, "the interval to reconcile the default APISIX cluster and repair the drifts, zero means disabled, the minimum interval is 30s")
+	cmd.PersistentFlags().BoolVar(&cfg.APISIX.GCDryRun, "apisix-gc-dry-run", false, "only report the orphaned resources in APISIX rather than deleting them in the garbage collection")
+	cmd.PersistentFlags().IntVar(&cfg.APISIX.GCMaxDeletions, "apisix-gc-max-deletions", 100, "the maximum number of orphaned resources that a garbage collection pass may delete")
 
 	return cmd
 }
+
+// _flagKeys maps command line options to keys of the config items they set,
+// options are named after the keys by flagName.
+var _flagKeys = func() map[string]string {
+	keys := make(map[string]string)
+	for _, key := range config.Keys() {
+		keys[flagName(key)] = key
+	}
+	return keys
+}()
+
+// _flagNames are the command line options which are not named by the rules
+// in flagName.
+var _flagNames = map[string]string{
+	"kubernetes.app_namespaces":        "app-namespace",
+	"kubernetes.watch_endpoint_slices": "watch-endpointslices",
+}
+
+// flagName returns the command line option of the config item. Options are
+// the keys without the section and with dashes, options of the apisix section
+// are prefixed by "apisix-" (or "default-apisix-cluster-" for the default
+// cluster), and fields of secret references follow the reference name, e.g.
+// "default-apisix-cluster-admin-key-secret-name". TestFlagKeys ensures every
+// option exists.
+func flagName(key string) string {
+	if name, ok := _flagNames[key]; ok {
+		return name
+	}
+	name := key
+	switch {
+	case strings.HasPrefix(key, "kubernetes."):
+		name = strings.TrimPrefix(key, "kubernetes.")
+	case strings.HasPrefix(key, "apisix.default_cluster_"):
+		name = "default_apisix_cluster_" + strings.TrimPrefix(key, "apisix.default_cluster_")
+	case strings.HasPrefix(key, "apisix."):
+		name = "apisix_" + strings.TrimPrefix(key, "apisix.")
+	}
+	name = strings.Replace(name, "_ref.", "_", -1)
+	return strings.Replace(name, "_", "-", -1)
+}
@@ -19,14 +19,15 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"math/rand"
 	"os"
-	"strings"
 	"syscall"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v2"
 
 	"github.com/apache/apisix-ingress-controller/pkg/config"
 	"github.com/apache/apisix-ingress-controller/pkg/log"
@@ -38,9 +39,10 @@ type fakeWriteSyncer struct {
 }
 
 type fields struct {
-	Level   string
-	Time    string
-	Message string
+	Level         string
+	Time          string
+	Message       string
+	Configuration config.Config
 }
 
 func init() {
@@ -137,18 +139,19 @@ func TestNewIngressCommandEffectiveLog(t *testing.T) {
 	assert.Contains(t, f.Message, "use configuration")
 	assert.Equal(t, f.Level, "info")
 
-	var cfg config.Config
-	data := strings.TrimPrefix(f.Message, "use configuration\n")
-	err = json.Unmarshal([]byte(data), &cfg)
-	assert.Nil(t, err)
-
+	cfg := f.Configuration
 	assert.Equal(t, cfg.LogOutput, "./test.log")
 	assert.Equal(t, cfg.LogLevel, "debug")
 	assert.Equal(t, cfg.HTTPListen, listen)
 	assert.Equal(t, cfg.EnableProfiling, true)
 	assert.Equal(t, cfg.Kubernetes.Kubeconfig, "/foo/bar/baz")
 	assert.Equal(t, cfg.Kubernetes.ResyncInterval, types.TimeDuration{Duration: 24 * time.Hour})
-	assert.Equal(t, cfg.APISIX.AdminKey, "0x123")
+	// The admin key is redacted.
+	assert.Equal(t, cfg.APISIX.AdminKey, "******")
+	assert.Equal(t, cfg.APISIX.DefaultClusterAdminKey, "******")
+	data, err := ioutil.ReadFile("./test.log")
+	assert.Nil(t, err)
+	assert.NotContains(t, string(data), "0x123")
 	assert.Equal(t, cfg.APISIX.BaseURL, "http://apisixgw.default.cluster.local/apisix")
 }
 
@@ -162,3 +165,55 @@ func parseLog(t *testing.T, r *bufio.Reader) *fields {
 	assert.Nil(t, err)
 	return &f
 }
+
+func TestFlagKeys(t *testing.T) {
+	cmd := NewIngressCommand()
+	var keys []string
+	for name, key := range _flagKeys {
+		assert.NotNil(t, cmd.PersistentFlags().Lookup(name), "option --%s", name)
+		keys = append(keys, key)
+	}
+	// Every config item can be set by a command line option.
+	assert.ElementsMatch(t, config.Keys(), keys)
+}
+
+func TestPrintConfig(t *testing.T) {
+	env := map[string]string{
+		"APISIX_INGRESS_APISIX_DEFAULT_CLUSTER_ADMIN_KEY": "0x123",
+		"APISIX_INGRESS_LOG_LEVEL":                        "error",
+	}
+	cmd := newIngressCommand(func(name string) (string, bool) {
+		value, ok := env[name]
+		return value, ok
+	})
+	out := new(bytes.Buffer)
+	cmd.SetOut(out)
+	cmd.SetArgs([]string{
+		"--print-config",
+		"--log-level", "debug",
+		"--default-apisix-cluster-base-url", "http://apisixgw.default.cluster.local/apisix",
+	})
+	assert.Nil(t, cmd.Execute())
+
+	var cfg config.Config
+	assert.Nil(t, yaml.Unmarshal(out.Bytes(), &cfg))
+	assert.Equal(t, "debug", cfg.LogLevel)
+	assert.Equal(t, "******", cfg.APISIX.DefaultClusterAdminKey)
+	assert.Equal(t, "http://apisixgw.default.cluster.local/apisix", cfg.APISIX.DefaultClusterBaseURL)
+	assert.Equal(t, types.TimeDuration{Duration: 6 * time.Hour}, cfg.Kubernetes.ResyncInterval)
+}
+
+func TestDefaultLogLevel(t *testing.T) {
+	cmd := newIngressCommand(func(string) (string, bool) { return "", false })
+	out := new(bytes.Buffer)
+	cmd.SetOut(out)
+	cmd.SetArgs([]string{
+		"--print-config",
+		"--default-apisix-cluster-base-url", "http://apisixgw.default.cluster.local/apisix",
+	})
+	assert.Nil(t, cmd.Execute())
+
+	var cfg config.Config
+	assert.Nil(t, yaml.Unmarshal(out.Bytes(), &cfg))
+	assert.Equal(t, "info", cfg.LogLevel)
+}
@@ -0,0 +1,114 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package translate
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/yaml"
+
+	"github.com/apache/apisix-ingress-controller/pkg/config"
+	"github.com/apache/apisix-ingress-controller/pkg/offline"
+)
+
+const (
+	_outputJSON = "json"
+	_outputYAML = "yaml"
+)
+
+func dief(template string, args ...interface{}) {
+	if !strings.HasSuffix(template, "\n") {
+		template += "\n"
+	}
+	fmt.Fprintf(os.Stderr, template, args...)
+	os.Exit(1)
+}
+
+// NewTranslateCommand creates the translate sub command for apisix-ingress-controller.
+func NewTranslateCommand() *cobra.Command {
+	var (
+		files  []string
+		output string
+		opts   offline.Options
+	)
+	cmd := &cobra.Command{
+		Use: "translate [flags]",
+		Long: `translate Kubernetes manifests to APISIX resources
+
+Ingress, ApisixRoute, ApisixUpstream, ApisixTls, ApisixConsumer, ApisixClusterConfig,
+Service, Endpoints and Secret objects are read from the given files (or directories),
+and the generated APISIX Routes, Upstreams, SSLs, Stream Routes, Consumers and Global Rules
+are printed, without connecting to Kubernetes or APISIX.
+
+    apisix-ingress-controller translate -f /path/to/manifests -o yaml
+
+Upstream nodes are filled from the Endpoints objects, so put them with the Services
+if you care about the upstream nodes.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			if len(files) == 0 {
+				dief("no manifest specified, use -f to specify them")
+			}
+			if output != _outputJSON && output != _outputYAML {
+				dief("unsupported output format: %s", output)
+			}
+			objs, err := offline.LoadObjects(files...)
+			if err != nil {
+				dief("failed to load manifests: %s", err)
+			}
+			kubeClient, apisixClient := offline.NewFakeClients(objs)
+			result, translateErr := offline.Translate(context.Background(), kubeClient, apisixClient, &opts)
+			if result == nil {
+				dief("failed to translate manifests: %s", translateErr)
+			}
+			if err := printResult(cmd.OutOrStdout(), result, output); err != nil {
+				dief("failed to print translation result: %s", err)
+			}
+			if translateErr != nil {
+				dief("some objects failed to be translated: %s", translateErr)
+			}
+		},
+	}
+
+	cmd.PersistentFlags().StringSliceVarP(&files, "filename", "f", nil, "manifest files or directories which contain the manifests")
+	cmd.PersistentFlags().StringVarP(&output, "output", "o", _outputJSON, "output format, json or yaml")
+	cmd.PersistentFlags().StringVar(&opts.IngressClass, "ingress-class", config.IngressClass, "the class of Ingresses to be translated, empty means all Ingresses")
+	cmd.PersistentFlags().StringVar(&opts.IngressVersion, "ingress-version", "", "the version of Ingresses to be translated, empty means all versions")
+	cmd.PersistentFlags().StringVar(&opts.ApisixRouteVersion, "apisix-route-version", "", "the version of ApisixRoutes to be translated, empty means all versions")
+
+	return cmd
+}
+
+func printResult(w io.Writer, result *offline.Result, output string) error {
+	data, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return err
+	}
+	if output == _outputYAML {
+		// Marshal with JSON first so plugin configs respect their json tags.
+		if data, err = yaml.JSONToYAML(data); err != nil {
+			return err
+		}
+	} else {
+		data = append(data, '\n')
+	}
+	_, err = w.Write(data)
+	return err
+}
@@ -0,0 +1,45 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package translate
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/apache/apisix-ingress-controller/pkg/offline"
+)
+
+func TestTranslateCommand(t *testing.T) {
+	var out bytes.Buffer
+	cmd := NewTranslateCommand()
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"-f", "../../pkg/offline/testdata/manifests.yaml"})
+	assert.Nil(t, cmd.Execute())
+
+	var result offline.Result
+	assert.Nil(t, json.Unmarshal(out.Bytes(), &result))
+	assert.Len(t, result.Routes, 2)
+	assert.Len(t, result.Upstreams, 1)
+
+	out.Reset()
+	cmd = NewTranslateCommand()
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"-f", "../../pkg/offline/testdata/manifests.yaml", "-o", "yaml"})
+	assert.Nil(t, cmd.Execute())
+	assert.Contains(t, out.String(), "upstream_id: 5ce57b8e")
+}
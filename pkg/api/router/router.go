@@ -42,6 +42,22 @@ func metrics(c *gin.Context) {
 	promhttp.Handler().ServeHTTP(c.Writer, c.Request)
 }
 
+type configResponse struct {
+	Hash string `json:"hash"`
+}
+
+// MountConfig mounts the router which shows the hash of the config in use,
+// so that whether a changed config is reloaded can be checked.
+func MountConfig(r *gin.Engine, hash func() string) {
+	r.GET("/config/hash", configHash(hash))
+}
+
+func configHash(hash func() string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.AbortWithStatusJSON(http.StatusOK, configResponse{Hash: hash()})
+	}
+}
+
 // Mount mounts all api routers.
 func Mount(r *gin.Engine) {
 	mountHealthz(r)
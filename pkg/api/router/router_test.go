@@ -50,3 +50,18 @@ func TestMetrics(t *testing.T) {
 
 	assert.Equal(t, w.Code, http.StatusOK)
 }
+
+func TestConfigHash(t *testing.T) {
+	w := httptest.NewRecorder()
+	c, r := gin.CreateTestContext(w)
+	MountConfig(r, func() string { return "abc" })
+	configHash(func() string { return "abc" })(c)
+
+	assert.Equal(t, w.Code, http.StatusOK)
+
+	var resp configResponse
+	dec := json.NewDecoder(w.Body)
+	assert.Nil(t, dec.Decode(&resp))
+
+	assert.Equal(t, resp, configResponse{Hash: "abc"})
+}
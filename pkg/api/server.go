@@ -18,6 +18,7 @@ import (
 	"net"
 	"net/http"
 	"net/http/pprof"
+	"sync/atomic"
 
 	"github.com/gin-gonic/gin"
 
@@ -32,6 +33,8 @@ type Server struct {
 	router       *gin.Engine
 	httpListener net.Listener
 	pprofMu      *http.ServeMux
+	// configHash is the hash of the config in use.
+	configHash atomic.Value
 }
 
 // NewServer initializes the API Server.
@@ -49,6 +52,8 @@ func NewServer(cfg *config.Config) (*Server, error) {
 		router:       router,
 		httpListener: httpListener,
 	}
+	srv.SetConfigHash(cfg.Hash())
+	apirouter.MountConfig(router, srv.ConfigHash)
 
 	if cfg.EnableProfiling {
 		srv.pprofMu = new(http.ServeMux)
@@ -63,6 +68,17 @@ func NewServer(cfg *config.Config) (*Server, error) {
 	return srv, nil
 }
 
+// SetConfigHash sets the hash of the config in use, it's changed once the
+// config is reloaded.
+func (srv *Server) SetConfigHash(hash string) {
+	srv.configHash.Store(hash)
+}
+
+// ConfigHash returns the hash of the config in use.
+func (srv *Server) ConfigHash() string {
+	return srv.configHash.Load().(string)
+}
+
 // Run launches the API Server.
 func (srv *Server) Run(stopCh <-chan struct{}) error {
 	go func() {
@@ -27,10 +27,24 @@ type APISIX interface {
 	Cluster(string) Cluster
 	// AddCluster adds a new cluster.
 	AddCluster(*ClusterOptions) error
-	// UpdateCluster updates an existing cluster.
+	// UpdateCluster updates an existing cluster, it's updated in place if
+	// only the admin key is changed.
 	UpdateCluster(*ClusterOptions) error
 	// ListClusters lists all APISIX clusters.
 	ListClusters() []Cluster
+	// DeleteCluster deletes the cluster, its cache is dropped but
+	// resources in APISIX are kept.
+	DeleteCluster(string) error
+}
+
+// Snapshot contains all resources in an APISIX cluster.
+type Snapshot struct {
+	Routes       []*v1.Route
+	Upstreams    []*v1.Upstream
+	SSLs         []*v1.Ssl
+	StreamRoutes []*v1.StreamRoute
+	GlobalRules  []*v1.GlobalRule
+	Consumers    []*v1.Consumer
 }
 
 // Cluster defines specific operations that can be applied in an APISIX
@@ -54,6 +68,13 @@ type Cluster interface {
 	Consumer() Consumer
 	// HealthCheck checks apisix cluster health in realtime.
 	HealthCheck(context.Context) error
+	// Resync lists all resources from APISIX and refreshes the cache
+	// with them, the listed resources are returned.
+	Resync(context.Context) (*Snapshot, error)
+	// ListBySource lists resources in the cache which are translated from
+	// the source object (see v1.SourceKey), upstreams referenced by its
+	// routes and stream routes are also included.
+	ListBySource(context.Context, string) (*Snapshot, error)
 }
 
 // Route is the specific client interface to take over the create, update,
@@ -172,15 +193,39 @@ func (c *apisix) AddCluster(co *ClusterOptions) error {
 func (c *apisix) UpdateCluster(co *ClusterOptions) error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
-	if _, ok := c.clusters[co.Name]; !ok {
+	old, ok := c.clusters[co.Name]
+	if !ok {
 		return ErrClusterNotExist
 	}
+	// Only the admin key is changed (e.g. rotated), the cluster is
+	// kept so that its cache needn't be synced again.
+	if old, ok := old.(*cluster); ok && old.updateAdminKey(co) {
+		return nil
+	}
 
-	cluster, err := newCluster(co)
+	cli, err := newCluster(co)
 	if err != nil {
 		return err
 	}
 
-	c.clusters[co.Name] = cluster
+	c.clusters[co.Name] = cli
+	if old, ok := old.(*cluster); ok {
+		old.close()
+	}
+	return nil
+}
+
+// DeleteCluster implements APISIX.DeleteCluster method.
+func (c *apisix) DeleteCluster(name string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	old, ok := c.clusters[name]
+	if !ok {
+		return ErrClusterNotExist
+	}
+	delete(c.clusters, name)
+	if old, ok := old.(*cluster); ok {
+		old.close()
+	}
 	return nil
 }
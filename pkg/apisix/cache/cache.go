@@ -62,6 +62,16 @@ type Cache interface {
 	// ListConsumers lists all consumer objects in cache.
 	ListConsumers() ([]*v1.Consumer, error)
 
+	// ListRoutesBySource lists routes translated from the source object, see
+	// v1.SourceKey.
+	ListRoutesBySource(string) ([]*v1.Route, error)
+	// ListSSLBySource lists ssl objects translated from the source object.
+	ListSSLBySource(string) ([]*v1.Ssl, error)
+	// ListStreamRoutesBySource lists stream_route translated from the source object.
+	ListStreamRoutesBySource(string) ([]*v1.StreamRoute, error)
+	// ListConsumersBySource lists consumer objects translated from the source object.
+	ListConsumersBySource(string) ([]*v1.Consumer, error)
+
 	// DeleteRoute deletes the specified route in cache.
 	DeleteRoute(*v1.Route) error
 	// DeleteSSL deletes the specified ssl in cache.
@@ -74,4 +84,7 @@ type Cache interface {
 	DeleteGlobalRule(*v1.GlobalRule) error
 	// DeleteConsumer deletes the specified consumer in cache.
 	DeleteConsumer(*v1.Consumer) error
+
+	// Drain deletes all objects in cache.
+	Drain() error
 }
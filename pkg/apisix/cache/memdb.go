@@ -230,6 +230,68 @@ func (c *dbCache) list(table string) ([]interface{}, error) {
 	return objs, nil
 }
 
+func (c *dbCache) ListRoutesBySource(source string) ([]*v1.Route, error) {
+	raws, err := c.listBySource("route", source)
+	if err != nil {
+		return nil, err
+	}
+	routes := make([]*v1.Route, 0, len(raws))
+	for _, raw := range raws {
+		routes = append(routes, raw.(*v1.Route).DeepCopy())
+	}
+	return routes, nil
+}
+
+func (c *dbCache) ListSSLBySource(source string) ([]*v1.Ssl, error) {
+	raws, err := c.listBySource("ssl", source)
+	if err != nil {
+		return nil, err
+	}
+	ssl := make([]*v1.Ssl, 0, len(raws))
+	for _, raw := range raws {
+		ssl = append(ssl, raw.(*v1.Ssl).DeepCopy())
+	}
+	return ssl, nil
+}
+
+func (c *dbCache) ListStreamRoutesBySource(source string) ([]*v1.StreamRoute, error) {
+	raws, err := c.listBySource("stream_route", source)
+	if err != nil {
+		return nil, err
+	}
+	streamRoutes := make([]*v1.StreamRoute, 0, len(raws))
+	for _, raw := range raws {
+		streamRoutes = append(streamRoutes, raw.(*v1.StreamRoute).DeepCopy())
+	}
+	return streamRoutes, nil
+}
+
+func (c *dbCache) ListConsumersBySource(source string) ([]*v1.Consumer, error) {
+	raws, err := c.listBySource("consumer", source)
+	if err != nil {
+		return nil, err
+	}
+	consumers := make([]*v1.Consumer, 0, len(raws))
+	for _, raw := range raws {
+		consumers = append(consumers, raw.(*v1.Consumer).DeepCopy())
+	}
+	return consumers, nil
+}
+
+func (c *dbCache) listBySource(table, source string) ([]interface{}, error) {
+	txn := c.db.Txn(false)
+	defer txn.Abort()
+	iter, err := txn.Get(table, "source", source)
+	if err != nil {
+		return nil, err
+	}
+	var objs []interface{}
+	for obj := iter.Next(); obj != nil; obj = iter.Next() {
+		objs = append(objs, obj)
+	}
+	return objs, nil
+}
+
 func (c *dbCache) DeleteRoute(r *v1.Route) error {
 	return c.delete("route", r)
 }
@@ -270,11 +332,23 @@ func (c *dbCache) delete(table string, obj interface{}) error {
 	return nil
 }
 
+func (c *dbCache) Drain() error {
+	txn := c.db.Txn(true)
+	defer txn.Abort()
+	for table := range _schema.Tables {
+		if _, err := txn.DeleteAll(table, "id_prefix", ""); err != nil {
+			return err
+		}
+	}
+	txn.Commit()
+	return nil
+}
+
 func (c *dbCache) checkUpstreamReference(u *v1.Upstream) error {
-	// Upstream is referenced by Route.
+	// Upstream is referenced by Route, either directly or through plugins.
 	txn := c.db.Txn(false)
 	defer txn.Abort()
-	obj, err := txn.First("route", "upstream_id", u.ID)
+	obj, err := txn.First("route", "referenced_upstream_id", u.ID)
 	if err != nil && err != memdb.ErrNotFound {
 		return err
 	}
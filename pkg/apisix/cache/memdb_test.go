@@ -215,6 +215,27 @@ func TestMemDBCacheReference(t *testing.T) {
 	assert.Nil(t, db.DeleteUpstream(u))
 	assert.Nil(t, db.DeleteStreamRoute(sr))
 	assert.Nil(t, db.DeleteUpstream(u2))
+
+	// Upstreams referenced by the traffic-split plugin.
+	r.Plugins = v1.Plugins{
+		"traffic-split": &v1.TrafficSplitConfig{
+			Rules: []v1.TrafficSplitConfigRule{
+				{
+					WeightedUpstreams: []v1.TrafficSplitConfigRuleWeightedUpstream{
+						{UpstreamID: "2", Weight: 10},
+						{Weight: 90},
+					},
+				},
+			},
+		},
+	}
+	assert.Nil(t, db.InsertRoute(r))
+	assert.Nil(t, db.InsertUpstream(u))
+	assert.Nil(t, db.InsertUpstream(u2))
+	assert.Equal(t, ErrStillInUse, db.DeleteUpstream(u2))
+	assert.Nil(t, db.DeleteRoute(r))
+	assert.Nil(t, db.DeleteUpstream(u2))
+	assert.Nil(t, db.DeleteUpstream(u))
 }
 
 func TestMemDBCacheStreamRoute(t *testing.T) {
@@ -345,3 +366,59 @@ func TestMemDBCacheConsumer(t *testing.T) {
 	}
 	assert.Error(t, ErrNotFound, c.DeleteConsumer(c4))
 }
+
+func TestMemDBCacheDrain(t *testing.T) {
+	c, err := NewMemDBCache()
+	assert.Nil(t, err, "NewMemDBCache")
+
+	assert.Nil(t, c.InsertUpstream(&v1.Upstream{Metadata: v1.Metadata{ID: "1"}}))
+	assert.Nil(t, c.InsertRoute(&v1.Route{Metadata: v1.Metadata{ID: "1"}, UpstreamId: "1"}))
+	assert.Nil(t, c.InsertConsumer(&v1.Consumer{Username: "jack"}))
+
+	assert.Nil(t, c.Drain())
+
+	routes, err := c.ListRoutes()
+	assert.Nil(t, err)
+	assert.Len(t, routes, 0)
+	upstreams, err := c.ListUpstreams()
+	assert.Nil(t, err)
+	assert.Len(t, upstreams, 0)
+	consumers, err := c.ListConsumers()
+	assert.Nil(t, err)
+	assert.Len(t, consumers, 0)
+}
+
+func TestMemDBCacheListBySource(t *testing.T) {
+	c, err := NewMemDBCache()
+	assert.Nil(t, err, "NewMemDBCache")
+
+	foo := map[string]string{
+		v1.LabelSourceKind:      "ApisixRoute",
+		v1.LabelSourceNamespace: "default",
+		v1.LabelSourceName:      "foo",
+	}
+	foobar := map[string]string{
+		v1.LabelSourceKind:      "ApisixRoute",
+		v1.LabelSourceNamespace: "default",
+		v1.LabelSourceName:      "foo-bar",
+	}
+	assert.Nil(t, c.InsertRoute(&v1.Route{Metadata: v1.Metadata{ID: "1", Labels: foo}}))
+	assert.Nil(t, c.InsertRoute(&v1.Route{Metadata: v1.Metadata{ID: "2", Labels: foobar}}))
+	assert.Nil(t, c.InsertRoute(&v1.Route{Metadata: v1.Metadata{ID: "3"}}))
+	assert.Nil(t, c.InsertStreamRoute(&v1.StreamRoute{ID: "1", Labels: foo}))
+	assert.Nil(t, c.InsertConsumer(&v1.Consumer{Username: "jack", Labels: foobar}))
+
+	routes, err := c.ListRoutesBySource("ApisixRoute/default/foo")
+	assert.Nil(t, err)
+	assert.Len(t, routes, 1)
+	assert.Equal(t, "1", routes[0].ID)
+	streamRoutes, err := c.ListStreamRoutesBySource("ApisixRoute/default/foo")
+	assert.Nil(t, err)
+	assert.Len(t, streamRoutes, 1)
+	consumers, err := c.ListConsumersBySource("ApisixRoute/default/foo")
+	assert.Nil(t, err)
+	assert.Len(t, consumers, 0)
+	ssls, err := c.ListSSLBySource("ApisixRoute/default/foo")
+	assert.Nil(t, err)
+	assert.Len(t, ssls, 0)
+}
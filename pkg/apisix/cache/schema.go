@@ -16,7 +16,11 @@
 package cache
 
 import (
+	"fmt"
+
 	"github.com/hashicorp/go-memdb"
+
+	v1 "github.com/apache/apisix-ingress-controller/pkg/types/apisix/v1"
 )
 
 var (
@@ -42,6 +46,18 @@ var (
 						Indexer:      &memdb.StringFieldIndex{Field: "UpstreamId"},
 						AllowMissing: true,
 					},
+					"referenced_upstream_id": {
+						Name:         "referenced_upstream_id",
+						Unique:       false,
+						Indexer:      &routeUpstreamsIndex{},
+						AllowMissing: true,
+					},
+					"source": {
+						Name:         "source",
+						Unique:       false,
+						Indexer:      &sourceIndex{},
+						AllowMissing: true,
+					},
 				},
 			},
 			"upstream": {
@@ -68,6 +84,12 @@ var (
 						Unique:  true,
 						Indexer: &memdb.StringFieldIndex{Field: "ID"},
 					},
+					"source": {
+						Name:         "source",
+						Unique:       false,
+						Indexer:      &sourceIndex{},
+						AllowMissing: true,
+					},
 				},
 			},
 			"stream_route": {
@@ -84,6 +106,12 @@ var (
 						Indexer:      &memdb.StringFieldIndex{Field: "UpstreamId"},
 						AllowMissing: true,
 					},
+					"source": {
+						Name:         "source",
+						Unique:       false,
+						Indexer:      &sourceIndex{},
+						AllowMissing: true,
+					},
 				},
 			},
 			"global_rule": {
@@ -104,8 +132,83 @@ var (
 						Unique:  true,
 						Indexer: &memdb.StringFieldIndex{Field: "Username"},
 					},
+					"source": {
+						Name:         "source",
+						Unique:       false,
+						Indexer:      &sourceIndex{},
+						AllowMissing: true,
+					},
 				},
 			},
 		},
 	}
 )
+
+// routeUpstreamsIndex indexes routes by all upstreams they reference,
+// including the ones in plugins like traffic-split.
+type routeUpstreamsIndex struct{}
+
+func (idx *routeUpstreamsIndex) FromObject(obj interface{}) (bool, [][]byte, error) {
+	r, ok := obj.(*v1.Route)
+	if !ok {
+		return false, nil, fmt.Errorf("unexpected object type %T", obj)
+	}
+	ids := r.ReferencedUpstreams()
+	if len(ids) == 0 {
+		return false, nil, nil
+	}
+	vals := make([][]byte, 0, len(ids))
+	for _, id := range ids {
+		// Add the null character as a terminator, see memdb.StringFieldIndex.
+		vals = append(vals, []byte(id+"\x00"))
+	}
+	return true, vals, nil
+}
+
+func (idx *routeUpstreamsIndex) FromArgs(args ...interface{}) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("must provide only a single argument")
+	}
+	id, ok := args[0].(string)
+	if !ok {
+		return nil, fmt.Errorf("argument must be a string: %#v", args[0])
+	}
+	return []byte(id + "\x00"), nil
+}
+
+// sourceIndex indexes resources by their source Kubernetes objects, see
+// v1.SourceKey.
+type sourceIndex struct{}
+
+func (idx *sourceIndex) FromObject(obj interface{}) (bool, []byte, error) {
+	var labels map[string]string
+	switch v := obj.(type) {
+	case *v1.Route:
+		labels = v.Labels
+	case *v1.StreamRoute:
+		labels = v.Labels
+	case *v1.Ssl:
+		labels = v.Labels
+	case *v1.Consumer:
+		labels = v.Labels
+	default:
+		return false, nil, fmt.Errorf("unexpected object type %T", obj)
+	}
+	key := v1.SourceKey(labels)
+	if key == "" {
+		return false, nil, nil
+	}
+	// Add the null character as a terminator, see memdb.StringFieldIndex.
+	return true, []byte(key + "\x00"), nil
+}
+
+func (idx *sourceIndex) FromArgs(args ...interface{}) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("must provide only a single argument")
+	}
+	key, ok := args[0].(string)
+	if !ok {
+		return nil, fmt.Errorf("argument must be a string: %#v", args[0])
+	}
+	return []byte(key + "\x00"), nil
+}
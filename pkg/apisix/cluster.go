@@ -15,7 +15,10 @@
 package apisix
 
 import (
+	"bytes"
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -24,6 +27,7 @@ import (
 	"net"
 	"net/http"
 	"net/url"
+	"reflect"
 	"strings"
 	"sync/atomic"
 	"time"
@@ -34,6 +38,8 @@ import (
 
 	"github.com/apache/apisix-ingress-controller/pkg/apisix/cache"
 	"github.com/apache/apisix-ingress-controller/pkg/log"
+	"github.com/apache/apisix-ingress-controller/pkg/metrics"
+	v1 "github.com/apache/apisix-ingress-controller/pkg/types/apisix/v1"
 )
 
 const (
@@ -51,19 +57,6 @@ var (
 	ErrDuplicatedCluster = errors.New("duplicated cluster")
 
 	_errReadOnClosedResBody = errors.New("http: read on closed response body")
-
-	// Default shared transport for apisix client
-	_defaultTransport = &http.Transport{
-		Proxy: http.ProxyFromEnvironment,
-		Dial: (&net.Dialer{
-			Timeout: 3 * time.Second,
-		}).Dial,
-		DialContext: (&net.Dialer{
-			Timeout: 3 * time.Second,
-		}).DialContext,
-		ResponseHeaderTimeout: 30 * time.Second,
-		ExpectContinueTimeout: 1 * time.Second,
-	}
 )
 
 // ClusterOptions contains parameters to customize APISIX client.
@@ -72,24 +65,42 @@ type ClusterOptions struct {
 	AdminKey string
 	BaseURL  string
 	Timeout  time.Duration
+	// MetricsCollector is used to collect metrics of requests to the
+	// Admin API, metrics are dropped if it's nil.
+	MetricsCollector metrics.Collector
+	// CACert is the PEM encoded CA bundle to verify the Admin API server
+	// certificate, the system roots are used if it's empty.
+	CACert []byte
+	// ClientCert and ClientKey are the PEM encoded client certificate and
+	// key, which are used if the Admin API requires client certificates.
+	ClientCert []byte
+	ClientKey  []byte
+	// ServerName is used to verify the hostname of the server certificate.
+	ServerName string
+	// InsecureSkipVerify disables the server certificate verification.
+	InsecureSkipVerify bool
 }
 
 type cluster struct {
-	name         string
-	baseURL      string
-	baseURLHost  string
-	adminKey     string
-	cli          *http.Client
-	cacheState   int32
-	cache        cache.Cache
-	cacheSynced  chan struct{}
-	cacheSyncErr error
-	route        Route
-	upstream     Upstream
-	ssl          SSL
-	streamRoute  StreamRoute
-	globalRules  GlobalRule
-	consumer     Consumer
+	name             string
+	baseURL          string
+	baseURLHost      string
+	adminKey         atomic.Value
+	cli              *http.Client
+	cacheState       int32
+	cache            cache.Cache
+	cacheSynced      chan struct{}
+	cacheSyncErr     error
+	route            Route
+	upstream         Upstream
+	ssl              SSL
+	streamRoute      StreamRoute
+	globalRules      GlobalRule
+	consumer         Consumer
+	metricsCollector metrics.Collector
+	// connOpts are options to connect the Admin API, the admin key
+	// is excluded as it can be changed in place.
+	connOpts ClusterOptions
 }
 
 func newCluster(o *ClusterOptions) (Cluster, error) {
@@ -100,24 +111,34 @@ func newCluster(o *ClusterOptions) (Cluster, error) {
 		o.Timeout = _defaultTimeout
 	}
 	o.BaseURL = strings.TrimSuffix(o.BaseURL, "/")
+	if o.MetricsCollector == nil {
+		o.MetricsCollector = metrics.NewNoopCollector()
+	}
 
 	u, err := url.Parse(o.BaseURL)
 	if err != nil {
 		return nil, err
 	}
+	transport, err := newTransport(o)
+	if err != nil {
+		return nil, err
+	}
 
 	c := &cluster{
 		name:        o.Name,
 		baseURL:     o.BaseURL,
 		baseURLHost: u.Host,
-		adminKey:    o.AdminKey,
 		cli: &http.Client{
 			Timeout:   o.Timeout,
-			Transport: _defaultTransport,
+			Transport: transport,
 		},
-		cacheState:  _cacheSyncing, // default state
-		cacheSynced: make(chan struct{}),
+		cacheState:       _cacheSyncing, // default state
+		cacheSynced:      make(chan struct{}),
+		metricsCollector: o.MetricsCollector,
+		connOpts:         *o,
 	}
+	c.connOpts.AdminKey = ""
+	c.adminKey.Store(o.AdminKey)
 	c.route = newRouteClient(c)
 	c.upstream = newUpstreamClient(c)
 	c.ssl = newSSLClient(c)
@@ -135,6 +156,44 @@ func newCluster(o *ClusterOptions) (Cluster, error) {
 	return c, nil
 }
 
+// newTransport creates the http.Transport for a cluster, so that clusters
+// can have their own TLS configurations.
+func newTransport(o *ClusterOptions) (*http.Transport, error) {
+	transport := &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout: 3 * time.Second,
+		}).DialContext,
+		ResponseHeaderTimeout: 30 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+	}
+	if len(o.CACert) == 0 && len(o.ClientCert) == 0 && len(o.ClientKey) == 0 &&
+		o.ServerName == "" && !o.InsecureSkipVerify {
+		return transport, nil
+	}
+
+	tlsConfig := &tls.Config{
+		ServerName:         o.ServerName,
+		InsecureSkipVerify: o.InsecureSkipVerify,
+	}
+	if len(o.CACert) > 0 {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(o.CACert) {
+			return nil, errors.New("invalid ca certificate")
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if len(o.ClientCert) > 0 || len(o.ClientKey) > 0 {
+		cert, err := tls.X509KeyPair(o.ClientCert, o.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("invalid client certificate: %s", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	transport.TLSClientConfig = tlsConfig
+	return transport, nil
+}
+
 func (c *cluster) syncCache() {
 	log.Infow("syncing cache", zap.String("cluster", c.name))
 	now := time.Now()
@@ -176,38 +235,11 @@ func (c *cluster) syncCache() {
 }
 
 func (c *cluster) syncCacheOnce() (bool, error) {
-	routes, err := c.route.List(context.TODO())
-	if err != nil {
-		log.Errorf("failed to list route in APISIX: %s", err)
-		return false, err
-	}
-	upstreams, err := c.upstream.List(context.TODO())
-	if err != nil {
-		log.Errorf("failed to list upstreams in APISIX: %s", err)
-		return false, err
-	}
-	ssl, err := c.ssl.List(context.TODO())
+	snap, err := c.list(context.TODO())
 	if err != nil {
-		log.Errorf("failed to list ssl in APISIX: %s", err)
 		return false, err
 	}
-	streamRoutes, err := c.streamRoute.List(context.TODO())
-	if err != nil {
-		log.Errorf("failed to list stream_routes in APISIX: %s", err)
-		return false, err
-	}
-	globalRules, err := c.globalRules.List(context.TODO())
-	if err != nil {
-		log.Errorf("failed to list global_rules in APISIX: %s", err)
-		return false, err
-	}
-	consumers, err := c.consumer.List(context.TODO())
-	if err != nil {
-		log.Errorf("failed to list consumers in APISIX: %s", err)
-		return false, err
-	}
-
-	for _, r := range routes {
+	for _, r := range snap.Routes {
 		if err := c.cache.InsertRoute(r); err != nil {
 			log.Errorw("failed to insert route to cache",
 				zap.String("route", r.ID),
@@ -217,7 +249,7 @@ func (c *cluster) syncCacheOnce() (bool, error) {
 			return false, err
 		}
 	}
-	for _, u := range upstreams {
+	for _, u := range snap.Upstreams {
 		if err := c.cache.InsertUpstream(u); err != nil {
 			log.Errorw("failed to insert upstream to cache",
 				zap.String("upstream", u.ID),
@@ -227,7 +259,7 @@ func (c *cluster) syncCacheOnce() (bool, error) {
 			return false, err
 		}
 	}
-	for _, s := range ssl {
+	for _, s := range snap.SSLs {
 		if err := c.cache.InsertSSL(s); err != nil {
 			log.Errorw("failed to insert ssl to cache",
 				zap.String("ssl", s.ID),
@@ -237,7 +269,7 @@ func (c *cluster) syncCacheOnce() (bool, error) {
 			return false, err
 		}
 	}
-	for _, sr := range streamRoutes {
+	for _, sr := range snap.StreamRoutes {
 		if err := c.cache.InsertStreamRoute(sr); err != nil {
 			log.Errorw("failed to insert stream_route to cache",
 				zap.Any("stream_route", sr),
@@ -247,7 +279,7 @@ func (c *cluster) syncCacheOnce() (bool, error) {
 			return false, err
 		}
 	}
-	for _, gr := range globalRules {
+	for _, gr := range snap.GlobalRules {
 		if err := c.cache.InsertGlobalRule(gr); err != nil {
 			log.Errorw("failed to insert global_rule to cache",
 				zap.Any("global_rule", gr),
@@ -257,7 +289,7 @@ func (c *cluster) syncCacheOnce() (bool, error) {
 			return false, err
 		}
 	}
-	for _, consumer := range consumers {
+	for _, consumer := range snap.Consumers {
 		if err := c.cache.InsertConsumer(consumer); err != nil {
 			log.Errorw("failed to insert consumer to cache",
 				zap.Any("consumer", consumer),
@@ -269,6 +301,325 @@ func (c *cluster) syncCacheOnce() (bool, error) {
 	return true, nil
 }
 
+// list lists all resources from APISIX.
+func (c *cluster) list(ctx context.Context) (*Snapshot, error) {
+	routes, err := c.route.List(ctx)
+	if err != nil {
+		log.Errorf("failed to list route in APISIX: %s", err)
+		return nil, err
+	}
+	upstreams, err := c.upstream.List(ctx)
+	if err != nil {
+		log.Errorf("failed to list upstreams in APISIX: %s", err)
+		return nil, err
+	}
+	ssl, err := c.ssl.List(ctx)
+	if err != nil {
+		log.Errorf("failed to list ssl in APISIX: %s", err)
+		return nil, err
+	}
+	streamRoutes, err := c.streamRoute.List(ctx)
+	if err != nil {
+		log.Errorf("failed to list stream_routes in APISIX: %s", err)
+		return nil, err
+	}
+	globalRules, err := c.globalRules.List(ctx)
+	if err != nil {
+		log.Errorf("failed to list global_rules in APISIX: %s", err)
+		return nil, err
+	}
+	consumers, err := c.consumer.List(ctx)
+	if err != nil {
+		log.Errorf("failed to list consumers in APISIX: %s", err)
+		return nil, err
+	}
+	return &Snapshot{
+		Routes:       routes,
+		Upstreams:    upstreams,
+		SSLs:         ssl,
+		StreamRoutes: streamRoutes,
+		GlobalRules:  globalRules,
+		Consumers:    consumers,
+	}, nil
+}
+
+// Resync implements Cluster.Resync method.
+func (c *cluster) Resync(ctx context.Context) (*Snapshot, error) {
+	if err := c.HasSynced(ctx); err != nil {
+		return nil, err
+	}
+	before, err := c.cachedSnapshot()
+	if err != nil {
+		return nil, err
+	}
+	snap, err := c.list(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.refreshCache(before, snap); err != nil {
+		log.Errorw("failed to refresh cache",
+			zap.String("cluster", c.name),
+			zap.Error(err),
+		)
+		return nil, err
+	}
+	return snap, nil
+}
+
+// ListBySource implements Cluster.ListBySource method.
+func (c *cluster) ListBySource(ctx context.Context, source string) (*Snapshot, error) {
+	if err := c.HasSynced(ctx); err != nil {
+		return nil, err
+	}
+	routes, err := c.cache.ListRoutesBySource(source)
+	if err != nil {
+		return nil, err
+	}
+	streamRoutes, err := c.cache.ListStreamRoutesBySource(source)
+	if err != nil {
+		return nil, err
+	}
+	ssls, err := c.cache.ListSSLBySource(source)
+	if err != nil {
+		return nil, err
+	}
+	consumers, err := c.cache.ListConsumersBySource(source)
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []string
+	for _, r := range routes {
+		ids = append(ids, r.ReferencedUpstreams()...)
+	}
+	for _, sr := range streamRoutes {
+		if sr.UpstreamId != "" {
+			ids = append(ids, sr.UpstreamId)
+		}
+	}
+	var upstreams []*v1.Upstream
+	seen := make(map[string]struct{}, len(ids))
+	for _, id := range ids {
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		u, err := c.cache.GetUpstream(id)
+		if err != nil {
+			if err == cache.ErrNotFound {
+				continue
+			}
+			return nil, err
+		}
+		upstreams = append(upstreams, u)
+	}
+	return &Snapshot{
+		Routes:       routes,
+		Upstreams:    upstreams,
+		SSLs:         ssls,
+		StreamRoutes: streamRoutes,
+		Consumers:    consumers,
+	}, nil
+}
+
+// cachedSnapshot lists all resources in the cache.
+func (c *cluster) cachedSnapshot() (*Snapshot, error) {
+	routes, err := c.cache.ListRoutes()
+	if err != nil {
+		return nil, err
+	}
+	upstreams, err := c.cache.ListUpstreams()
+	if err != nil {
+		return nil, err
+	}
+	ssls, err := c.cache.ListSSL()
+	if err != nil {
+		return nil, err
+	}
+	streamRoutes, err := c.cache.ListStreamRoutes()
+	if err != nil {
+		return nil, err
+	}
+	globalRules, err := c.cache.ListGlobalRules()
+	if err != nil {
+		return nil, err
+	}
+	consumers, err := c.cache.ListConsumers()
+	if err != nil {
+		return nil, err
+	}
+	return &Snapshot{
+		Routes:       routes,
+		Upstreams:    upstreams,
+		SSLs:         ssls,
+		StreamRoutes: streamRoutes,
+		GlobalRules:  globalRules,
+		Consumers:    consumers,
+	}, nil
+}
+
+// refreshCache makes the cache consistent with the snapshot listed from
+// APISIX, the before is the content of the cache when the list started.
+// Objects which were changed in the cache since then, e.g. pushed by
+// workers concurrently, are newer than the snapshot, so they are kept as is.
+// Other objects are removed if they don't exist in the snapshot, and
+// replaced with the listed ones otherwise.
+func (c *cluster) refreshCache(before, snap *Snapshot) error {
+	current, err := c.cachedSnapshot()
+	if err != nil {
+		return err
+	}
+	routes := newCacheRefresh(routeObjects(before.Routes), routeObjects(current.Routes), routeObjects(snap.Routes))
+	upstreams := newCacheRefresh(upstreamObjects(before.Upstreams), upstreamObjects(current.Upstreams), upstreamObjects(snap.Upstreams))
+	ssls := newCacheRefresh(sslObjects(before.SSLs), sslObjects(current.SSLs), sslObjects(snap.SSLs))
+	streamRoutes := newCacheRefresh(streamRouteObjects(before.StreamRoutes), streamRouteObjects(current.StreamRoutes), streamRouteObjects(snap.StreamRoutes))
+	globalRules := newCacheRefresh(globalRuleObjects(before.GlobalRules), globalRuleObjects(current.GlobalRules), globalRuleObjects(snap.GlobalRules))
+	consumers := newCacheRefresh(consumerObjects(before.Consumers), consumerObjects(current.Consumers), consumerObjects(snap.Consumers))
+
+	for _, obj := range routes.deletes {
+		if err := c.cache.DeleteRoute(obj.(*v1.Route)); err != nil && err != cache.ErrNotFound {
+			return err
+		}
+	}
+	for _, obj := range streamRoutes.deletes {
+		if err := c.cache.DeleteStreamRoute(obj.(*v1.StreamRoute)); err != nil && err != cache.ErrNotFound {
+			return err
+		}
+	}
+	// Upstreams should be deleted after routes and stream routes, as they
+	// might be still referenced.
+	for _, obj := range upstreams.deletes {
+		if err := c.cache.DeleteUpstream(obj.(*v1.Upstream)); err != nil && err != cache.ErrNotFound && err != cache.ErrStillInUse {
+			return err
+		}
+	}
+	for _, obj := range ssls.deletes {
+		if err := c.cache.DeleteSSL(obj.(*v1.Ssl)); err != nil && err != cache.ErrNotFound {
+			return err
+		}
+	}
+	for _, obj := range globalRules.deletes {
+		if err := c.cache.DeleteGlobalRule(obj.(*v1.GlobalRule)); err != nil && err != cache.ErrNotFound {
+			return err
+		}
+	}
+	for _, obj := range consumers.deletes {
+		if err := c.cache.DeleteConsumer(obj.(*v1.Consumer)); err != nil && err != cache.ErrNotFound {
+			return err
+		}
+	}
+
+	for _, obj := range upstreams.inserts {
+		if err := c.cache.InsertUpstream(obj.(*v1.Upstream)); err != nil {
+			return err
+		}
+	}
+	for _, obj := range routes.inserts {
+		if err := c.cache.InsertRoute(obj.(*v1.Route)); err != nil {
+			return err
+		}
+	}
+	for _, obj := range streamRoutes.inserts {
+		if err := c.cache.InsertStreamRoute(obj.(*v1.StreamRoute)); err != nil {
+			return err
+		}
+	}
+	for _, obj := range ssls.inserts {
+		if err := c.cache.InsertSSL(obj.(*v1.Ssl)); err != nil {
+			return err
+		}
+	}
+	for _, obj := range globalRules.inserts {
+		if err := c.cache.InsertGlobalRule(obj.(*v1.GlobalRule)); err != nil {
+			return err
+		}
+	}
+	for _, obj := range consumers.inserts {
+		if err := c.cache.InsertConsumer(obj.(*v1.Consumer)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// cacheRefresh contains the objects of a resource which should be inserted
+// into or deleted from the cache in a refresh.
+type cacheRefresh struct {
+	inserts []interface{}
+	deletes []interface{}
+}
+
+// newCacheRefresh compares objects (keyed by their IDs) in the cache before
+// the list started, in the cache now and in the listed snapshot. Only the
+// objects which weren't changed since the list started are refreshed.
+func newCacheRefresh(before, current, listed map[string]interface{}) *cacheRefresh {
+	changed := func(id string) bool {
+		old, existed := before[id]
+		obj, exists := current[id]
+		return existed != exists || !reflect.DeepEqual(old, obj)
+	}
+	refresh := &cacheRefresh{}
+	for id, obj := range current {
+		if _, ok := listed[id]; !ok && !changed(id) {
+			refresh.deletes = append(refresh.deletes, obj)
+		}
+	}
+	for id, obj := range listed {
+		if !changed(id) {
+			refresh.inserts = append(refresh.inserts, obj)
+		}
+	}
+	return refresh
+}
+
+func routeObjects(routes []*v1.Route) map[string]interface{} {
+	objs := make(map[string]interface{}, len(routes))
+	for _, r := range routes {
+		objs[r.ID] = r
+	}
+	return objs
+}
+
+func upstreamObjects(upstreams []*v1.Upstream) map[string]interface{} {
+	objs := make(map[string]interface{}, len(upstreams))
+	for _, u := range upstreams {
+		objs[u.ID] = u
+	}
+	return objs
+}
+
+func sslObjects(ssls []*v1.Ssl) map[string]interface{} {
+	objs := make(map[string]interface{}, len(ssls))
+	for _, ssl := range ssls {
+		objs[ssl.ID] = ssl
+	}
+	return objs
+}
+
+func streamRouteObjects(streamRoutes []*v1.StreamRoute) map[string]interface{} {
+	objs := make(map[string]interface{}, len(streamRoutes))
+	for _, sr := range streamRoutes {
+		objs[sr.ID] = sr
+	}
+	return objs
+}
+
+func globalRuleObjects(globalRules []*v1.GlobalRule) map[string]interface{} {
+	objs := make(map[string]interface{}, len(globalRules))
+	for _, gr := range globalRules {
+		objs[gr.ID] = gr
+	}
+	return objs
+}
+
+func consumerObjects(consumers []*v1.Consumer) map[string]interface{} {
+	objs := make(map[string]interface{}, len(consumers))
+	for _, consumer := range consumers {
+		objs[consumer.Username] = consumer
+	}
+	return objs
+}
+
 // String implements Cluster.String method.
 func (c *cluster) String() string {
 	return fmt.Sprintf("name=%s; base_url=%s", c.name, c.baseURL)
@@ -379,23 +730,69 @@ func (c *cluster) healthCheck(ctx context.Context) (err error) {
 	return
 }
 
+// close releases the cluster after it's removed or replaced, requests in
+// flight are not affected. Connections are closed as clusters no longer
+// share the transport, and the cache is dropped once the syncing is done.
+func (c *cluster) close() {
+	c.cli.CloseIdleConnections()
+	go func() {
+		<-c.cacheSynced
+		if err := c.cache.Drain(); err != nil {
+			log.Warnw("failed to drain cache",
+				zap.String("cluster", c.name),
+				zap.Error(err),
+			)
+		}
+	}()
+}
+
 func (c *cluster) applyAuth(req *http.Request) {
-	if c.adminKey != "" {
-		req.Header.Set("X-API-Key", c.adminKey)
+	if key, _ := c.adminKey.Load().(string); key != "" {
+		req.Header.Set("X-API-Key", key)
 	}
 }
 
-func (c *cluster) do(req *http.Request) (*http.Response, error) {
+// updateAdminKey changes the admin key in place if other options to
+// connect the Admin API are not changed, so that the cache is kept.
+func (c *cluster) updateAdminKey(o *ClusterOptions) bool {
+	timeout := o.Timeout
+	if timeout == time.Duration(0) {
+		timeout = _defaultTimeout
+	}
+	old := &c.connOpts
+	if old.Name != o.Name ||
+		old.BaseURL != strings.TrimSuffix(o.BaseURL, "/") ||
+		old.Timeout != timeout ||
+		!bytes.Equal(old.CACert, o.CACert) ||
+		!bytes.Equal(old.ClientCert, o.ClientCert) ||
+		!bytes.Equal(old.ClientKey, o.ClientKey) ||
+		old.ServerName != o.ServerName ||
+		old.InsecureSkipVerify != o.InsecureSkipVerify {
+		return false
+	}
+	c.adminKey.Store(o.AdminKey)
+	return true
+}
+
+func (c *cluster) do(req *http.Request, resource string) (*http.Response, error) {
 	c.applyAuth(req)
-	return c.cli.Do(req)
+
+	start := time.Now()
+	resp, err := c.cli.Do(req)
+	c.metricsCollector.RecordAPISIXLatency(time.Since(start), c.name, resource, req.Method)
+	c.metricsCollector.IncrAPISIXRequest(c.name, resource, req.Method)
+	if err == nil {
+		c.metricsCollector.RecordAPISIXCode(resp.StatusCode, c.name, resource, req.Method)
+	}
+	return resp, err
 }
 
-func (c *cluster) getResource(ctx context.Context, url string) (*getResponse, error) {
+func (c *cluster) getResource(ctx context.Context, url, resource string) (*getResponse, error) {
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
 		return nil, err
 	}
-	resp, err := c.do(req)
+	resp, err := c.do(req, resource)
 	if err != nil {
 		return nil, err
 	}
@@ -419,12 +816,12 @@ func (c *cluster) getResource(ctx context.Context, url string) (*getResponse, er
 	return &res, nil
 }
 
-func (c *cluster) listResource(ctx context.Context, url string) (*listResponse, error) {
+func (c *cluster) listResource(ctx context.Context, url, resource string) (*listResponse, error) {
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
 		return nil, err
 	}
-	resp, err := c.do(req)
+	resp, err := c.do(req, resource)
 	if err != nil {
 		return nil, err
 	}
@@ -444,12 +841,12 @@ func (c *cluster) listResource(ctx context.Context, url string) (*listResponse,
 	return &list, nil
 }
 
-func (c *cluster) createResource(ctx context.Context, url string, body io.Reader) (*createResponse, error) {
+func (c *cluster) createResource(ctx context.Context, url, resource string, body io.Reader) (*createResponse, error) {
 	req, err := http.NewRequestWithContext(ctx, http.MethodPut, url, body)
 	if err != nil {
 		return nil, err
 	}
-	resp, err := c.do(req)
+	resp, err := c.do(req, resource)
 	if err != nil {
 		return nil, err
 	}
@@ -470,12 +867,12 @@ func (c *cluster) createResource(ctx context.Context, url string, body io.Reader
 	return &cr, nil
 }
 
-func (c *cluster) updateResource(ctx context.Context, url string, body io.Reader) (*updateResponse, error) {
+func (c *cluster) updateResource(ctx context.Context, url, resource string, body io.Reader) (*updateResponse, error) {
 	req, err := http.NewRequestWithContext(ctx, http.MethodPut, url, body)
 	if err != nil {
 		return nil, err
 	}
-	resp, err := c.do(req)
+	resp, err := c.do(req, resource)
 	if err != nil {
 		return nil, err
 	}
@@ -494,12 +891,12 @@ func (c *cluster) updateResource(ctx context.Context, url string, body io.Reader
 	return &ur, nil
 }
 
-func (c *cluster) deleteResource(ctx context.Context, url string) error {
+func (c *cluster) deleteResource(ctx context.Context, url, resource string) error {
 	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, url, nil)
 	if err != nil {
 		return err
 	}
-	resp, err := c.do(req)
+	resp, err := c.do(req, resource)
 	if err != nil {
 		return err
 	}
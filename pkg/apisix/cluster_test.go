@@ -17,8 +17,14 @@ package apisix
 
 import (
 	"context"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 
+	"github.com/apache/apisix-ingress-controller/pkg/apisix/cache"
+	"github.com/apache/apisix-ingress-controller/pkg/metrics"
 	v1 "github.com/apache/apisix-ingress-controller/pkg/types/apisix/v1"
 	"github.com/stretchr/testify/assert"
 )
@@ -51,6 +57,64 @@ func TestAddCluster(t *testing.T) {
 	assert.Len(t, clusters, 2)
 }
 
+func TestUpdateCluster(t *testing.T) {
+	apisix, err := NewClient()
+	assert.Nil(t, err)
+
+	err = apisix.UpdateCluster(&ClusterOptions{
+		Name:    "service1",
+		BaseURL: "http://service1:9080/apisix/admin",
+	})
+	assert.Equal(t, ErrClusterNotExist, err)
+
+	err = apisix.AddCluster(&ClusterOptions{
+		Name:     "service1",
+		AdminKey: "123",
+		BaseURL:  "http://service1:9080/apisix/admin",
+	})
+	assert.Nil(t, err)
+	old := apisix.Cluster("service1")
+
+	// Only the admin key is changed, the cluster is updated in place.
+	err = apisix.UpdateCluster(&ClusterOptions{
+		Name:     "service1",
+		AdminKey: "456",
+		BaseURL:  "http://service1:9080/apisix/admin/",
+	})
+	assert.Nil(t, err)
+	assert.True(t, old == apisix.Cluster("service1"))
+	req, err := http.NewRequest(http.MethodGet, "http://service1:9080/apisix/admin/routes", nil)
+	assert.Nil(t, err)
+	old.(*cluster).applyAuth(req)
+	assert.Equal(t, "456", req.Header.Get("X-API-Key"))
+
+	err = apisix.UpdateCluster(&ClusterOptions{
+		Name:     "service1",
+		AdminKey: "456",
+		BaseURL:  "http://service2:9080/apisix/admin",
+	})
+	assert.Nil(t, err)
+	assert.False(t, old == apisix.Cluster("service1"))
+}
+
+func TestDeleteCluster(t *testing.T) {
+	apisix, err := NewClient()
+	assert.Nil(t, err)
+
+	assert.Equal(t, ErrClusterNotExist, apisix.DeleteCluster("service1"))
+
+	err = apisix.AddCluster(&ClusterOptions{
+		Name:    "service1",
+		BaseURL: "http://service1:9080/apisix/admin",
+	})
+	assert.Nil(t, err)
+	assert.Len(t, apisix.ListClusters(), 1)
+
+	assert.Nil(t, apisix.DeleteCluster("service1"))
+	assert.Len(t, apisix.ListClusters(), 0)
+	assert.Equal(t, "non-existent cluster", apisix.Cluster("service1").String())
+}
+
 func TestNonExistentCluster(t *testing.T) {
 	apisix, err := NewClient()
 	assert.Nil(t, err)
@@ -87,3 +151,178 @@ func TestNonExistentCluster(t *testing.T) {
 	err = apisix.Cluster("non-existent-cluster").SSL().Delete(context.Background(), &v1.Ssl{})
 	assert.Equal(t, ErrClusterNotExist, err)
 }
+
+func TestRefreshCache(t *testing.T) {
+	db, err := cache.NewMemDBCache()
+	assert.Nil(t, err)
+	c := &cluster{
+		name:  "test",
+		cache: db,
+	}
+
+	staleUps := &v1.Upstream{Metadata: v1.Metadata{ID: "1", Name: "stale"}}
+	staleRoute := &v1.Route{Metadata: v1.Metadata{ID: "1", Name: "stale"}, UpstreamId: "1"}
+	assert.Nil(t, db.InsertUpstream(staleUps))
+	assert.Nil(t, db.InsertRoute(staleRoute))
+	assert.Nil(t, db.InsertConsumer(&v1.Consumer{Username: "jack"}))
+	assert.Nil(t, db.InsertSSL(&v1.Ssl{ID: "1", Snis: []string{"a.com"}}))
+	before, err := c.cachedSnapshot()
+	assert.Nil(t, err)
+
+	// Pushed by workers after the list started.
+	assert.Nil(t, db.InsertRoute(&v1.Route{Metadata: v1.Metadata{ID: "3", Name: "pushed"}}))
+	assert.Nil(t, db.InsertSSL(&v1.Ssl{ID: "1", Snis: []string{"b.com"}}))
+
+	snap := &Snapshot{
+		Upstreams: []*v1.Upstream{
+			{Metadata: v1.Metadata{ID: "2", Name: "ups"}},
+		},
+		Routes: []*v1.Route{
+			{Metadata: v1.Metadata{ID: "2", Name: "route"}, UpstreamId: "2"},
+		},
+		Consumers: []*v1.Consumer{
+			{Username: "jack", Desc: "updated"},
+		},
+		SSLs: []*v1.Ssl{
+			{ID: "1", Snis: []string{"a.com"}},
+		},
+	}
+	assert.Nil(t, c.refreshCache(before, snap))
+
+	routes, err := db.ListRoutes()
+	assert.Nil(t, err)
+	assert.Len(t, routes, 2)
+	ids := []string{routes[0].ID, routes[1].ID}
+	assert.ElementsMatch(t, []string{"2", "3"}, ids)
+	ssl, err := db.GetSSL("1")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"b.com"}, ssl.Snis)
+	upstreams, err := db.ListUpstreams()
+	assert.Nil(t, err)
+	assert.Len(t, upstreams, 1)
+	assert.Equal(t, "2", upstreams[0].ID)
+	consumer, err := db.GetConsumer("jack")
+	assert.Nil(t, err)
+	assert.Equal(t, "updated", consumer.Desc)
+}
+
+func TestListBySource(t *testing.T) {
+	db, err := cache.NewMemDBCache()
+	assert.Nil(t, err)
+	c := &cluster{
+		name:       "test",
+		cache:      db,
+		cacheState: _cacheSynced,
+	}
+	labels := map[string]string{
+		v1.LabelSourceKind:      "Ingress",
+		v1.LabelSourceNamespace: "default",
+		v1.LabelSourceName:      "foo",
+	}
+	assert.Nil(t, db.InsertUpstream(&v1.Upstream{Metadata: v1.Metadata{ID: "1"}}))
+	assert.Nil(t, db.InsertUpstream(&v1.Upstream{Metadata: v1.Metadata{ID: "2"}}))
+	assert.Nil(t, db.InsertRoute(&v1.Route{Metadata: v1.Metadata{ID: "1", Labels: labels}, UpstreamId: "1"}))
+	assert.Nil(t, db.InsertRoute(&v1.Route{Metadata: v1.Metadata{ID: "2"}, UpstreamId: "2"}))
+	assert.Nil(t, db.InsertSSL(&v1.Ssl{ID: "1", Labels: labels}))
+	assert.Nil(t, db.InsertSSL(&v1.Ssl{ID: "2"}))
+
+	snap, err := c.ListBySource(context.Background(), "Ingress/default/foo")
+	assert.Nil(t, err)
+	assert.Len(t, snap.Routes, 1)
+	assert.Equal(t, "1", snap.Routes[0].ID)
+	assert.Len(t, snap.Upstreams, 1)
+	assert.Equal(t, "1", snap.Upstreams[0].ID)
+	assert.Len(t, snap.SSLs, 1)
+	assert.Equal(t, "1", snap.SSLs[0].ID)
+
+	snap, err = c.ListBySource(context.Background(), "Ingress/default/bar")
+	assert.Nil(t, err)
+	assert.Len(t, snap.Routes, 0)
+	assert.Len(t, snap.Upstreams, 0)
+
+	_, err = newNonExistentCluster().ListBySource(context.Background(), "Ingress/default/foo")
+	assert.Equal(t, ErrClusterNotExist, err)
+}
+
+type fakeCollector struct {
+	metrics.Collector
+
+	requests  []string
+	codes     []int
+	latencies int
+}
+
+func (c *fakeCollector) RecordAPISIXCode(code int, cluster, resource, method string) {
+	c.codes = append(c.codes, code)
+}
+
+func (c *fakeCollector) RecordAPISIXLatency(_ time.Duration, cluster, resource, method string) {
+	c.latencies++
+}
+
+func (c *fakeCollector) IncrAPISIXRequest(cluster, resource, method string) {
+	c.requests = append(c.requests, cluster+" "+resource+" "+method)
+}
+
+func TestClusterMetrics(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	collector := &fakeCollector{}
+	c := &cluster{
+		name:             "test",
+		baseURL:          srv.URL,
+		cli:              srv.Client(),
+		metricsCollector: collector,
+	}
+	_, err := c.getResource(context.Background(), srv.URL+"/routes/1", "route")
+	assert.Equal(t, cache.ErrNotFound, err)
+	assert.Nil(t, c.deleteResource(context.Background(), srv.URL+"/upstreams/1", "upstream"))
+
+	assert.Equal(t, []string{"test route GET", "test upstream DELETE"}, collector.requests)
+	assert.Equal(t, []int{404, 404}, collector.codes)
+	assert.Equal(t, 2, collector.latencies)
+}
+
+func TestNewTransport(t *testing.T) {
+	transport, err := newTransport(&ClusterOptions{})
+	assert.Nil(t, err)
+	assert.Nil(t, transport.TLSClientConfig)
+
+	_, err = newTransport(&ClusterOptions{CACert: []byte("bad ca")})
+	assert.Equal(t, "invalid ca certificate", err.Error())
+
+	_, err = newTransport(&ClusterOptions{ClientCert: []byte("bad cert")})
+	assert.Contains(t, err.Error(), "invalid client certificate")
+
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+	ca := pem.EncodeToMemory(&pem.Block{
+		Type:  "CERTIFICATE",
+		Bytes: srv.Certificate().Raw,
+	})
+
+	// The server certificate is unknown without the CA.
+	transport, err = newTransport(&ClusterOptions{})
+	assert.Nil(t, err)
+	_, err = (&http.Client{Transport: transport}).Get(srv.URL)
+	assert.NotNil(t, err)
+
+	transport, err = newTransport(&ClusterOptions{CACert: ca})
+	assert.Nil(t, err)
+	resp, err := (&http.Client{Transport: transport}).Get(srv.URL)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	resp.Body.Close()
+
+	transport, err = newTransport(&ClusterOptions{InsecureSkipVerify: true})
+	assert.Nil(t, err)
+	resp, err = (&http.Client{Transport: transport}).Get(srv.URL)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	resp.Body.Close()
+}
@@ -65,7 +65,7 @@ func (r *consumerClient) Get(ctx context.Context, name string) (*v1.Consumer, er
 
 	// TODO Add mutex here to avoid dog-pile effect.
 	url := r.url + "/" + name
-	resp, err := r.cluster.getResource(ctx, url)
+	resp, err := r.cluster.getResource(ctx, url, "consumer")
 	if err != nil {
 		if err == cache.ErrNotFound {
 			log.Warnw("consumer not found",
@@ -109,7 +109,7 @@ func (r *consumerClient) List(ctx context.Context) ([]*v1.Consumer, error) {
 		zap.String("cluster", "default"),
 		zap.String("url", r.url),
 	)
-	consumerItems, err := r.cluster.listResource(ctx, r.url)
+	consumerItems, err := r.cluster.listResource(ctx, r.url, "consumer")
 	if err != nil {
 		log.Errorf("failed to list consumers: %s", err)
 		return nil, err
@@ -153,7 +153,7 @@ func (r *consumerClient) Create(ctx context.Context, obj *v1.Consumer) (*v1.Cons
 
 	url := r.url + "/" + obj.Username
 	log.Debugw("creating consumer", zap.ByteString("body", data), zap.String("url", url))
-	resp, err := r.cluster.createResource(ctx, url, bytes.NewReader(data))
+	resp, err := r.cluster.createResource(ctx, url, "consumer", bytes.NewReader(data))
 	if err != nil {
 		log.Errorf("failed to create consumer: %s", err)
 		return nil, err
@@ -180,7 +180,7 @@ func (r *consumerClient) Delete(ctx context.Context, obj *v1.Consumer) error {
 		return err
 	}
 	url := r.url + "/" + obj.Username
-	if err := r.cluster.deleteResource(ctx, url); err != nil {
+	if err := r.cluster.deleteResource(ctx, url, "consumer"); err != nil {
 		return err
 	}
 	if err := r.cluster.cache.DeleteConsumer(obj); err != nil {
@@ -208,7 +208,7 @@ func (r *consumerClient) Update(ctx context.Context, obj *v1.Consumer) (*v1.Cons
 	}
 	url := r.url + "/" + obj.Username
 	log.Debugw("updating username", zap.ByteString("body", body), zap.String("url", url))
-	resp, err := r.cluster.updateResource(ctx, url, bytes.NewReader(body))
+	resp, err := r.cluster.updateResource(ctx, url, "consumer", bytes.NewReader(body))
 	if err != nil {
 		return nil, err
 	}
@@ -67,7 +67,7 @@ func (r *globalRuleClient) Get(ctx context.Context, name string) (*v1.GlobalRule
 
 	// TODO Add mutex here to avoid dog-pile effect.
 	url := r.url + "/" + rid
-	resp, err := r.cluster.getResource(ctx, url)
+	resp, err := r.cluster.getResource(ctx, url, "global_rule")
 	if err != nil {
 		if err == cache.ErrNotFound {
 			log.Warnw("global_rule not found",
@@ -111,7 +111,7 @@ func (r *globalRuleClient) List(ctx context.Context) ([]*v1.GlobalRule, error) {
 		zap.String("cluster", "default"),
 		zap.String("url", r.url),
 	)
-	globalRuleItems, err := r.cluster.listResource(ctx, r.url)
+	globalRuleItems, err := r.cluster.listResource(ctx, r.url, "global_rule")
 	if err != nil {
 		log.Errorf("failed to list global_rules: %s", err)
 		return nil, err
@@ -155,7 +155,7 @@ func (r *globalRuleClient) Create(ctx context.Context, obj *v1.GlobalRule) (*v1.
 
 	url := r.url + "/" + obj.ID
 	log.Debugw("creating global_rule", zap.ByteString("body", data), zap.String("url", url))
-	resp, err := r.cluster.createResource(ctx, url, bytes.NewReader(data))
+	resp, err := r.cluster.createResource(ctx, url, "global_rule", bytes.NewReader(data))
 	if err != nil {
 		log.Errorf("failed to create global_rule: %s", err)
 		return nil, err
@@ -182,7 +182,7 @@ func (r *globalRuleClient) Delete(ctx context.Context, obj *v1.GlobalRule) error
 		return err
 	}
 	url := r.url + "/" + obj.ID
-	if err := r.cluster.deleteResource(ctx, url); err != nil {
+	if err := r.cluster.deleteResource(ctx, url, "global_rule"); err != nil {
 		return err
 	}
 	if err := r.cluster.cache.DeleteGlobalRule(obj); err != nil {
@@ -210,7 +210,7 @@ func (r *globalRuleClient) Update(ctx context.Context, obj *v1.GlobalRule) (*v1.
 	}
 	url := r.url + "/" + obj.ID
 	log.Debugw("updating global_rule", zap.ByteString("body", body), zap.String("url", url))
-	resp, err := r.cluster.updateResource(ctx, url, bytes.NewReader(body))
+	resp, err := r.cluster.updateResource(ctx, url, "global_rule", bytes.NewReader(body))
 	if err != nil {
 		return nil, err
 	}
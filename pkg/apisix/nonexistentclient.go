@@ -212,6 +212,14 @@ func (nc *nonExistentCluster) HealthCheck(_ context.Context) error {
 	return nil
 }
 
+func (nc *nonExistentCluster) Resync(_ context.Context) (*Snapshot, error) {
+	return nil, ErrClusterNotExist
+}
+
+func (nc *nonExistentCluster) ListBySource(_ context.Context, _ string) (*Snapshot, error) {
+	return nil, ErrClusterNotExist
+}
+
 func (nc *nonExistentCluster) String() string {
 	return "non-existent cluster"
 }
@@ -220,27 +228,32 @@ type dummyCache struct{}
 
 var _ cache.Cache = &dummyCache{}
 
-func (c *dummyCache) InsertRoute(_ *v1.Route) error                    { return nil }
-func (c *dummyCache) InsertSSL(_ *v1.Ssl) error                        { return nil }
-func (c *dummyCache) InsertUpstream(_ *v1.Upstream) error              { return nil }
-func (c *dummyCache) InsertStreamRoute(_ *v1.StreamRoute) error        { return nil }
-func (c *dummyCache) InsertGlobalRule(_ *v1.GlobalRule) error          { return nil }
-func (c *dummyCache) InsertConsumer(_ *v1.Consumer) error              { return nil }
-func (c *dummyCache) GetRoute(_ string) (*v1.Route, error)             { return nil, cache.ErrNotFound }
-func (c *dummyCache) GetSSL(_ string) (*v1.Ssl, error)                 { return nil, cache.ErrNotFound }
-func (c *dummyCache) GetUpstream(_ string) (*v1.Upstream, error)       { return nil, cache.ErrNotFound }
-func (c *dummyCache) GetStreamRoute(_ string) (*v1.StreamRoute, error) { return nil, cache.ErrNotFound }
-func (c *dummyCache) GetGlobalRule(_ string) (*v1.GlobalRule, error)   { return nil, cache.ErrNotFound }
-func (c *dummyCache) GetConsumer(_ string) (*v1.Consumer, error)       { return nil, cache.ErrNotFound }
-func (c *dummyCache) ListRoutes() ([]*v1.Route, error)                 { return nil, nil }
-func (c *dummyCache) ListSSL() ([]*v1.Ssl, error)                      { return nil, nil }
-func (c *dummyCache) ListUpstreams() ([]*v1.Upstream, error)           { return nil, nil }
-func (c *dummyCache) ListStreamRoutes() ([]*v1.StreamRoute, error)     { return nil, nil }
-func (c *dummyCache) ListGlobalRules() ([]*v1.GlobalRule, error)       { return nil, nil }
-func (c *dummyCache) ListConsumers() ([]*v1.Consumer, error)           { return nil, nil }
-func (c *dummyCache) DeleteRoute(_ *v1.Route) error                    { return nil }
-func (c *dummyCache) DeleteSSL(_ *v1.Ssl) error                        { return nil }
-func (c *dummyCache) DeleteUpstream(_ *v1.Upstream) error              { return nil }
-func (c *dummyCache) DeleteStreamRoute(_ *v1.StreamRoute) error        { return nil }
-func (c *dummyCache) DeleteGlobalRule(_ *v1.GlobalRule) error          { return nil }
-func (c *dummyCache) DeleteConsumer(_ *v1.Consumer) error              { return nil }
+func (c *dummyCache) InsertRoute(_ *v1.Route) error                                { return nil }
+func (c *dummyCache) InsertSSL(_ *v1.Ssl) error                                    { return nil }
+func (c *dummyCache) InsertUpstream(_ *v1.Upstream) error                          { return nil }
+func (c *dummyCache) InsertStreamRoute(_ *v1.StreamRoute) error                    { return nil }
+func (c *dummyCache) InsertGlobalRule(_ *v1.GlobalRule) error                      { return nil }
+func (c *dummyCache) InsertConsumer(_ *v1.Consumer) error                          { return nil }
+func (c *dummyCache) GetRoute(_ string) (*v1.Route, error)                         { return nil, cache.ErrNotFound }
+func (c *dummyCache) GetSSL(_ string) (*v1.Ssl, error)                             { return nil, cache.ErrNotFound }
+func (c *dummyCache) GetUpstream(_ string) (*v1.Upstream, error)                   { return nil, cache.ErrNotFound }
+func (c *dummyCache) GetStreamRoute(_ string) (*v1.StreamRoute, error)             { return nil, cache.ErrNotFound }
+func (c *dummyCache) GetGlobalRule(_ string) (*v1.GlobalRule, error)               { return nil, cache.ErrNotFound }
+func (c *dummyCache) GetConsumer(_ string) (*v1.Consumer, error)                   { return nil, cache.ErrNotFound }
+func (c *dummyCache) ListRoutes() ([]*v1.Route, error)                             { return nil, nil }
+func (c *dummyCache) ListSSL() ([]*v1.Ssl, error)                                  { return nil, nil }
+func (c *dummyCache) ListUpstreams() ([]*v1.Upstream, error)                       { return nil, nil }
+func (c *dummyCache) ListStreamRoutes() ([]*v1.StreamRoute, error)                 { return nil, nil }
+func (c *dummyCache) ListGlobalRules() ([]*v1.GlobalRule, error)                   { return nil, nil }
+func (c *dummyCache) ListConsumers() ([]*v1.Consumer, error)                       { return nil, nil }
+func (c *dummyCache) ListRoutesBySource(_ string) ([]*v1.Route, error)             { return nil, nil }
+func (c *dummyCache) ListSSLBySource(_ string) ([]*v1.Ssl, error)                  { return nil, nil }
+func (c *dummyCache) ListStreamRoutesBySource(_ string) ([]*v1.StreamRoute, error) { return nil, nil }
+func (c *dummyCache) ListConsumersBySource(_ string) ([]*v1.Consumer, error)       { return nil, nil }
+func (c *dummyCache) DeleteRoute(_ *v1.Route) error                                { return nil }
+func (c *dummyCache) DeleteSSL(_ *v1.Ssl) error                                    { return nil }
+func (c *dummyCache) DeleteUpstream(_ *v1.Upstream) error                          { return nil }
+func (c *dummyCache) DeleteStreamRoute(_ *v1.StreamRoute) error                    { return nil }
+func (c *dummyCache) DeleteGlobalRule(_ *v1.GlobalRule) error                      { return nil }
+func (c *dummyCache) DeleteConsumer(_ *v1.Consumer) error                          { return nil }
+func (c *dummyCache) Drain() error                                                 { return nil }
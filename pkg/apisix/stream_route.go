@@ -67,7 +67,7 @@ func (r *streamRouteClient) Get(ctx context.Context, name string) (*v1.StreamRou
 
 	// TODO Add mutex here to avoid dog-pile effection.
 	url := r.url + "/" + rid
-	resp, err := r.cluster.getResource(ctx, url)
+	resp, err := r.cluster.getResource(ctx, url, "stream_route")
 	if err != nil {
 		if err == cache.ErrNotFound {
 			log.Warnw("stream_route not found",
@@ -111,7 +111,7 @@ func (r *streamRouteClient) List(ctx context.Context) ([]*v1.StreamRoute, error)
 		zap.String("cluster", "default"),
 		zap.String("url", r.url),
 	)
-	streamRouteItems, err := r.cluster.listResource(ctx, r.url)
+	streamRouteItems, err := r.cluster.listResource(ctx, r.url, "stream_route")
 	if err != nil {
 		log.Errorf("failed to list stream_routes: %s", err)
 		return nil, err
@@ -154,7 +154,7 @@ func (r *streamRouteClient) Create(ctx context.Context, obj *v1.StreamRoute) (*v
 
 	url := r.url + "/" + obj.ID
 	log.Debugw("creating stream_route", zap.ByteString("body", data), zap.String("url", url))
-	resp, err := r.cluster.createResource(ctx, url, bytes.NewReader(data))
+	resp, err := r.cluster.createResource(ctx, url, "stream_route", bytes.NewReader(data))
 	if err != nil {
 		log.Errorf("failed to create stream_route: %s", err)
 		return nil, err
@@ -181,7 +181,7 @@ func (r *streamRouteClient) Delete(ctx context.Context, obj *v1.StreamRoute) err
 		return err
 	}
 	url := r.url + "/" + obj.ID
-	if err := r.cluster.deleteResource(ctx, url); err != nil {
+	if err := r.cluster.deleteResource(ctx, url, "stream_route"); err != nil {
 		return err
 	}
 	if err := r.cluster.cache.DeleteStreamRoute(obj); err != nil {
@@ -208,7 +208,7 @@ func (r *streamRouteClient) Update(ctx context.Context, obj *v1.StreamRoute) (*v
 	}
 	url := r.url + "/" + obj.ID
 	log.Debugw("updating stream_route", zap.ByteString("body", body), zap.String("url", url))
-	resp, err := r.cluster.updateResource(ctx, url, bytes.NewReader(body))
+	resp, err := r.cluster.updateResource(ctx, url, "stream_route", bytes.NewReader(body))
 	if err != nil {
 		return nil, err
 	}
@@ -64,7 +64,7 @@ func (u *upstreamClient) Get(ctx context.Context, name string) (*v1.Upstream, er
 
 	// TODO Add mutex here to avoid dog-pile effection.
 	url := u.url + "/" + uid
-	resp, err := u.cluster.getResource(ctx, url)
+	resp, err := u.cluster.getResource(ctx, url, "upstream")
 	if err != nil {
 		if err == cache.ErrNotFound {
 			log.Warnw("upstream not found",
@@ -108,7 +108,7 @@ func (u *upstreamClient) List(ctx context.Context) ([]*v1.Upstream, error) {
 		zap.String("cluster", "default"),
 	)
 
-	upsItems, err := u.cluster.listResource(ctx, u.url)
+	upsItems, err := u.cluster.listResource(ctx, u.url, "upstream")
 	if err != nil {
 		log.Errorf("failed to list upstreams: %s", err)
 		return nil, err
@@ -149,7 +149,7 @@ func (u *upstreamClient) Create(ctx context.Context, obj *v1.Upstream) (*v1.Upst
 	url := u.url + "/" + obj.ID
 	log.Debugw("creating upstream", zap.ByteString("body", body), zap.String("url", url))
 
-	resp, err := u.cluster.createResource(ctx, url, bytes.NewReader(body))
+	resp, err := u.cluster.createResource(ctx, url, "upstream", bytes.NewReader(body))
 	if err != nil {
 		log.Errorf("failed to create upstream: %s", err)
 		return nil, err
@@ -177,7 +177,7 @@ func (u *upstreamClient) Delete(ctx context.Context, obj *v1.Upstream) error {
 		return err
 	}
 	url := u.url + "/" + obj.ID
-	if err := u.cluster.deleteResource(ctx, url); err != nil {
+	if err := u.cluster.deleteResource(ctx, url, "upstream"); err != nil {
 		return err
 	}
 	if err := u.cluster.cache.DeleteUpstream(obj); err != nil {
@@ -208,7 +208,7 @@ func (u *upstreamClient) Update(ctx context.Context, obj *v1.Upstream) (*v1.Upst
 
 	url := u.url + "/" + obj.ID
 	log.Debugw("updating upstream", zap.ByteString("body", body), zap.String("url", url))
-	resp, err := u.cluster.updateResource(ctx, url, bytes.NewReader(body))
+	resp, err := u.cluster.updateResource(ctx, url, "upstream", bytes.NewReader(body))
 	if err != nil {
 		return nil, err
 	}
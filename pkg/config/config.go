@@ -15,15 +15,21 @@
 package config
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"io/ioutil"
+	"reflect"
 	"strings"
 	"time"
 
+	"go.uber.org/zap/zapcore"
 	"gopkg.in/yaml.v2"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
 
+	"github.com/apache/apisix-ingress-controller/pkg/log"
 	"github.com/apache/apisix-ingress-controller/pkg/types"
 )
 
@@ -37,6 +43,9 @@ const (
 	// object's IngressClassName field in Kubernetes clusters version v1.18.0
 	// or higher, or the annotation "kubernetes.io/ingress.class" (deprecated).
 	IngressClass = "apisix"
+	// ControllerName is the default controller name, IngressClass objects
+	// with this spec.controller are handled by apisix-ingress-controller.
+	ControllerName = "apisix.apache.org/apisix-ingress-controller"
 
 	// IngressNetworkingV1 represents ingress.networking/v1
 	IngressNetworkingV1 = "networking/v1"
@@ -54,6 +63,7 @@ const (
 	ApisixRouteV2beta1 = "apisix.apache.org/v2beta1"
 
 	_minimalResyncInterval = 30 * time.Second
+	_defaultGCMaxDeletions = 100
 )
 
 // Config contains all config items which are necessary for
@@ -74,20 +84,56 @@ type KubernetesConfig struct {
 	AppNamespaces       []string           `json:"app_namespaces" yaml:"app_namespaces"`
 	ElectionID          string             `json:"election_id" yaml:"election_id"`
 	IngressClass        string             `json:"ingress_class" yaml:"ingress_class"`
+	ControllerName      string             `json:"controller_name" yaml:"controller_name"`
 	IngressVersion      string             `json:"ingress_version" yaml:"ingress_version"`
 	WatchEndpointSlices bool               `json:"watch_endpoint_slices" yaml:"watch_endpoint_slices"`
 	ApisixRouteVersion  string             `json:"apisix_route_version" yaml:"apisix_route_version"`
+	// NamespaceSelector is a label selector of Namespaces, only resources in
+	// the selected namespaces (among AppNamespaces) are watched, namespaces
+	// are included or excluded once their labels change.
+	NamespaceSelector string `json:"namespace_selector" yaml:"namespace_selector"`
+	// WatchApisixWithoutClass controls whether the Apisix* objects without
+	// the "kubernetes.io/ingress.class" annotation are handled.
+	WatchApisixWithoutClass bool `json:"watch_apisix_without_class" yaml:"watch_apisix_without_class"`
+	// PublishService is the Service (in the namespace/name format) of the
+	// APISIX gateway, its LoadBalancer addresses or external IPs are written
+	// to the status of effective Ingresses.
+	PublishService string `json:"publish_service" yaml:"publish_service"`
+	// PublishStatusAddress is the static addresses (IPs or hostnames) written
+	// to the status of effective Ingresses, it takes precedence over the
+	// PublishService.
+	PublishStatusAddress []string `json:"publish_status_address" yaml:"publish_status_address"`
 }
 
 // APISIXConfig contains all APISIX related config items.
 type APISIXConfig struct {
 	// DefaultClusterName is the name of default cluster.
-	DefaultClusterName string `json:"default_cluster_name"`
+	DefaultClusterName string `json:"default_cluster_name" yaml:"default_cluster_name"`
 	// DefaultClusterBaseURL is the base url configuration for the default cluster.
 	DefaultClusterBaseURL string `json:"default_cluster_base_url" yaml:"default_cluster_base_url"`
 	// DefaultClusterAdminKey is the admin key for the default cluster.
-	// TODO: Obsolete the plain way to specify admin_key, which is insecure.
+	// It's insecure to specify the admin key in plain text, use
+	// DefaultClusterAdminKeySecretRef instead.
 	DefaultClusterAdminKey string `json:"default_cluster_admin_key" yaml:"default_cluster_admin_key"`
+	// DefaultClusterAdminKeySecretRef references the Secret key which
+	// stores the admin key for the default cluster, the admin key will
+	// be updated once the Secret is changed.
+	DefaultClusterAdminKeySecretRef SecretKeyRef `json:"default_cluster_admin_key_secret_ref" yaml:"default_cluster_admin_key_secret_ref"`
+	// DefaultClusterCACert is the path of the CA bundle to verify the Admin
+	// API server certificate of the default cluster.
+	DefaultClusterCACert string `json:"default_cluster_ca_cert" yaml:"default_cluster_ca_cert"`
+	// DefaultClusterClientCert is the path of the client certificate for
+	// the Admin API of the default cluster.
+	DefaultClusterClientCert string `json:"default_cluster_client_cert" yaml:"default_cluster_client_cert"`
+	// DefaultClusterClientKey is the path of the client key for the Admin
+	// API of the default cluster.
+	DefaultClusterClientKey string `json:"default_cluster_client_key" yaml:"default_cluster_client_key"`
+	// DefaultClusterServerName is used to verify the hostname of the Admin
+	// API server certificate of the default cluster.
+	DefaultClusterServerName string `json:"default_cluster_server_name" yaml:"default_cluster_server_name"`
+	// DefaultClusterInsecureSkipVerify disables the Admin API server
+	// certificate verification of the default cluster.
+	DefaultClusterInsecureSkipVerify bool `json:"default_cluster_insecure_skip_verify" yaml:"default_cluster_insecure_skip_verify"`
 	// BaseURL is same to DefaultClusterBaseURL.
 	// Deprecated: use DefaultClusterBaseURL instead. BaseURL will be removed
 	// once v1.0.0 is released.
@@ -96,25 +142,53 @@ type APISIXConfig struct {
 	// Deprecated: use DefaultClusterAdminKey instead. AdminKey will be removed
 	// once v1.0.0 is released.
 	AdminKey string `json:"admin_key" yaml:"admin_key"`
+	// ReconcileInterval is the interval to reconcile the APISIX cluster with
+	// the translated Kubernetes objects, drifts will be repaired. The
+	// reconciliation is disabled if it's zero.
+	ReconcileInterval types.TimeDuration `json:"reconcile_interval" yaml:"reconcile_interval"`
+	// GCDryRun makes the garbage collection (runs with the reconciliation)
+	// only report the orphaned resources instead of deleting them.
+	GCDryRun bool `json:"gc_dry_run" yaml:"gc_dry_run"`
+	// GCMaxDeletions is the maximum number of orphaned resources that
+	// a garbage collection pass may delete, the pass will be aborted if
+	// more orphaned resources are found.
+	GCMaxDeletions int `json:"gc_max_deletions" yaml:"gc_max_deletions"`
+}
+
+// SecretKeyRef references a key in a Kubernetes Secret.
+type SecretKeyRef struct {
+	Namespace string `json:"namespace" yaml:"namespace"`
+	Name      string `json:"name" yaml:"name"`
+	Key       string `json:"key" yaml:"key"`
+}
+
+// IsEmpty returns true if no Secret is referenced.
+func (ref *SecretKeyRef) IsEmpty() bool {
+	return ref.Namespace == "" && ref.Name == "" && ref.Key == ""
 }
 
 // NewDefaultConfig creates a Config object which fills all config items with
 // default value.
 func NewDefaultConfig() *Config {
 	return &Config{
-		LogLevel:        "warn",
+		LogLevel:        "info",
 		LogOutput:       "stderr",
 		HTTPListen:      ":8080",
 		EnableProfiling: true,
 		Kubernetes: KubernetesConfig{
-			Kubeconfig:          "", // Use in-cluster configurations.
-			ResyncInterval:      types.TimeDuration{Duration: 6 * time.Hour},
-			AppNamespaces:       []string{v1.NamespaceAll},
-			ElectionID:          IngressAPISIXLeader,
-			IngressClass:        IngressClass,
-			IngressVersion:      IngressNetworkingV1,
-			ApisixRouteVersion:  ApisixRouteV2alpha1,
-			WatchEndpointSlices: false,
+			Kubeconfig:              "", // Use in-cluster configurations.
+			ResyncInterval:          types.TimeDuration{Duration: 6 * time.Hour},
+			AppNamespaces:           []string{v1.NamespaceAll},
+			ElectionID:              IngressAPISIXLeader,
+			IngressClass:            IngressClass,
+			ControllerName:          ControllerName,
+			IngressVersion:          IngressNetworkingV1,
+			ApisixRouteVersion:      ApisixRouteV2alpha1,
+			WatchEndpointSlices:     false,
+			WatchApisixWithoutClass: true,
+		},
+		APISIX: APISIXConfig{
+			GCMaxDeletions: _defaultGCMaxDeletions,
 		},
 	}
 }
@@ -146,9 +220,26 @@ func (cfg *Config) Validate() error {
 	if cfg.Kubernetes.ResyncInterval.Duration < _minimalResyncInterval {
 		return errors.New("controller resync interval too small")
 	}
+	if cfg.APISIX.ReconcileInterval.Duration != 0 && cfg.APISIX.ReconcileInterval.Duration < _minimalResyncInterval {
+		return errors.New("apisix reconcile interval too small")
+	}
+	if cfg.APISIX.GCMaxDeletions < 0 {
+		return errors.New("apisix gc max deletions should not be negative")
+	}
+	if (cfg.APISIX.DefaultClusterClientCert == "") != (cfg.APISIX.DefaultClusterClientKey == "") {
+		return errors.New("apisix client cert and key should be specified together")
+	}
 	if cfg.APISIX.DefaultClusterAdminKey == "" {
 		cfg.APISIX.DefaultClusterAdminKey = cfg.APISIX.AdminKey
 	}
+	if ref := cfg.APISIX.DefaultClusterAdminKeySecretRef; !ref.IsEmpty() {
+		if ref.Namespace == "" || ref.Name == "" || ref.Key == "" {
+			return errors.New("apisix admin key secret ref requires namespace, name and key")
+		}
+		if cfg.APISIX.DefaultClusterAdminKey != "" {
+			return errors.New("apisix admin key and admin key secret ref are mutually exclusive")
+		}
+	}
 	if cfg.APISIX.DefaultClusterBaseURL == "" {
 		cfg.APISIX.DefaultClusterBaseURL = cfg.APISIX.BaseURL
 	}
@@ -159,16 +250,85 @@ func (cfg *Config) Validate() error {
 	if cfg.APISIX.DefaultClusterBaseURL == "" {
 		return errors.New("apisix base url is required")
 	}
+	if svc := cfg.Kubernetes.PublishService; svc != "" {
+		if parts := strings.Split(svc, "/"); len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return errors.New("publish service should be in the namespace/name format")
+		}
+	}
 	switch cfg.Kubernetes.IngressVersion {
 	case IngressNetworkingV1, IngressNetworkingV1beta1, IngressExtensionsV1beta1:
 		break
 	default:
 		return errors.New("unsupported ingress version")
 	}
+	if _, err := labels.Parse(cfg.Kubernetes.NamespaceSelector); err != nil {
+		return errors.New("invalid namespace selector: " + err.Error())
+	}
 	cfg.Kubernetes.AppNamespaces = purifyAppNamespaces(cfg.Kubernetes.AppNamespaces)
 	return nil
 }
 
+// Hash returns the SHA-256 checksum of the redacted Config (see Redacted) in
+// JSON format, so that the Config in use can be identified without exposing
+// the admin keys. Changes of the admin keys are not reflected, but the ones
+// of the Secret references are.
+func (cfg *Config) Hash() string {
+	// Marshalling never fails as the Config only contains plain types.
+	data, _ := json.Marshal(cfg.Redacted())
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// Redacted returns a copy of the Config whose secrets (the admin keys) are
+// masked, so that it can be shown.
+func (cfg *Config) Redacted() *Config {
+	c := *cfg
+	if c.APISIX.DefaultClusterAdminKey != "" {
+		c.APISIX.DefaultClusterAdminKey = log.Redacted
+	}
+	if c.APISIX.AdminKey != "" {
+		c.APISIX.AdminKey = log.Redacted
+	}
+	return &c
+}
+
+// MarshalLogObject implements the zapcore.ObjectMarshaler interface, the
+// admin keys are redacted.
+func (cfg *Config) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	c := cfg.Redacted()
+	enc.AddString("log_level", c.LogLevel)
+	enc.AddString("log_output", c.LogOutput)
+	enc.AddString("http_listen", c.HTTPListen)
+	enc.AddBool("enable_profiling", c.EnableProfiling)
+	if err := enc.AddReflected("kubernetes", &c.Kubernetes); err != nil {
+		return err
+	}
+	return enc.AddReflected("apisix", &c.APISIX)
+}
+
+// Diff returns the keys (see Keys) of the config items which are different
+// in the other Config, a SecretKeyRef is compared as a whole.
+func (cfg *Config) Diff(other *Config) []string {
+	return diffFields("", reflect.ValueOf(*cfg), reflect.ValueOf(*other))
+}
+
+func diffFields(prefix string, a, b reflect.Value) []string {
+	var fields []string
+	for i := 0; i < a.NumField(); i++ {
+		field := a.Type().Field(i)
+		name := prefix + fieldName(field)
+		switch field.Type {
+		case reflect.TypeOf(KubernetesConfig{}), reflect.TypeOf(APISIXConfig{}):
+			fields = append(fields, diffFields(name+".", a.Field(i), b.Field(i))...)
+			continue
+		}
+		if !reflect.DeepEqual(a.Field(i).Interface(), b.Field(i).Interface()) {
+			fields = append(fields, name)
+		}
+	}
+	return fields
+}
+
 func purifyAppNamespaces(namespaces []string) []string {
 	exists := make(map[string]struct{})
 	var ultimate []string
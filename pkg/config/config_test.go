@@ -15,6 +15,8 @@
 package config
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"io/ioutil"
 	"os"
@@ -33,18 +35,21 @@ func TestNewConfigFromFile(t *testing.T) {
 		HTTPListen:      ":9090",
 		EnableProfiling: true,
 		Kubernetes: KubernetesConfig{
-			ResyncInterval:     types.TimeDuration{Duration: time.Hour},
-			Kubeconfig:         "/path/to/foo/baz",
-			AppNamespaces:      []string{""},
-			ElectionID:         "my-election-id",
-			IngressClass:       IngressClass,
-			IngressVersion:     IngressNetworkingV1,
-			ApisixRouteVersion: ApisixRouteV2alpha1,
+			ResyncInterval:          types.TimeDuration{Duration: time.Hour},
+			Kubeconfig:              "/path/to/foo/baz",
+			AppNamespaces:           []string{""},
+			ElectionID:              "my-election-id",
+			IngressClass:            IngressClass,
+			ControllerName:          ControllerName,
+			IngressVersion:          IngressNetworkingV1,
+			ApisixRouteVersion:      ApisixRouteV2alpha1,
+			WatchApisixWithoutClass: true,
 		},
 		APISIX: APISIXConfig{
 			DefaultClusterName:     "default",
 			DefaultClusterBaseURL:  "http://127.0.0.1:8080/apisix",
 			DefaultClusterAdminKey: "123456",
+			GCMaxDeletions:         100,
 		},
 	}
 
@@ -156,4 +161,120 @@ apisix:
 	assert.Nil(t, err, "failed to new config from file: ", err)
 	err = newCfg.Validate()
 	assert.Equal(t, err.Error(), "controller resync interval too small", "bad error: ", err)
+
+	yamlData = `
+apisix:
+  base_url: http://127.0.0.1:1234/apisix
+  reconcile_interval: 10s
+`
+	tmpYAML, err = ioutil.TempFile("/tmp", "config-*.yaml")
+	assert.Nil(t, err, "failed to create temporary yaml configuration file: ", err)
+	defer os.Remove(tmpYAML.Name())
+
+	_, err = tmpYAML.Write([]byte(yamlData))
+	assert.Nil(t, err, "failed to write yaml data: ", err)
+	tmpYAML.Close()
+
+	newCfg, err = NewConfigFromFile(tmpYAML.Name())
+	assert.Nil(t, err, "failed to new config from file: ", err)
+	err = newCfg.Validate()
+	assert.Equal(t, err.Error(), "apisix reconcile interval too small", "bad error: ", err)
+
+	yamlData = `
+apisix:
+  base_url: http://127.0.0.1:1234/apisix
+  default_cluster_client_cert: /path/to/cert.pem
+`
+	tmpYAML, err = ioutil.TempFile("/tmp", "config-*.yaml")
+	assert.Nil(t, err, "failed to create temporary yaml configuration file: ", err)
+	defer os.Remove(tmpYAML.Name())
+
+	_, err = tmpYAML.Write([]byte(yamlData))
+	assert.Nil(t, err, "failed to write yaml data: ", err)
+	tmpYAML.Close()
+
+	newCfg, err = NewConfigFromFile(tmpYAML.Name())
+	assert.Nil(t, err, "failed to new config from file: ", err)
+	err = newCfg.Validate()
+	assert.Equal(t, err.Error(), "apisix client cert and key should be specified together", "bad error: ", err)
+
+	yamlData = `
+apisix:
+  base_url: http://127.0.0.1:1234/apisix
+  default_cluster_admin_key_secret_ref:
+    namespace: apisix
+    name: admin-key
+`
+	tmpYAML, err = ioutil.TempFile("/tmp", "config-*.yaml")
+	assert.Nil(t, err, "failed to create temporary yaml configuration file: ", err)
+	defer os.Remove(tmpYAML.Name())
+
+	_, err = tmpYAML.Write([]byte(yamlData))
+	assert.Nil(t, err, "failed to write yaml data: ", err)
+	tmpYAML.Close()
+
+	newCfg, err = NewConfigFromFile(tmpYAML.Name())
+	assert.Nil(t, err, "failed to new config from file: ", err)
+	err = newCfg.Validate()
+	assert.Equal(t, err.Error(), "apisix admin key secret ref requires namespace, name and key", "bad error: ", err)
+
+	newCfg.APISIX.DefaultClusterAdminKeySecretRef.Key = "key"
+	newCfg.APISIX.DefaultClusterAdminKey = "123456"
+	err = newCfg.Validate()
+	assert.Equal(t, err.Error(), "apisix admin key and admin key secret ref are mutually exclusive", "bad error: ", err)
+
+	newCfg.APISIX.DefaultClusterAdminKey = ""
+	assert.Nil(t, newCfg.Validate())
+
+	newCfg.Kubernetes.NamespaceSelector = "tenant in (foo"
+	err = newCfg.Validate()
+	assert.Contains(t, err.Error(), "invalid namespace selector", "bad error: ", err)
+	newCfg.Kubernetes.NamespaceSelector = "tenant in (foo, bar)"
+	assert.Nil(t, newCfg.Validate())
+}
+
+func TestConfigDiffAndHash(t *testing.T) {
+	a := NewDefaultConfig()
+	b := NewDefaultConfig()
+	assert.Len(t, a.Diff(b), 0)
+	assert.Equal(t, a.Hash(), b.Hash())
+
+	b.LogLevel = "debug"
+	b.Kubernetes.AppNamespaces = []string{"foo"}
+	b.APISIX.DefaultClusterAdminKeySecretRef.Name = "admin-key"
+	assert.Equal(t, []string{
+		"log_level",
+		"kubernetes.app_namespaces",
+		"apisix.default_cluster_admin_key_secret_ref",
+	}, a.Diff(b))
+	assert.NotEqual(t, a.Hash(), b.Hash())
+}
+
+func TestConfigHashSecrets(t *testing.T) {
+	a := NewDefaultConfig()
+	a.APISIX.DefaultClusterAdminKey = "123456"
+	b := NewDefaultConfig()
+	b.APISIX.DefaultClusterAdminKey = "654321"
+
+	// The admin key is not hashed in plain.
+	data, err := json.Marshal(a)
+	assert.Nil(t, err)
+	sum := sha256.Sum256(data)
+	assert.NotEqual(t, hex.EncodeToString(sum[:]), a.Hash())
+
+	// The hash is stable across processes.
+	assert.Equal(t, a.Hash(), b.Hash())
+
+	b.APISIX.DefaultClusterAdminKey = ""
+	b.APISIX.DefaultClusterAdminKeySecretRef = SecretKeyRef{Namespace: "apisix", Name: "admin-key", Key: "key"}
+	assert.NotEqual(t, a.Hash(), b.Hash())
+}
+
+func TestConfigRedacted(t *testing.T) {
+	cfg := NewDefaultConfig()
+	cfg.APISIX.DefaultClusterAdminKey = "123456"
+	redacted := cfg.Redacted()
+	assert.Equal(t, "******", redacted.APISIX.DefaultClusterAdminKey)
+	assert.Equal(t, "", redacted.APISIX.AdminKey)
+	assert.Equal(t, "123456", cfg.APISIX.DefaultClusterAdminKey)
 }
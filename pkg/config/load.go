@@ -0,0 +1,190 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/apache/apisix-ingress-controller/pkg/types"
+)
+
+// EnvPrefix is the prefix of environment variables which set config items.
+const EnvPrefix = "APISIX_INGRESS_"
+
+// Keys returns the keys of all config items, a key is the YAML name of the
+// item, prefixed by names of its sections and joined by dots, e.g.
+// "kubernetes.app_namespaces".
+func Keys() []string {
+	return fieldKeys("", reflect.TypeOf(Config{}))
+}
+
+func fieldKeys(prefix string, typ reflect.Type) []string {
+	var keys []string
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		key := prefix + fieldName(field)
+		if isSection(field.Type) {
+			keys = append(keys, fieldKeys(key+".", field.Type)...)
+			continue
+		}
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// EnvName returns the name of the environment variable which sets the
+// config item, e.g. "APISIX_INGRESS_KUBERNETES_APP_NAMESPACES" for the key
+// "kubernetes.app_namespaces".
+func EnvName(key string) string {
+	return EnvPrefix + strings.ToUpper(strings.Replace(key, ".", "_", -1))
+}
+
+// LoadEnv sets config items from the environment variables, which are looked
+// up by lookupEnv (e.g. os.LookupEnv). Items of lists are separated by commas.
+func (cfg *Config) LoadEnv(lookupEnv func(string) (string, bool)) error {
+	for _, key := range Keys() {
+		value, ok := lookupEnv(EnvName(key))
+		if !ok {
+			continue
+		}
+		if err := cfg.Set(key, value); err != nil {
+			return fmt.Errorf("bad environment variable %s: %s", EnvName(key), err)
+		}
+	}
+	return nil
+}
+
+// Set parses the value and sets it to the config item.
+func (cfg *Config) Set(key, value string) error {
+	field, err := cfg.field(key)
+	if err != nil {
+		return err
+	}
+	switch field.Interface().(type) {
+	case string:
+		field.SetString(value)
+	case bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	case int:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(n))
+	case []string:
+		var items []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		field.Set(reflect.ValueOf(items))
+	case types.TimeDuration:
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		field.Set(reflect.ValueOf(types.TimeDuration{Duration: d}))
+	default:
+		return fmt.Errorf("unsupported type %s of config item %s", field.Type(), key)
+	}
+	return nil
+}
+
+// Merge copies the config items named by keys from the other Config.
+func (cfg *Config) Merge(other *Config, keys ...string) error {
+	for _, key := range keys {
+		dst, err := cfg.field(key)
+		if err != nil {
+			return err
+		}
+		src, err := other.field(key)
+		if err != nil {
+			return err
+		}
+		dst.Set(src)
+	}
+	return nil
+}
+
+func (cfg *Config) field(key string) (reflect.Value, error) {
+	v := reflect.ValueOf(cfg).Elem()
+	parts := strings.Split(key, ".")
+	for i, part := range parts {
+		found := false
+		for j := 0; j < v.NumField(); j++ {
+			field := v.Type().Field(j)
+			if fieldName(field) != part {
+				continue
+			}
+			if i < len(parts)-1 && !isSection(field.Type) {
+				break
+			}
+			v = v.Field(j)
+			found = true
+			break
+		}
+		if !found {
+			return reflect.Value{}, fmt.Errorf("unknown config item %s", key)
+		}
+	}
+	if isSection(v.Type()) {
+		return reflect.Value{}, fmt.Errorf("unknown config item %s", key)
+	}
+	return v, nil
+}
+
+// Load creates a Config in layers: the default values, the configuration
+// file (if filename isn't empty), the environment variables looked up by
+// lookupEnv, and the config items in overrides (e.g. set by command line
+// options) which are named by keys. Later layers take precedence.
+func Load(filename string, lookupEnv func(string) (string, bool), overrides *Config, keys []string) (*Config, error) {
+	cfg := NewDefaultConfig()
+	if filename != "" {
+		c, err := NewConfigFromFile(filename)
+		if err != nil {
+			return nil, err
+		}
+		cfg = c
+	}
+	if err := cfg.LoadEnv(lookupEnv); err != nil {
+		return nil, err
+	}
+	if err := cfg.Merge(overrides, keys...); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+func fieldName(field reflect.StructField) string {
+	return strings.Split(field.Tag.Get("yaml"), ",")[0]
+}
+
+// isSection returns true if config items are grouped by the type.
+func isSection(typ reflect.Type) bool {
+	switch typ {
+	case reflect.TypeOf(KubernetesConfig{}), reflect.TypeOf(APISIXConfig{}), reflect.TypeOf(SecretKeyRef{}):
+		return true
+	}
+	return false
+}
@@ -0,0 +1,110 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeys(t *testing.T) {
+	keys := Keys()
+	assert.Contains(t, keys, "log_level")
+	assert.Contains(t, keys, "kubernetes.app_namespaces")
+	assert.Contains(t, keys, "apisix.default_cluster_name")
+	assert.Contains(t, keys, "apisix.default_cluster_admin_key_secret_ref.name")
+	assert.NotContains(t, keys, "apisix.default_cluster_admin_key_secret_ref")
+
+	cfg := NewDefaultConfig()
+	for _, key := range keys {
+		_, err := cfg.field(key)
+		assert.Nil(t, err, "config item %s", key)
+	}
+	assert.Equal(t, "APISIX_INGRESS_KUBERNETES_APP_NAMESPACES", EnvName("kubernetes.app_namespaces"))
+}
+
+func TestLoadEnv(t *testing.T) {
+	env := map[string]string{
+		"APISIX_INGRESS_LOG_LEVEL":                                       "debug",
+		"APISIX_INGRESS_ENABLE_PROFILING":                                "false",
+		"APISIX_INGRESS_KUBERNETES_RESYNC_INTERVAL":                      "1h",
+		"APISIX_INGRESS_KUBERNETES_APP_NAMESPACES":                       "foo, bar",
+		"APISIX_INGRESS_APISIX_DEFAULT_CLUSTER_NAME":                     "cluster",
+		"APISIX_INGRESS_APISIX_DEFAULT_CLUSTER_ADMIN_KEY_SECRET_REF_KEY": "key",
+		"APISIX_INGRESS_APISIX_GC_MAX_DELETIONS":                         "10",
+	}
+	lookupEnv := func(name string) (string, bool) {
+		value, ok := env[name]
+		return value, ok
+	}
+	cfg := NewDefaultConfig()
+	assert.Nil(t, cfg.LoadEnv(lookupEnv))
+	assert.Equal(t, "debug", cfg.LogLevel)
+	assert.False(t, cfg.EnableProfiling)
+	assert.Equal(t, time.Hour, cfg.Kubernetes.ResyncInterval.Duration)
+	assert.Equal(t, []string{"foo", "bar"}, cfg.Kubernetes.AppNamespaces)
+	assert.Equal(t, "cluster", cfg.APISIX.DefaultClusterName)
+	assert.Equal(t, "key", cfg.APISIX.DefaultClusterAdminKeySecretRef.Key)
+	assert.Equal(t, 10, cfg.APISIX.GCMaxDeletions)
+
+	env["APISIX_INGRESS_APISIX_GC_DRY_RUN"] = "maybe"
+	err := NewDefaultConfig().LoadEnv(lookupEnv)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "APISIX_INGRESS_APISIX_GC_DRY_RUN")
+
+	assert.NotNil(t, cfg.Set("kubernetes", "foo"))
+	assert.NotNil(t, cfg.Set("log_level.foo", "foo"))
+	assert.NotNil(t, cfg.Set("unknown", "foo"))
+}
+
+func TestLoad(t *testing.T) {
+	tmp, err := ioutil.TempFile("/tmp", "config-*.yaml")
+	assert.Nil(t, err)
+	defer os.Remove(tmp.Name())
+	_, err = tmp.Write([]byte(`
+log_level: info
+http_listen: :9090
+apisix:
+  default_cluster_name: file
+  default_cluster_base_url: http://127.0.0.1:9080/apisix/admin
+`))
+	assert.Nil(t, err)
+	tmp.Close()
+
+	lookupEnv := func(name string) (string, bool) {
+		switch name {
+		case "APISIX_INGRESS_LOG_LEVEL":
+			return "error", true
+		case "APISIX_INGRESS_HTTP_LISTEN":
+			return ":9091", true
+		}
+		return "", false
+	}
+	overrides := NewDefaultConfig()
+	overrides.LogLevel = "debug"
+	overrides.HTTPListen = ":9092"
+
+	cfg, err := Load(tmp.Name(), lookupEnv, overrides, []string{"log_level"})
+	assert.Nil(t, err)
+	assert.Equal(t, "debug", cfg.LogLevel)
+	assert.Equal(t, ":9091", cfg.HTTPListen)
+	assert.Equal(t, "file", cfg.APISIX.DefaultClusterName)
+	assert.Equal(t, "http://127.0.0.1:9080/apisix/admin", cfg.APISIX.DefaultClusterBaseURL)
+	assert.Equal(t, IngressAPISIXLeader, cfg.Kubernetes.ElectionID)
+}
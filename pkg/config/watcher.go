@@ -0,0 +1,63 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package config
+
+import (
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/apache/apisix-ingress-controller/pkg/log"
+)
+
+// WatchFile polls the configuration file every interval until the stopCh is
+// closed, the Config is loaded by load (which reads the file) and the handler
+// is called with it once config items are changed (compared with the current
+// one). The file is re-read rather than watched by inotify, as files in a
+// mounted ConfigMap are updated by swapping symbolic links. A Config which
+// can't be loaded or is invalid is ignored, so the one in use is kept.
+func WatchFile(filename string, load func() (*Config, error), current *Config, interval time.Duration, stopCh <-chan struct{}, handler func(*Config)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stopCh:
+			return
+		case <-ticker.C:
+		}
+		cfg, err := load()
+		if err == nil {
+			err = cfg.Validate()
+		}
+		if err != nil {
+			log.Errorw("failed to reload configuration, keep using the current one",
+				zap.String("file", filename),
+				zap.Error(err),
+			)
+			continue
+		}
+		// The hash doesn't reflect changes of the admin keys, so the items
+		// are compared.
+		if changed := current.Diff(cfg); len(changed) > 0 {
+			log.Infow("configuration file changed, reloading",
+				zap.String("file", filename),
+				zap.Strings("items", changed),
+				zap.String("hash", cfg.Hash()),
+			)
+			current = cfg
+			handler(cfg)
+		}
+	}
+}
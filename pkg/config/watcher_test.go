@@ -0,0 +1,91 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWatchFile(t *testing.T) {
+	tmp, err := ioutil.TempFile("/tmp", "config-*.yaml")
+	assert.Nil(t, err)
+	defer os.Remove(tmp.Name())
+
+	write := func(data string) {
+		assert.Nil(t, ioutil.WriteFile(tmp.Name(), []byte(data), 0644))
+	}
+	write(`
+log_level: info
+apisix:
+  default_cluster_base_url: http://127.0.0.1:9080/apisix/admin
+`)
+	current, err := NewConfigFromFile(tmp.Name())
+	assert.Nil(t, err)
+	assert.Nil(t, current.Validate())
+
+	reloaded := make(chan *Config, 1)
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	load := func() (*Config, error) {
+		return NewConfigFromFile(tmp.Name())
+	}
+	go WatchFile(tmp.Name(), load, current, 10*time.Millisecond, stopCh, func(cfg *Config) {
+		reloaded <- cfg
+	})
+
+	// Invalid configurations are ignored.
+	write(`
+log_level: debug
+apisix:
+  default_cluster_base_url: ""
+`)
+	select {
+	case cfg := <-reloaded:
+		t.Fatalf("unexpected reload with log level %s", cfg.LogLevel)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	write(`
+log_level: debug
+apisix:
+  default_cluster_base_url: http://127.0.0.1:9080/apisix/admin
+`)
+	select {
+	case cfg := <-reloaded:
+		assert.Equal(t, "debug", cfg.LogLevel)
+		assert.Equal(t, []string{"log_level"}, current.Diff(cfg))
+	case <-time.After(time.Second):
+		t.Fatal("configuration was not reloaded")
+	}
+
+	// Rotations of the admin key are not reflected by the hash.
+	write(`
+log_level: debug
+apisix:
+  default_cluster_base_url: http://127.0.0.1:9080/apisix/admin
+  default_cluster_admin_key: "123456"
+`)
+	select {
+	case cfg := <-reloaded:
+		assert.Equal(t, "123456", cfg.APISIX.DefaultClusterAdminKey)
+	case <-time.After(time.Second):
+		t.Fatal("configuration was not reloaded")
+	}
+}
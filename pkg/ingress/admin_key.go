@@ -0,0 +1,124 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package ingress
+
+import (
+	"context"
+	"fmt"
+
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/apache/apisix-ingress-controller/pkg/apisix"
+	"github.com/apache/apisix-ingress-controller/pkg/config"
+	"github.com/apache/apisix-ingress-controller/pkg/log"
+)
+
+// adminKeySource records the Secret which stores the admin key of an
+// APISIX cluster, and the options to update the cluster once the admin
+// key is rotated.
+type adminKeySource struct {
+	ref  config.SecretKeyRef
+	opts apisix.ClusterOptions
+}
+
+// loadAdminKey reads the admin key from the referenced Secret. The Secret
+// is fetched from the API server as the admin key of the default cluster
+// is required before informers are started.
+func (c *Controller) loadAdminKey(ctx context.Context, ref *config.SecretKeyRef) (string, error) {
+	sec, err := c.kubeClient.Client.CoreV1().Secrets(ref.Namespace).Get(ctx, ref.Name, metav1.GetOptions{})
+	if err != nil {
+		return "", err
+	}
+	return adminKeyFromSecret(sec, ref)
+}
+
+func adminKeyFromSecret(sec *corev1.Secret, ref *config.SecretKeyRef) (string, error) {
+	key, ok := sec.Data[ref.Key]
+	if !ok || len(key) == 0 {
+		return "", fmt.Errorf("key %s not found in secret %s/%s", ref.Key, ref.Namespace, ref.Name)
+	}
+	return string(key), nil
+}
+
+// watchAdminKey records the admin key Secret of the cluster, so that the
+// cluster will be updated once the Secret is changed. A nil ref stops
+// watching.
+func (c *Controller) watchAdminKey(ref *config.SecretKeyRef, opts *apisix.ClusterOptions) {
+	if ref == nil {
+		c.adminKeySources.Delete(opts.Name)
+		return
+	}
+	c.adminKeySources.Store(opts.Name, &adminKeySource{
+		ref:  *ref,
+		opts: *opts,
+	})
+}
+
+// isAdminKeySecret returns true if the Secret stores admin key of clusters.
+func (c *Controller) isAdminKeySecret(namespace, name string) bool {
+	found := false
+	c.adminKeySources.Range(func(_, v interface{}) bool {
+		src := v.(*adminKeySource)
+		found = src.ref.Namespace == namespace && src.ref.Name == name
+		return !found
+	})
+	return found
+}
+
+// rotateAdminKey updates clusters whose admin key is stored in the Secret.
+// Clusters are updated in place, so their caches are kept.
+func (c *Controller) rotateAdminKey(sec *corev1.Secret) error {
+	var lastErr error
+	c.adminKeySources.Range(func(k, v interface{}) bool {
+		src := v.(*adminKeySource)
+		if src.ref.Namespace != sec.Namespace || src.ref.Name != sec.Name {
+			return true
+		}
+		adminKey, err := adminKeyFromSecret(sec, &src.ref)
+		if err != nil {
+			log.Errorw("failed to load admin key",
+				zap.String("cluster", src.opts.Name),
+				zap.Error(err),
+			)
+			lastErr = err
+			return true
+		}
+		if adminKey == src.opts.AdminKey {
+			return true
+		}
+		opts := src.opts
+		opts.AdminKey = adminKey
+		if err := c.apisix.UpdateCluster(&opts); err != nil {
+			log.Errorw("failed to update admin key of cluster",
+				zap.String("cluster", src.opts.Name),
+				zap.Error(err),
+			)
+			lastErr = err
+			return true
+		}
+		log.Infow("admin key of cluster rotated",
+			zap.String("cluster", src.opts.Name),
+			zap.String("secret", sec.Namespace+"/"+sec.Name),
+		)
+		c.adminKeySources.Store(k, &adminKeySource{
+			ref:  src.ref,
+			opts: opts,
+		})
+		return true
+	})
+	return lastErr
+}
@@ -0,0 +1,127 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package ingress
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/apache/apisix-ingress-controller/pkg/apisix"
+	"github.com/apache/apisix-ingress-controller/pkg/config"
+	"github.com/apache/apisix-ingress-controller/pkg/kube"
+	apisixv1 "github.com/apache/apisix-ingress-controller/pkg/types/apisix/v1"
+)
+
+type fakeAPISIX struct {
+	apisix.APISIX
+	updated  []apisix.ClusterOptions
+	deleted  []string
+	clusters map[string]*fakeCluster
+}
+
+func (f *fakeAPISIX) Cluster(name string) apisix.Cluster {
+	if cluster, ok := f.clusters[name]; ok {
+		return cluster
+	}
+	return nonExistentCluster{}
+}
+
+// nonExistentCluster is returned for clusters which are not registered.
+type nonExistentCluster struct {
+	apisix.Cluster
+}
+
+func (nonExistentCluster) GlobalRule() apisix.GlobalRule {
+	return nonExistentGlobalRule{}
+}
+
+type nonExistentGlobalRule struct {
+	apisix.GlobalRule
+}
+
+func (nonExistentGlobalRule) Delete(context.Context, *apisixv1.GlobalRule) error {
+	return apisix.ErrClusterNotExist
+}
+
+func (f *fakeAPISIX) UpdateCluster(opts *apisix.ClusterOptions) error {
+	f.updated = append(f.updated, *opts)
+	return nil
+}
+
+func TestRotateAdminKey(t *testing.T) {
+	sec := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "admin-key",
+			Namespace: "apisix",
+		},
+		Data: map[string][]byte{
+			"key": []byte("123"),
+		},
+	}
+	cli := &fakeAPISIX{}
+	c := &Controller{
+		apisix: cli,
+		kubeClient: &kube.KubeClient{
+			Client: fake.NewSimpleClientset(sec),
+		},
+		adminKeySources: new(sync.Map),
+	}
+	ref := &config.SecretKeyRef{
+		Namespace: "apisix",
+		Name:      "admin-key",
+		Key:       "key",
+	}
+
+	adminKey, err := c.loadAdminKey(context.Background(), ref)
+	assert.Nil(t, err)
+	assert.Equal(t, "123", adminKey)
+
+	opts := &apisix.ClusterOptions{
+		Name:     "default",
+		BaseURL:  "http://apisix-admin:9180/apisix/admin",
+		AdminKey: adminKey,
+	}
+	assert.False(t, c.isAdminKeySecret("apisix", "admin-key"))
+	c.watchAdminKey(ref, opts)
+	assert.True(t, c.isAdminKeySecret("apisix", "admin-key"))
+	assert.False(t, c.isAdminKeySecret("default", "admin-key"))
+
+	// The admin key is not changed.
+	assert.Nil(t, c.rotateAdminKey(sec))
+	assert.Len(t, cli.updated, 0)
+
+	sec.Data["key"] = []byte("456")
+	assert.Nil(t, c.rotateAdminKey(sec))
+	assert.Len(t, cli.updated, 1)
+	assert.Equal(t, "456", cli.updated[0].AdminKey)
+	assert.Equal(t, opts.BaseURL, cli.updated[0].BaseURL)
+
+	// Already rotated.
+	assert.Nil(t, c.rotateAdminKey(sec))
+	assert.Len(t, cli.updated, 1)
+
+	delete(sec.Data, "key")
+	assert.NotNil(t, c.rotateAdminKey(sec))
+	assert.Len(t, cli.updated, 1)
+
+	c.watchAdminKey(nil, opts)
+	assert.False(t, c.isAdminKeySecret("apisix", "admin-key"))
+}
@@ -16,25 +16,35 @@ package ingress
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"go.uber.org/zap"
 	corev1 "k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/util/workqueue"
 
 	"github.com/apache/apisix-ingress-controller/pkg/apisix"
+	apisixcache "github.com/apache/apisix-ingress-controller/pkg/apisix/cache"
+	"github.com/apache/apisix-ingress-controller/pkg/config"
+	"github.com/apache/apisix-ingress-controller/pkg/id"
 	configv2alpha1 "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/apis/config/v2alpha1"
 	"github.com/apache/apisix-ingress-controller/pkg/log"
+	"github.com/apache/apisix-ingress-controller/pkg/metrics"
 	"github.com/apache/apisix-ingress-controller/pkg/types"
+	apisixv1 "github.com/apache/apisix-ingress-controller/pkg/types/apisix/v1"
 )
 
 type apisixClusterConfigController struct {
 	controller *Controller
 	workqueue  workqueue.RateLimitingInterface
 	workers    int
+	// cluster name -> labels of the ApisixClusterConfig, used to find
+	// out whether cluster selections should be re-evaluated.
+	clusterLabels map[string]map[string]string
 }
 
 func (c *Controller) newApisixClusterConfigController() *apisixClusterConfigController {
@@ -42,6 +52,8 @@ func (c *Controller) newApisixClusterConfigController() *apisixClusterConfigCont
 		controller: c,
 		workqueue:  workqueue.NewNamedRateLimitingQueue(workqueue.NewItemFastSlowRateLimiter(time.Second, 60*time.Second, 5), "ApisixClusterConfig"),
 		workers:    1,
+
+		clusterLabels: make(map[string]map[string]string),
 	}
 	c.apisixClusterConfigInformer.AddEventHandler(
 		cache.ResourceEventHandlerFuncs{
@@ -109,43 +121,128 @@ func (c *apisixClusterConfigController) sync(ctx context.Context, ev *types.Even
 		acc = ev.Tombstone.(*configv2alpha1.ApisixClusterConfig)
 	}
 
-	// Currently we don't handle multiple cluster, so only process
-	// the default apisix cluster.
-	if acc.Name != c.controller.cfg.APISIX.DefaultClusterName {
-		log.Infow("ignore non-default apisix cluster config",
-			zap.String("default_cluster_name", c.controller.cfg.APISIX.DefaultClusterName),
-			zap.Any("ApisixClusterConfig", acc),
-		)
-		return nil
+	var deps []string
+	if ev.Type != types.EventDelete {
+		deps = apisixClusterConfigDependencies(acc)
+	}
+	c.controller.dependencyIndex.set(sourceKey(_dependentKindApisixClusterConfig, key), deps)
+
+	isDefault := acc.Name == c.controller.cfg.APISIX.DefaultClusterName
+	if ev.Type != types.EventDelete {
+		if c.controller.isFinalizing(acc) {
+			if isForceDeleting(acc) {
+				return c.controller.forceRemoveFinalizer(ctx, acc)
+			}
+			// No cluster is registered by the object.
+			if !isDefault && acc.Spec.Admin == nil {
+				return c.controller.removeFinalizer(ctx, acc)
+			}
+			// Only the global rule is deleted, the cluster is removed
+			// once the object is gone.
+			gr := &apisixv1.GlobalRule{ID: id.GenID(acc.Name)}
+			err := c.controller.apisix.Cluster(acc.Name).GlobalRule().Delete(ctx, gr)
+			// The cluster might be never registered, e.g. its Secrets are
+			// missing, so there is nothing to delete.
+			if err != nil && err != apisix.ErrClusterNotExist && err != apisixcache.ErrNotFound {
+				log.Errorw("failed to delete global_rule from apisix cluster",
+					zap.String("cluster", acc.Name),
+					zap.Error(err),
+				)
+				c.controller.recorderEvent(acc, corev1.EventTypeWarning, _resourceSyncAborted, err)
+				return err
+			}
+			return c.controller.removeFinalizer(ctx, acc)
+		}
+		if err := c.controller.ensureFinalizer(ctx, acc); err != nil {
+			return err
+		}
 	}
-	// Cluster delete is dangerous.
-	// TODO handle delete?
 	if ev.Type == types.EventDelete {
-		log.Error("ApisixClusterConfig delete event for default apisix cluster will be ignored")
+		// The default cluster is required by objects without cluster
+		// selectors, so it's never deleted.
+		if isDefault {
+			log.Error("ApisixClusterConfig delete event for default apisix cluster will be ignored")
+			return nil
+		}
+		c.removeCluster(acc.Name)
+		return nil
+	}
+	if !isDefault && acc.Spec.Admin == nil {
+		log.Warnw("ApisixClusterConfig without admin config doesn't register a cluster",
+			zap.String("cluster_name", acc.Name),
+		)
+		c.removeCluster(acc.Name)
 		return nil
 	}
 
 	if acc.Spec.Admin != nil {
 		clusterOpts := &apisix.ClusterOptions{
-			Name:     acc.Name,
-			BaseURL:  acc.Spec.Admin.BaseURL,
-			AdminKey: acc.Spec.Admin.AdminKey,
+			Name:             acc.Name,
+			BaseURL:          acc.Spec.Admin.BaseURL,
+			AdminKey:         acc.Spec.Admin.AdminKey,
+			MetricsCollector: c.controller.metricsCollector,
+		}
+		var adminKeyRef *config.SecretKeyRef
+		if ref := acc.Spec.Admin.AdminKeySecretRef; ref != nil {
+			adminKeyRef = &config.SecretKeyRef{
+				Namespace: ref.Namespace,
+				Name:      ref.Name,
+				Key:       ref.Key,
+			}
+			adminKey, err := c.controller.loadAdminKey(ctx, adminKeyRef)
+			if err != nil {
+				log.Errorw("failed to load admin key",
+					zap.String("cluster_name", acc.Name),
+					zap.Error(err),
+				)
+				c.controller.recorderEvent(acc, corev1.EventTypeWarning, _resourceSyncAborted, err)
+				c.controller.recordStatus(acc, _resourceSyncAborted, err, metav1.ConditionFalse)
+				return err
+			}
+			clusterOpts.AdminKey = adminKey
+		}
+		if err := c.loadAdminTLSOptions(acc.Spec.Admin.TLS, clusterOpts); err != nil {
+			log.Errorw("failed to load admin tls options",
+				zap.String("cluster_name", acc.Name),
+				zap.Error(err),
+			)
+			c.controller.recorderEvent(acc, corev1.EventTypeWarning, _resourceSyncAborted, err)
+			c.controller.recordStatus(acc, _resourceSyncAborted, err, metav1.ConditionFalse)
+			return err
 		}
 		log.Infow("updating cluster",
-			zap.Any("opts", clusterOpts),
+			zap.String("cluster_name", acc.Name),
+			zap.String("base_url", clusterOpts.BaseURL),
 		)
-		// TODO we may first call AddCluster.
-		// Since now we already have the default cluster, we just call UpdateCluster.
-		if err := c.controller.apisix.UpdateCluster(clusterOpts); err != nil {
+		err := c.controller.apisix.AddCluster(clusterOpts)
+		if err == apisix.ErrDuplicatedCluster {
+			err = c.controller.apisix.UpdateCluster(clusterOpts)
+		}
+		if err != nil {
 			log.Errorw("failed to update cluster",
 				zap.String("cluster_name", acc.Name),
 				zap.Error(err),
-				zap.Any("opts", clusterOpts),
+				zap.String("base_url", clusterOpts.BaseURL),
 			)
 			c.controller.recorderEvent(acc, corev1.EventTypeWarning, _resourceSyncAborted, err)
 			c.controller.recordStatus(acc, _resourceSyncAborted, err, metav1.ConditionFalse)
 			return err
 		}
+		c.controller.watchAdminKey(adminKeyRef, clusterOpts)
+	}
+	if !isDefault {
+		if err := c.controller.apisix.Cluster(acc.Name).HasSynced(ctx); err != nil {
+			log.Errorw("failed to wait the cluster to be synced",
+				zap.String("cluster_name", acc.Name),
+				zap.Error(err),
+			)
+			// So that the cluster will be added again when retrying.
+			c.controller.forgetCluster(acc.Name)
+			c.controller.recorderEvent(acc, corev1.EventTypeWarning, _resourceSyncAborted, err)
+			c.controller.recordStatus(acc, _resourceSyncAborted, err, metav1.ConditionFalse)
+			return err
+		}
+		c.updateClusterLabels(acc.Name, acc.Labels)
 	}
 
 	globalRule, err := c.controller.translator.TranslateClusterConfig(acc)
@@ -156,6 +253,7 @@ func (c *apisixClusterConfigController) sync(ctx context.Context, ev *types.Even
 			zap.String("key", key),
 			zap.Any("object", acc),
 		)
+		c.controller.metricsCollector.IncrSyncOperation("ApisixClusterConfig", metrics.SyncResultTranslationFailure)
 		c.controller.recorderEvent(acc, corev1.EventTypeWarning, _resourceSyncAborted, err)
 		c.controller.recordStatus(acc, _resourceSyncAborted, err, metav1.ConditionFalse)
 		return err
@@ -164,7 +262,6 @@ func (c *apisixClusterConfigController) sync(ctx context.Context, ev *types.Even
 		zap.Any("object", globalRule),
 	)
 
-	// TODO multiple cluster support
 	if ev.Type == types.EventAdd {
 		_, err = c.controller.apisix.Cluster(acc.Name).GlobalRule().Create(ctx, globalRule)
 	} else {
@@ -175,6 +272,7 @@ func (c *apisixClusterConfigController) sync(ctx context.Context, ev *types.Even
 			zap.Any("global_rule", globalRule),
 			zap.Any("cluster", acc.Name),
 		)
+		c.controller.metricsCollector.IncrSyncOperation("ApisixClusterConfig", metrics.SyncResultPushFailure)
 		c.controller.recorderEvent(acc, corev1.EventTypeWarning, _resourceSyncAborted, err)
 		c.controller.recordStatus(acc, _resourceSyncAborted, err, metav1.ConditionFalse)
 		return err
@@ -184,9 +282,34 @@ func (c *apisixClusterConfigController) sync(ctx context.Context, ev *types.Even
 	return nil
 }
 
+// removeCluster removes the non-default cluster registered by the
+// ApisixClusterConfig, resources in APISIX are kept.
+func (c *apisixClusterConfigController) removeCluster(name string) {
+	_, known := c.clusterLabels[name]
+	delete(c.clusterLabels, name)
+	c.controller.forgetCluster(name)
+	if known {
+		log.Infow("cluster removed",
+			zap.String("cluster_name", name),
+		)
+		c.controller.resyncClusterSelectors()
+	}
+}
+
+// updateClusterLabels records labels of the cluster, objects with cluster
+// selectors are re-synced if the cluster is new or its labels changed.
+func (c *apisixClusterConfigController) updateClusterLabels(name string, lbls map[string]string) {
+	if old, ok := c.clusterLabels[name]; ok && labels.Equals(old, lbls) {
+		return
+	}
+	c.clusterLabels[name] = lbls
+	c.controller.resyncClusterSelectors()
+}
+
 func (c *apisixClusterConfigController) handleSyncErr(obj interface{}, err error) {
 	if err == nil {
 		c.workqueue.Forget(obj)
+		c.controller.metricsCollector.IncrSyncOperation("ApisixClusterConfig", metrics.SyncResultSuccess)
 		return
 	}
 	log.Warnw("sync ApisixClusterConfig failed, will retry",
@@ -259,3 +382,41 @@ func (c *apisixClusterConfigController) onDelete(obj interface{}) {
 		Tombstone: acc,
 	})
 }
+
+// loadAdminTLSOptions fills the TLS options of the Admin API client, the
+// certificates are read from the referenced Secrets.
+func (c *apisixClusterConfigController) loadAdminTLSOptions(cfg *configv2alpha1.ApisixClusterAdminTLSConfig, opts *apisix.ClusterOptions) error {
+	if cfg == nil {
+		return nil
+	}
+	opts.ServerName = cfg.ServerName
+	opts.InsecureSkipVerify = cfg.InsecureSkipVerify
+	if cfg.CASecretRef != nil {
+		secret, err := c.controller.secretLister.Secrets(cfg.CASecretRef.Namespace).Get(cfg.CASecretRef.Name)
+		if err != nil {
+			return err
+		}
+		ca, ok := secret.Data["ca.crt"]
+		if !ok {
+			return fmt.Errorf("key ca.crt not found in secret %s/%s", secret.Namespace, secret.Name)
+		}
+		opts.CACert = ca
+	}
+	if cfg.ClientCertSecretRef != nil {
+		secret, err := c.controller.secretLister.Secrets(cfg.ClientCertSecretRef.Namespace).Get(cfg.ClientCertSecretRef.Name)
+		if err != nil {
+			return err
+		}
+		cert, ok := secret.Data[corev1.TLSCertKey]
+		if !ok {
+			return fmt.Errorf("key %s not found in secret %s/%s", corev1.TLSCertKey, secret.Namespace, secret.Name)
+		}
+		key, ok := secret.Data[corev1.TLSPrivateKeyKey]
+		if !ok {
+			return fmt.Errorf("key %s not found in secret %s/%s", corev1.TLSPrivateKeyKey, secret.Namespace, secret.Name)
+		}
+		opts.ClientCert = cert
+		opts.ClientKey = key
+	}
+	return nil
+}
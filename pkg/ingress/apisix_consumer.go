@@ -26,8 +26,11 @@ import (
 	"k8s.io/client-go/util/workqueue"
 
 	configv2alpha1 "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/apis/config/v2alpha1"
+	"github.com/apache/apisix-ingress-controller/pkg/kube/translation"
 	"github.com/apache/apisix-ingress-controller/pkg/log"
+	"github.com/apache/apisix-ingress-controller/pkg/metrics"
 	"github.com/apache/apisix-ingress-controller/pkg/types"
+	apisixv1 "github.com/apache/apisix-ingress-controller/pkg/types/apisix/v1"
 )
 
 type apisixConsumerController struct {
@@ -98,49 +101,110 @@ func (c *apisixConsumerController) sync(ctx context.Context, ev *types.Event) er
 			return nil
 		}
 	}
+	// released is the ApisixConsumer which is not handled by us anymore, its
+	// resources are deleted and the finalizer is removed.
+	var released *configv2alpha1.ApisixConsumer
 	if ev.Type == types.EventDelete {
 		if ac != nil {
-			// We still find the resource while we are processing the DELETE event,
-			// that means object with same namespace and name was created, discarding
-			// this stale DELETE event.
-			log.Warnf("discard the stale ApisixConsumer delete event since the %s exists", key)
-			return nil
+			if !c.controller.namespaceWatching(key) || !c.controller.isApisixObjectEffective(ac) {
+				released = ac
+			} else {
+				// We still find the resource while we are processing the DELETE event,
+				// that means object with same namespace and name was created, discarding
+				// this stale DELETE event.
+				log.Warnf("discard the stale ApisixConsumer delete event since the %s exists", key)
+				return nil
+			}
 		}
 		ac = ev.Tombstone.(*configv2alpha1.ApisixConsumer)
+	} else if !c.controller.namespaceWatching(key) || !c.controller.isApisixObjectEffective(ac) {
+		return nil
+	}
+	finalizing := false
+	if ev.Type != types.EventDelete {
+		if c.controller.isFinalizing(ac) {
+			if isForceDeleting(ac) {
+				return c.controller.forceRemoveFinalizer(ctx, ac)
+			}
+			// The ApisixConsumer is being deleted, handle it as a DELETE event.
+			finalizing = true
+			ev = &types.Event{
+				Type:      types.EventDelete,
+				Object:    ev.Object,
+				Tombstone: ac,
+			}
+		} else if err := c.controller.ensureFinalizer(ctx, ac); err != nil {
+			return err
+		}
+	}
+
+	dependent := sourceKey(translation.SourceKindApisixConsumer, key)
+	if ev.Type == types.EventDelete {
+		c.controller.dependencyIndex.set(dependent, nil)
+	} else {
+		c.controller.dependencyIndex.set(dependent, apisixConsumerDependencies(ac))
 	}
 
 	consumer, err := c.controller.translator.TranslateApisixConsumer(ac)
+	if err != nil && ev.Type == types.EventDelete {
+		// The Secrets might be deleted, but only the username is
+		// necessary for deleting the consumer.
+		consumer = apisixv1.NewDefaultConsumer()
+		consumer.Username = apisixv1.ComposeConsumerName(ac.Namespace, ac.Name)
+		err = nil
+	}
 	if err != nil {
 		log.Errorw("failed to translate ApisixConsumer",
 			zap.Error(err),
 			zap.Any("ApisixConsumer", ac),
 		)
+		c.controller.metricsCollector.IncrSyncOperation("ApisixConsumer", metrics.SyncResultTranslationFailure)
 		c.controller.recorderEvent(ac, corev1.EventTypeWarning, _resourceSyncAborted, err)
 		c.controller.recordStatus(ac, _resourceSyncAborted, err, metav1.ConditionFalse)
 		return err
 	}
-	log.Debug("got consumer object from ApisixConsumer",
+	log.Debugw("got consumer object from ApisixConsumer",
 		zap.Any("consumer", consumer),
 		zap.Any("ApisixConsumer", ac),
 	)
 
-	if err := c.controller.syncConsumer(ctx, consumer, ev.Type); err != nil {
+	clusters, err := c.controller.selectClusters(ac)
+	if err != nil && ev.Type != types.EventDelete {
+		log.Errorw("failed to select apisix clusters",
+			zap.Error(err),
+			zap.Any("ApisixConsumer", ac),
+		)
+		c.controller.metricsCollector.IncrSyncOperation("ApisixConsumer", metrics.SyncResultTranslationFailure)
+		c.controller.recorderEvent(ac, corev1.EventTypeWarning, _resourceSyncAborted, err)
+		c.controller.recordStatus(ac, _resourceSyncAborted, err, metav1.ConditionFalse)
+		return err
+	}
+	if err := c.controller.syncConsumer(ctx, dependent, clusters, consumer, ev.Type); err != nil {
 		log.Errorw("failed to sync Consumer to APISIX",
 			zap.Error(err),
 			zap.Any("consumer", consumer),
 		)
+		c.controller.metricsCollector.IncrSyncOperation("ApisixConsumer", metrics.SyncResultPushFailure)
 		c.controller.recorderEvent(ac, corev1.EventTypeWarning, _resourceSyncAborted, err)
 		c.controller.recordStatus(ac, _resourceSyncAborted, err, metav1.ConditionFalse)
 		return err
 	}
+	if finalizing {
+		return c.controller.removeFinalizer(ctx, ac)
+	}
+	if released != nil {
+		return c.controller.removeFinalizer(ctx, released)
+	}
 
 	c.controller.recorderEvent(ac, corev1.EventTypeNormal, _resourceSynced, nil)
+	c.controller.recordStatus(ac, _resourceSynced, nil, metav1.ConditionTrue)
 	return nil
 }
 
 func (c *apisixConsumerController) handleSyncErr(obj interface{}, err error) {
 	if err == nil {
 		c.workqueue.Forget(obj)
+		c.controller.metricsCollector.IncrSyncOperation("ApisixConsumer", metrics.SyncResultSuccess)
 		return
 	}
 	log.Warnw("sync ApisixConsumer failed, will retry",
@@ -159,6 +223,12 @@ func (c *apisixConsumerController) onAdd(obj interface{}) {
 	if !c.controller.namespaceWatching(key) {
 		return
 	}
+	if !c.controller.isApisixObjectEffective(obj.(*configv2alpha1.ApisixConsumer)) {
+		log.Debugw("ignore noneffective ApisixConsumer add event",
+			zap.Any("object", obj),
+		)
+		return
+	}
 	log.Debugw("ApisixConsumer add event arrived",
 		zap.Any("object", obj),
 	)
@@ -183,6 +253,17 @@ func (c *apisixConsumerController) onUpdate(oldObj, newObj interface{}) {
 	if !c.controller.namespaceWatching(key) {
 		return
 	}
+	if !c.controller.isApisixObjectEffective(curr) {
+		if c.controller.isApisixObjectEffective(prev) {
+			// The ApisixConsumer is not handled by us anymore, delete its resources.
+			c.workqueue.AddRateLimited(&types.Event{
+				Type:      types.EventDelete,
+				Object:    key,
+				Tombstone: prev,
+			})
+		}
+		return
+	}
 	log.Debugw("ApisixConsumer update event arrived",
 		zap.Any("new object", curr),
 		zap.Any("old object", prev),
@@ -209,7 +290,7 @@ func (c *apisixConsumerController) onDelete(obj interface{}) {
 		log.Errorf("found ApisixConsumer resource with bad meta namespace key: %s", err)
 		return
 	}
-	if !c.controller.namespaceWatching(key) {
+	if !c.controller.namespaceWatching(key) || !c.controller.isApisixObjectEffective(ac) {
 		return
 	}
 	log.Debugw("ApisixConsumer delete event arrived",
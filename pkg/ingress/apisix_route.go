@@ -16,6 +16,7 @@ package ingress
 
 import (
 	"context"
+	"sync"
 	"time"
 
 	"go.uber.org/zap"
@@ -26,8 +27,10 @@ import (
 	"k8s.io/client-go/util/workqueue"
 
 	"github.com/apache/apisix-ingress-controller/pkg/kube"
+	configv2alpha1 "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/apis/config/v2alpha1"
 	"github.com/apache/apisix-ingress-controller/pkg/kube/translation"
 	"github.com/apache/apisix-ingress-controller/pkg/log"
+	"github.com/apache/apisix-ingress-controller/pkg/metrics"
 	"github.com/apache/apisix-ingress-controller/pkg/types"
 )
 
@@ -35,6 +38,10 @@ type apisixRouteController struct {
 	controller *Controller
 	workqueue  workqueue.RateLimitingInterface
 	workers    int
+
+	rulesMu sync.Mutex
+	// key -> rules translated in the latest sync
+	rules map[string][]configv2alpha1.ApisixRouteRuleStatus
 }
 
 func (c *Controller) newApisixRouteController() *apisixRouteController {
@@ -42,6 +49,7 @@ func (c *Controller) newApisixRouteController() *apisixRouteController {
 		controller: c,
 		workqueue:  workqueue.NewNamedRateLimitingQueue(workqueue.NewItemFastSlowRateLimiter(1*time.Second, 60*time.Second, 5), "ApisixRoute"),
 		workers:    1,
+		rules:      make(map[string][]configv2alpha1.ApisixRouteRuleStatus),
 	}
 	c.apisixRouteInformer.AddEventHandler(
 		cache.ResourceEventHandlerFuncs{
@@ -119,83 +127,100 @@ func (c *apisixRouteController) sync(ctx context.Context, ev *types.Event) error
 			return nil
 		}
 	}
+	// released is the ApisixRoute which is not handled by us anymore, its
+	// resources are deleted and the finalizer is removed.
+	var released kube.ApisixRoute
 	if ev.Type == types.EventDelete {
 		if ar != nil {
-			// We still find the resource while we are processing the DELETE event,
-			// that means object with same namespace and name was created, discarding
-			// this stale DELETE event.
-			log.Warnw("discard the stale ApisixRoute delete event since the resource still exists",
-				zap.String("key", obj.Key),
-			)
-			return nil
+			if !c.controller.namespaceWatching(obj.Key) || !c.controller.isApisixObjectEffective(apisixRouteObject(ar)) {
+				released = ar
+			} else {
+				// We still find the resource while we are processing the DELETE event,
+				// that means object with same namespace and name was created, discarding
+				// this stale DELETE event.
+				log.Warnw("discard the stale ApisixRoute delete event since the resource still exists",
+					zap.String("key", obj.Key),
+				)
+				return nil
+			}
 		}
 		ar = ev.Tombstone.(kube.ApisixRoute)
+	} else if !c.controller.namespaceWatching(obj.Key) || !c.controller.isApisixObjectEffective(apisixRouteObject(ar)) {
+		return nil
 	}
-	//
-	switch obj.GroupVersion {
-	case kube.ApisixRouteV1:
-		tctx, err = c.controller.translator.TranslateRouteV1(ar.V1())
-		if err != nil {
-			log.Errorw("failed to translate ApisixRoute v1",
-				zap.Error(err),
-				zap.Any("object", ar),
-			)
+	finalizing := false
+	if ev.Type != types.EventDelete {
+		arObj := apisixRouteObject(ar)
+		if c.controller.isFinalizing(arObj) {
+			if isForceDeleting(arObj) {
+				return c.controller.forceRemoveFinalizer(ctx, arObj)
+			}
+			// The ApisixRoute is being deleted, handle it as a DELETE event.
+			finalizing = true
+			ev = &types.Event{
+				Type:      types.EventDelete,
+				Object:    ev.Object,
+				Tombstone: ar,
+			}
+		} else if err := c.controller.ensureFinalizer(ctx, arObj); err != nil {
 			return err
 		}
-	case kube.ApisixRouteV2alpha1:
-		if ev.Type != types.EventDelete {
+	}
+	dependent := sourceKey(translation.SourceKindApisixRoute, obj.Key)
+	if ev.Type == types.EventDelete {
+		c.controller.dependencyIndex.set(dependent, nil)
+	} else {
+		c.controller.dependencyIndex.set(dependent, apisixRouteDependencies(ar))
+	}
+	var m *manifest
+	// Resources are deleted from the cluster caches by their source labels
+	// on delete event, the ApisixRoute is not translated as its Services
+	// might be deleted together.
+	if ev.Type != types.EventDelete {
+		switch obj.GroupVersion {
+		case kube.ApisixRouteV1:
+			tctx, err = c.controller.translator.TranslateRouteV1(ar.V1())
+		case kube.ApisixRouteV2alpha1:
 			tctx, err = c.controller.translator.TranslateRouteV2alpha1(ar.V2alpha1())
-		} else {
-			// Use TranslateRouteV2alpha1NotStrictly in EventDelete.
-			// if K8S service has been removed before ApisixRoute resource, the translation about nodes
-			// of upstream will be failed.
-			tctx, err = c.controller.translator.TranslateRouteV2alpha1NotStrictly(ar.V2alpha1())
-		}
-		if err != nil {
-			log.Errorw("failed to translate ApisixRoute v2alpha1",
-				zap.Error(err),
-				zap.Any("object", ar),
-			)
-			return err
-		}
-	case kube.ApisixRouteV2beta1:
-		if ev.Type != types.EventDelete {
+			c.setRules(obj.Key, routeRules(ar, tctx, err))
+		case kube.ApisixRouteV2beta1:
 			tctx, err = c.controller.translator.TranslateRouteV2beta1(ar.V2beta1())
-		} else {
-			tctx, err = c.controller.translator.TranslateRouteV2beta1NotStrictly(ar.V2beta1())
+			c.setRules(obj.Key, routeRules(ar, tctx, err))
 		}
 		if err != nil {
-			log.Errorw("failed to translate ApisixRoute v2beta1",
+			log.Errorw("failed to translate ApisixRoute",
+				zap.String("version", obj.GroupVersion),
 				zap.Error(err),
 				zap.Any("object", ar),
 			)
+			c.controller.metricsCollector.IncrSyncOperation("ApisixRoute", metrics.SyncResultTranslationFailure)
 			return err
 		}
-	}
 
-	log.Debugw("translated ApisixRoute",
-		zap.Any("routes", tctx.Routes),
-		zap.Any("upstreams", tctx.Upstreams),
-		zap.Any("apisix_route", ar),
-	)
+		log.Debugw("translated ApisixRoute",
+			zap.Any("routes", tctx.Routes),
+			zap.Any("upstreams", tctx.Upstreams),
+			zap.Any("apisix_route", ar),
+		)
 
-	m := &manifest{
-		routes:       tctx.Routes,
-		upstreams:    tctx.Upstreams,
-		streamRoutes: tctx.StreamRoutes,
+		m = &manifest{
+			routes:       tctx.Routes,
+			upstreams:    tctx.Upstreams,
+			streamRoutes: tctx.StreamRoutes,
+		}
 	}
 
-	var (
-		added   *manifest
-		updated *manifest
-		deleted *manifest
-	)
-
-	if ev.Type == types.EventDelete {
-		deleted = m
-	} else if ev.Type == types.EventAdd {
-		added = m
-	} else {
+	clusters, err := c.controller.selectClusters(apisixRouteObject(ar))
+	if err != nil && ev.Type != types.EventDelete {
+		log.Errorw("failed to select apisix clusters",
+			zap.Error(err),
+			zap.Any("ApisixRoute", ar),
+		)
+		c.controller.metricsCollector.IncrSyncOperation("ApisixRoute", metrics.SyncResultTranslationFailure)
+		return err
+	}
+	var om *manifest
+	if ev.Type == types.EventUpdate {
 		var oldCtx *translation.TranslateContext
 		switch obj.GroupVersion {
 		case kube.ApisixRouteV1:
@@ -212,18 +237,28 @@ func (c *apisixRouteController) sync(ctx context.Context, ev *types.Event) error
 				zap.Error(err),
 				zap.Any("ApisixRoute", ar),
 			)
+			c.controller.metricsCollector.IncrSyncOperation("ApisixRoute", metrics.SyncResultTranslationFailure)
 			return err
 		}
 
-		om := &manifest{
+		om = &manifest{
 			routes:       oldCtx.Routes,
 			upstreams:    oldCtx.Upstreams,
 			streamRoutes: oldCtx.StreamRoutes,
 		}
-		added, updated, deleted = m.diff(om)
 	}
-
-	return c.controller.syncManifests(ctx, added, updated, deleted)
+	source := sourceKey(translation.SourceKindApisixRoute, obj.Key)
+	if err := c.controller.syncSourceManifests(ctx, source, clusters, om, m); err != nil {
+		c.controller.metricsCollector.IncrSyncOperation("ApisixRoute", metrics.SyncResultPushFailure)
+		return err
+	}
+	if finalizing {
+		return c.controller.removeFinalizer(ctx, apisixRouteObject(ar))
+	}
+	if released != nil {
+		return c.controller.removeFinalizer(ctx, apisixRouteObject(released))
+	}
+	return nil
 }
 
 func (c *apisixRouteController) handleSyncErr(obj interface{}, errOrigin error) {
@@ -251,10 +286,10 @@ func (c *apisixRouteController) handleSyncErr(obj interface{}, errOrigin error)
 					c.controller.recorderEvent(ar.V1(), v1.EventTypeNormal, _resourceSynced, nil)
 				case kube.ApisixRouteV2alpha1:
 					c.controller.recorderEvent(ar.V2alpha1(), v1.EventTypeNormal, _resourceSynced, nil)
-					c.controller.recordStatus(ar.V2alpha1(), _resourceSynced, nil, metav1.ConditionTrue)
+					c.recordStatus(ar.V2alpha1(), event.Key, _resourceSynced, nil, metav1.ConditionTrue)
 				case kube.ApisixRouteV2beta1:
 					c.controller.recorderEvent(ar.V2beta1(), v1.EventTypeNormal, _resourceSynced, nil)
-					c.controller.recordStatus(ar.V2beta1(), _resourceSynced, nil, metav1.ConditionTrue)
+					c.recordStatus(ar.V2beta1(), event.Key, _resourceSynced, nil, metav1.ConditionTrue)
 				}
 			} else {
 				log.Errorw("failed list ApisixRoute",
@@ -262,9 +297,11 @@ func (c *apisixRouteController) handleSyncErr(obj interface{}, errOrigin error)
 					zap.String("name", name),
 					zap.String("namespace", namespace),
 				)
+				c.popRules(event.Key)
 			}
 		}
 		c.workqueue.Forget(obj)
+		c.controller.metricsCollector.IncrSyncOperation("ApisixRoute", metrics.SyncResultSuccess)
 		return
 	}
 	log.Warnw("sync ApisixRoute failed, will retry",
@@ -277,10 +314,10 @@ func (c *apisixRouteController) handleSyncErr(obj interface{}, errOrigin error)
 			c.controller.recorderEvent(ar.V1(), v1.EventTypeWarning, _resourceSyncAborted, errOrigin)
 		case kube.ApisixRouteV2alpha1:
 			c.controller.recorderEvent(ar.V2alpha1(), v1.EventTypeWarning, _resourceSyncAborted, errOrigin)
-			c.controller.recordStatus(ar.V2alpha1(), _resourceSyncAborted, errOrigin, metav1.ConditionFalse)
+			c.recordStatus(ar.V2alpha1(), event.Key, _resourceSyncAborted, errOrigin, metav1.ConditionFalse)
 		case kube.ApisixRouteV2beta1:
 			c.controller.recorderEvent(ar.V2beta1(), v1.EventTypeWarning, _resourceSyncAborted, errOrigin)
-			c.controller.recordStatus(ar.V2beta1(), _resourceSyncAborted, errOrigin, metav1.ConditionFalse)
+			c.recordStatus(ar.V2beta1(), event.Key, _resourceSyncAborted, errOrigin, metav1.ConditionFalse)
 		}
 	} else {
 		log.Errorw("failed list ApisixRoute",
@@ -288,6 +325,7 @@ func (c *apisixRouteController) handleSyncErr(obj interface{}, errOrigin error)
 			zap.String("name", name),
 			zap.String("namespace", namespace),
 		)
+		c.popRules(event.Key)
 	}
 	c.workqueue.AddRateLimited(obj)
 }
@@ -301,10 +339,16 @@ func (c *apisixRouteController) onAdd(obj interface{}) {
 	if !c.controller.namespaceWatching(key) {
 		return
 	}
+	ar := kube.MustNewApisixRoute(obj)
+	if !c.controller.isApisixObjectEffective(apisixRouteObject(ar)) {
+		log.Debugw("ignore noneffective ApisixRoute add event",
+			zap.Any("object", obj),
+		)
+		return
+	}
 	log.Debugw("ApisixRoute add event arrived",
 		zap.Any("object", obj))
 
-	ar := kube.MustNewApisixRoute(obj)
 	c.workqueue.AddRateLimited(&types.Event{
 		Type: types.EventAdd,
 		Object: kube.ApisixRouteEvent{
@@ -328,6 +372,20 @@ func (c *apisixRouteController) onUpdate(oldObj, newObj interface{}) {
 	if !c.controller.namespaceWatching(key) {
 		return
 	}
+	if !c.controller.isApisixObjectEffective(apisixRouteObject(curr)) {
+		if c.controller.isApisixObjectEffective(apisixRouteObject(prev)) {
+			// The ApisixRoute is not handled by us anymore, delete its resources.
+			c.workqueue.AddRateLimited(&types.Event{
+				Type: types.EventDelete,
+				Object: kube.ApisixRouteEvent{
+					Key:          key,
+					GroupVersion: prev.GroupVersion(),
+				},
+				Tombstone: prev,
+			})
+		}
+		return
+	}
 	log.Debugw("ApisixRoute update event arrived",
 		zap.Any("new object", curr),
 		zap.Any("old object", prev),
@@ -356,7 +414,7 @@ func (c *apisixRouteController) onDelete(obj interface{}) {
 		log.Errorf("found ApisixRoute resource with bad meta namesapce key: %s", err)
 		return
 	}
-	if !c.controller.namespaceWatching(key) {
+	if !c.controller.namespaceWatching(key) || !c.controller.isApisixObjectEffective(apisixRouteObject(ar)) {
 		return
 	}
 	log.Debugw("ApisixRoute delete event arrived",
@@ -371,3 +429,27 @@ func (c *apisixRouteController) onDelete(obj interface{}) {
 		Tombstone: ar,
 	})
 }
+
+// resync enqueues the ApisixRoute by its key so it will be translated
+// again, the current object is used as the old one for update events.
+func (c *apisixRouteController) resync(key string, typ types.EventType) {
+	obj, exists, err := c.controller.apisixRouteInformer.GetStore().GetByKey(key)
+	if err != nil || !exists || !c.controller.namespaceWatching(key) {
+		return
+	}
+	ar, err := kube.NewApisixRoute(obj)
+	if err != nil || !c.controller.isApisixObjectEffective(apisixRouteObject(ar)) {
+		return
+	}
+	ev := kube.ApisixRouteEvent{
+		Key:          key,
+		GroupVersion: ar.GroupVersion(),
+	}
+	if typ == types.EventUpdate {
+		ev.OldObject = ar
+	}
+	c.workqueue.Add(&types.Event{
+		Type:   typ,
+		Object: ev,
+	})
+}
@@ -0,0 +1,161 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package ingress
+
+import (
+	"fmt"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/apache/apisix-ingress-controller/pkg/id"
+	"github.com/apache/apisix-ingress-controller/pkg/kube"
+	configv2alpha1 "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/apis/config/v2alpha1"
+	"github.com/apache/apisix-ingress-controller/pkg/kube/translation"
+	apisixv1 "github.com/apache/apisix-ingress-controller/pkg/types/apisix/v1"
+)
+
+const (
+	_ruleStateSynced = "Synced"
+	_ruleStateFailed = "Failed"
+	// The rule is valid but not pushed as other rules failed or the push
+	// failed.
+	_ruleStateAborted = "Aborted"
+)
+
+// routeRules returns rules of the ApisixRoute along with IDs of routes and
+// upstreams they are translated to, the rule which causes the translation
+// error is marked as failed. Rules of ApisixRoute v1 are not reported.
+func routeRules(ar kube.ApisixRoute, tctx *translation.TranslateContext, err error) []configv2alpha1.ApisixRouteRuleStatus {
+	var (
+		rules     []configv2alpha1.ApisixRouteRuleStatus
+		namespace string
+		name      string
+		http      []string
+		stream    []string
+		kind      string
+	)
+	switch ar.GroupVersion() {
+	case kube.ApisixRouteV2alpha1:
+		namespace, name, kind = ar.V2alpha1().Namespace, ar.V2alpha1().Name, "tcp"
+		for _, part := range ar.V2alpha1().Spec.HTTP {
+			http = append(http, part.Name)
+		}
+		for _, part := range ar.V2alpha1().Spec.TCP {
+			stream = append(stream, part.Name)
+		}
+	case kube.ApisixRouteV2beta1:
+		namespace, name, kind = ar.V2beta1().Namespace, ar.V2beta1().Name, "stream"
+		for _, part := range ar.V2beta1().Spec.HTTP {
+			http = append(http, part.Name)
+		}
+		for _, part := range ar.V2beta1().Spec.Stream {
+			stream = append(stream, part.Name)
+		}
+	default:
+		return nil
+	}
+
+	for i, rule := range http {
+		status := configv2alpha1.ApisixRouteRuleStatus{
+			Name:    rule,
+			Kind:    "http",
+			RouteID: id.GenID(apisixv1.ComposeRouteName(namespace, name, rule)),
+		}
+		if tctx != nil {
+			for _, r := range tctx.Routes {
+				if r.ID == status.RouteID {
+					status.UpstreamIDs = r.ReferencedUpstreams()
+					break
+				}
+			}
+		}
+		markFailedRule(&status, fmt.Sprintf("spec.http[%d]", i), err)
+		rules = append(rules, status)
+	}
+	for i, rule := range stream {
+		status := configv2alpha1.ApisixRouteRuleStatus{
+			Name:    rule,
+			Kind:    kind,
+			RouteID: id.GenID(apisixv1.ComposeStreamRouteName(namespace, name, rule)),
+		}
+		if tctx != nil {
+			for _, sr := range tctx.StreamRoutes {
+				if sr.ID == status.RouteID && sr.UpstreamId != "" {
+					status.UpstreamIDs = []string{sr.UpstreamId}
+					break
+				}
+			}
+		}
+		markFailedRule(&status, fmt.Sprintf("spec.%s[%d]", kind, i), err)
+		rules = append(rules, status)
+	}
+	return rules
+}
+
+// markFailedRule marks the rule as failed if the invalid field is under
+// the path of the rule.
+func markFailedRule(status *configv2alpha1.ApisixRouteRuleStatus, path string, err error) {
+	field := translation.FieldPath(err)
+	if field != path && !strings.HasPrefix(field, path+".") {
+		return
+	}
+	status.State = _ruleStateFailed
+	status.FieldPath = field
+	status.Message = err.Error()
+}
+
+// finishRules fills states of the rules according to the sync result.
+func finishRules(rules []configv2alpha1.ApisixRouteRuleStatus, err error) []configv2alpha1.ApisixRouteRuleStatus {
+	finished := make([]configv2alpha1.ApisixRouteRuleStatus, 0, len(rules))
+	for _, rule := range rules {
+		if rule.State != _ruleStateFailed {
+			if err == nil {
+				rule.State = _ruleStateSynced
+			} else {
+				rule.State = _ruleStateAborted
+			}
+		}
+		finished = append(finished, rule)
+	}
+	return finished
+}
+
+// setRules records rules of the ApisixRoute translated in the latest sync,
+// they are reported once the sync is finished.
+func (c *apisixRouteController) setRules(key string, rules []configv2alpha1.ApisixRouteRuleStatus) {
+	c.rulesMu.Lock()
+	defer c.rulesMu.Unlock()
+	c.rules[key] = rules
+}
+
+func (c *apisixRouteController) popRules(key string) ([]configv2alpha1.ApisixRouteRuleStatus, bool) {
+	c.rulesMu.Lock()
+	defer c.rulesMu.Unlock()
+	rules, ok := c.rules[key]
+	delete(c.rules, key)
+	return rules, ok
+}
+
+// recordStatus records status of the ApisixRoute, states of rules are
+// reported if they were translated.
+func (c *apisixRouteController) recordStatus(at interface{}, key string, reason string, err error, status metav1.ConditionStatus) {
+	rules, ok := c.popRules(key)
+	if !ok {
+		c.controller.recordStatus(at, reason, err, status)
+		return
+	}
+	c.controller.recordRouteStatus(at, reason, err, status, finishRules(rules, err))
+}
@@ -0,0 +1,98 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package ingress
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	listerscorev1 "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/apache/apisix-ingress-controller/pkg/id"
+	"github.com/apache/apisix-ingress-controller/pkg/kube"
+	configv2beta1 "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/apis/config/v2beta1"
+	"github.com/apache/apisix-ingress-controller/pkg/kube/translation"
+	apisixv1 "github.com/apache/apisix-ingress-controller/pkg/types/apisix/v1"
+)
+
+func TestRouteRules(t *testing.T) {
+	ar := &configv2beta1.ApisixRoute{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "httpbin",
+			Namespace: "default",
+		},
+		Spec: configv2beta1.ApisixRouteSpec{
+			HTTP: []configv2beta1.ApisixRouteHTTP{
+				{Name: "rule1"},
+				{Name: "rule2"},
+			},
+			Stream: []configv2beta1.ApisixRouteStream{
+				{Name: "rule3"},
+			},
+		},
+	}
+	routeID := id.GenID(apisixv1.ComposeRouteName("default", "httpbin", "rule1"))
+	streamRouteID := id.GenID(apisixv1.ComposeStreamRouteName("default", "httpbin", "rule3"))
+	tctx := &translation.TranslateContext{
+		Routes: []*apisixv1.Route{
+			{
+				Metadata:   apisixv1.Metadata{ID: routeID},
+				UpstreamId: "u1",
+			},
+		},
+		StreamRoutes: []*apisixv1.StreamRoute{
+			{
+				ID:         streamRouteID,
+				UpstreamId: "u2",
+			},
+		},
+	}
+
+	rules := finishRules(routeRules(kube.MustNewApisixRoute(ar), tctx, nil), nil)
+	assert.Len(t, rules, 3)
+	assert.Equal(t, "rule1", rules[0].Name)
+	assert.Equal(t, "http", rules[0].Kind)
+	assert.Equal(t, routeID, rules[0].RouteID)
+	assert.Equal(t, []string{"u1"}, rules[0].UpstreamIDs)
+	assert.Equal(t, _ruleStateSynced, rules[0].State)
+	assert.Equal(t, "stream", rules[2].Kind)
+	assert.Equal(t, streamRouteID, rules[2].RouteID)
+	assert.Equal(t, []string{"u2"}, rules[2].UpstreamIDs)
+
+	// Push failure aborts all rules.
+	rules = finishRules(routeRules(kube.MustNewApisixRoute(ar), tctx, nil), errors.New("timeout"))
+	for _, rule := range rules {
+		assert.Equal(t, _ruleStateAborted, rule.State)
+	}
+
+	// The backend service of the first rule doesn't exist.
+	ar.Spec.HTTP[0].Backend.ServiceName = "httpbin"
+	tr := translation.NewTranslator(&translation.TranslatorOptions{
+		ServiceLister: listerscorev1.NewServiceLister(cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})),
+	})
+	_, err := tr.TranslateRouteV2beta1(ar)
+	assert.NotNil(t, err)
+	rules = finishRules(routeRules(kube.MustNewApisixRoute(ar), nil, err), err)
+	assert.Equal(t, _ruleStateFailed, rules[0].State)
+	assert.Equal(t, "spec.http[0].backend", rules[0].FieldPath)
+	assert.Equal(t, err.Error(), rules[0].Message)
+	assert.Nil(t, rules[0].UpstreamIDs)
+	assert.Equal(t, _ruleStateAborted, rules[1].State)
+	assert.Equal(t, "", rules[1].FieldPath)
+	assert.Equal(t, _ruleStateAborted, rules[2].State)
+}
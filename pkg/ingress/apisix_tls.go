@@ -16,7 +16,6 @@ package ingress
 
 import (
 	"context"
-	"sync"
 	"time"
 
 	"go.uber.org/zap"
@@ -26,8 +25,11 @@ import (
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/util/workqueue"
 
+	"github.com/apache/apisix-ingress-controller/pkg/id"
 	configv1 "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/apis/config/v1"
+	"github.com/apache/apisix-ingress-controller/pkg/kube/translation"
 	"github.com/apache/apisix-ingress-controller/pkg/log"
+	"github.com/apache/apisix-ingress-controller/pkg/metrics"
 	"github.com/apache/apisix-ingress-controller/pkg/types"
 	v1 "github.com/apache/apisix-ingress-controller/pkg/types/apisix/v1"
 )
@@ -102,23 +104,62 @@ func (c *apisixTlsController) sync(ctx context.Context, ev *types.Event) error {
 			return nil
 		}
 	}
+	// released is the ApisixTls which is not handled by us anymore, its
+	// resources are deleted and the finalizer is removed.
+	var released *configv1.ApisixTls
 	if ev.Type == types.EventDelete {
 		if tls != nil {
-			// We still find the resource while we are processing the DELETE event,
-			// that means object with same namespace and name was created, discarding
-			// this stale DELETE event.
-			log.Warnf("discard the stale ApisixTls delete event since the %s exists", key)
-			return nil
+			if !c.controller.namespaceWatching(key) || !c.controller.isApisixObjectEffective(tls) {
+				released = tls
+			} else {
+				// We still find the resource while we are processing the DELETE event,
+				// that means object with same namespace and name was created, discarding
+				// this stale DELETE event.
+				log.Warnf("discard the stale ApisixTls delete event since the %s exists", key)
+				return nil
+			}
 		}
 		tls = ev.Tombstone.(*configv1.ApisixTls)
+	} else if !c.controller.namespaceWatching(key) || !c.controller.isApisixObjectEffective(tls) {
+		return nil
+	}
+	finalizing := false
+	if ev.Type != types.EventDelete {
+		if c.controller.isFinalizing(tls) {
+			if isForceDeleting(tls) {
+				return c.controller.forceRemoveFinalizer(ctx, tls)
+			}
+			// The ApisixTls is being deleted, handle it as a DELETE event.
+			finalizing = true
+			ev = &types.Event{
+				Type:      types.EventDelete,
+				Object:    ev.Object,
+				Tombstone: tls,
+			}
+		} else if err := c.controller.ensureFinalizer(ctx, tls); err != nil {
+			return err
+		}
+	}
+
+	dependent := sourceKey(translation.SourceKindApisixTls, key)
+	if ev.Type == types.EventDelete {
+		c.controller.dependencyIndex.set(dependent, nil)
+	} else {
+		c.controller.dependencyIndex.set(dependent, apisixTlsDependencies(tls))
 	}
 
 	ssl, err := c.controller.translator.TranslateSSL(tls)
+	if err != nil && ev.Type == types.EventDelete {
+		// The Secrets might be deleted, but only the ID is necessary
+		// for deleting the SSL.
+		ssl, err = &v1.Ssl{ID: id.GenID(tls.Namespace + "_" + tls.Name)}, nil
+	}
 	if err != nil {
 		log.Errorw("failed to translate ApisixTls",
 			zap.Error(err),
 			zap.Any("ApisixTls", tls),
 		)
+		c.controller.metricsCollector.IncrSyncOperation("ApisixTls", metrics.SyncResultTranslationFailure)
 		c.controller.recorderEvent(tls, corev1.EventTypeWarning, _resourceSyncAborted, err)
 		c.controller.recordStatus(tls, _resourceSyncAborted, err, metav1.ConditionFalse)
 		return err
@@ -128,51 +169,43 @@ func (c *apisixTlsController) sync(ctx context.Context, ev *types.Event) error {
 		zap.Any("ApisixTls", tls),
 	)
 
-	secretKey := tls.Spec.Secret.Namespace + "_" + tls.Spec.Secret.Name
-	c.syncSecretSSL(secretKey, key, ssl, ev.Type)
-	if tls.Spec.Client != nil {
-		caSecretKey := tls.Spec.Client.CASecret.Namespace + "_" + tls.Spec.Client.CASecret.Name
-		if caSecretKey != secretKey {
-			c.syncSecretSSL(caSecretKey, key, ssl, ev.Type)
-		}
+	clusters, err := c.controller.selectClusters(tls)
+	if err != nil && ev.Type != types.EventDelete {
+		log.Errorw("failed to select apisix clusters",
+			zap.Error(err),
+			zap.Any("ApisixTls", tls),
+		)
+		c.controller.metricsCollector.IncrSyncOperation("ApisixTls", metrics.SyncResultTranslationFailure)
+		c.controller.recorderEvent(tls, corev1.EventTypeWarning, _resourceSyncAborted, err)
+		c.controller.recordStatus(tls, _resourceSyncAborted, err, metav1.ConditionFalse)
+		return err
 	}
-
-	if err := c.controller.syncSSL(ctx, ssl, ev.Type); err != nil {
+	if err := c.controller.syncSSL(ctx, dependent, clusters, ssl, ev.Type); err != nil {
 		log.Errorw("failed to sync SSL to APISIX",
 			zap.Error(err),
 			zap.Any("ssl", ssl),
 		)
+		c.controller.metricsCollector.IncrSyncOperation("ApisixTls", metrics.SyncResultPushFailure)
 		c.controller.recorderEvent(tls, corev1.EventTypeWarning, _resourceSyncAborted, err)
 		c.controller.recordStatus(tls, _resourceSyncAborted, err, metav1.ConditionFalse)
 		return err
 	}
+	if finalizing {
+		return c.controller.removeFinalizer(ctx, tls)
+	}
+	if released != nil {
+		return c.controller.removeFinalizer(ctx, released)
+	}
 
 	c.controller.recorderEvent(tls, corev1.EventTypeNormal, _resourceSynced, nil)
 	c.controller.recordStatus(tls, _resourceSynced, nil, metav1.ConditionTrue)
 	return err
 }
 
-func (c *apisixTlsController) syncSecretSSL(secretKey string, apisixTlsKey string, ssl *v1.Ssl, event types.EventType) {
-	if ssls, ok := c.controller.secretSSLMap.Load(secretKey); ok {
-		sslMap := ssls.(*sync.Map)
-		switch event {
-		case types.EventDelete:
-			sslMap.Delete(apisixTlsKey)
-			c.controller.secretSSLMap.Store(secretKey, sslMap)
-		default:
-			sslMap.Store(apisixTlsKey, ssl)
-			c.controller.secretSSLMap.Store(secretKey, sslMap)
-		}
-	} else if event != types.EventDelete {
-		sslMap := new(sync.Map)
-		sslMap.Store(apisixTlsKey, ssl)
-		c.controller.secretSSLMap.Store(secretKey, sslMap)
-	}
-}
-
 func (c *apisixTlsController) handleSyncErr(obj interface{}, err error) {
 	if err == nil {
 		c.workqueue.Forget(obj)
+		c.controller.metricsCollector.IncrSyncOperation("ApisixTls", metrics.SyncResultSuccess)
 		return
 	}
 	log.Warnw("sync ApisixTls failed, will retry",
@@ -191,6 +224,12 @@ func (c *apisixTlsController) onAdd(obj interface{}) {
 	if !c.controller.namespaceWatching(key) {
 		return
 	}
+	if !c.controller.isApisixObjectEffective(obj.(*configv1.ApisixTls)) {
+		log.Debugw("ignore noneffective ApisixTls add event",
+			zap.Any("object", obj),
+		)
+		return
+	}
 	log.Debugw("ApisixTls add event arrived",
 		zap.Any("object", obj),
 	)
@@ -214,6 +253,17 @@ func (c *apisixTlsController) onUpdate(prev, curr interface{}) {
 	if !c.controller.namespaceWatching(key) {
 		return
 	}
+	if !c.controller.isApisixObjectEffective(newTls) {
+		if c.controller.isApisixObjectEffective(oldTls) {
+			// The ApisixTls is not handled by us anymore, delete its resources.
+			c.workqueue.AddRateLimited(&types.Event{
+				Type:      types.EventDelete,
+				Object:    key,
+				Tombstone: oldTls,
+			})
+		}
+		return
+	}
 	log.Debugw("ApisixTls update event arrived",
 		zap.Any("new object", curr),
 		zap.Any("old object", prev),
@@ -241,7 +291,7 @@ func (c *apisixTlsController) onDelete(obj interface{}) {
 		log.Errorf("found ApisixTls resource with bad meta namespace key: %s", err)
 		return
 	}
-	if !c.controller.namespaceWatching(key) {
+	if !c.controller.namespaceWatching(key) || !c.controller.isApisixObjectEffective(tls) {
 		return
 	}
 	log.Debugw("ApisixTls delete event arrived",
@@ -28,6 +28,7 @@ import (
 	apisixcache "github.com/apache/apisix-ingress-controller/pkg/apisix/cache"
 	configv1 "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/apis/config/v1"
 	"github.com/apache/apisix-ingress-controller/pkg/log"
+	"github.com/apache/apisix-ingress-controller/pkg/metrics"
 	"github.com/apache/apisix-ingress-controller/pkg/types"
 	apisixv1 "github.com/apache/apisix-ingress-controller/pkg/types/apisix/v1"
 )
@@ -106,15 +107,41 @@ func (c *apisixUpstreamController) sync(ctx context.Context, ev *types.Event) er
 			return nil
 		}
 	}
+	// released is the ApisixUpstream which is not handled by us anymore, its
+	// resources are deleted and the finalizer is removed.
+	var released *configv1.ApisixUpstream
 	if ev.Type == types.EventDelete {
 		if au != nil {
-			// We still find the resource while we are processing the DELETE event,
-			// that means object with same namespace and name was created, discarding
-			// this stale DELETE event.
-			log.Warnf("discard the stale ApisixUpstream delete event since the %s exists", key)
-			return nil
+			if !c.controller.namespaceWatching(key) || !c.controller.isApisixObjectEffective(au) {
+				released = au
+			} else {
+				// We still find the resource while we are processing the DELETE event,
+				// that means object with same namespace and name was created, discarding
+				// this stale DELETE event.
+				log.Warnf("discard the stale ApisixUpstream delete event since the %s exists", key)
+				return nil
+			}
 		}
 		au = ev.Tombstone.(*configv1.ApisixUpstream)
+	} else if !c.controller.namespaceWatching(key) || !c.controller.isApisixObjectEffective(au) {
+		return nil
+	}
+	finalizing := false
+	if ev.Type != types.EventDelete {
+		if c.controller.isFinalizing(au) {
+			if isForceDeleting(au) {
+				return c.controller.forceRemoveFinalizer(ctx, au)
+			}
+			// The ApisixUpstream is being deleted, handle it as a DELETE event.
+			finalizing = true
+			ev = &types.Event{
+				Type:      types.EventDelete,
+				Object:    ev.Object,
+				Tombstone: au,
+			}
+		} else if err := c.controller.ensureFinalizer(ctx, au); err != nil {
+			return err
+		}
 	}
 
 	var portLevelSettings map[int32]*configv1.ApisixUpstreamConfig
@@ -126,6 +153,16 @@ func (c *apisixUpstreamController) sync(ctx context.Context, ev *types.Event) er
 	}
 
 	svc, err := c.controller.svcLister.Services(namespace).Get(name)
+	if err != nil && ev.Type == types.EventDelete && k8serrors.IsNotFound(err) {
+		// Upstreams of the Service will be deleted, nothing to clean up.
+		if finalizing {
+			return c.controller.removeFinalizer(ctx, au)
+		}
+		if released != nil {
+			return c.controller.removeFinalizer(ctx, released)
+		}
+		return nil
+	}
 	if err != nil {
 		log.Errorf("failed to get service %s: %s", key, err)
 		c.controller.recorderEvent(au, corev1.EventTypeWarning, _resourceSyncAborted, err)
@@ -138,62 +175,71 @@ func (c *apisixUpstreamController) sync(ctx context.Context, ev *types.Event) er
 	if len(au.Spec.Subsets) > 0 {
 		subsets = append(subsets, au.Spec.Subsets...)
 	}
-	clusterName := c.controller.cfg.APISIX.DefaultClusterName
-	for _, port := range svc.Spec.Ports {
-		for _, subset := range subsets {
-			upsName := apisixv1.ComposeUpstreamName(namespace, name, subset.Name, port.Port)
-			// TODO: multiple cluster
-			ups, err := c.controller.apisix.Cluster(clusterName).Upstream().Get(ctx, upsName)
-			if err != nil {
-				if err == apisixcache.ErrNotFound {
-					continue
-				}
-				log.Errorf("failed to get upstream %s: %s", upsName, err)
-				c.controller.recorderEvent(au, corev1.EventTypeWarning, _resourceSyncAborted, err)
-				c.controller.recordStatus(au, _resourceSyncAborted, err, metav1.ConditionFalse)
-				return err
-			}
-			var newUps *apisixv1.Upstream
-			if ev.Type != types.EventDelete {
-				cfg, ok := portLevelSettings[port.Port]
-				if !ok {
-					cfg = &au.Spec.ApisixUpstreamConfig
-				}
-				// FIXME Same ApisixUpstreamConfig might be translated multiple times.
-				newUps, err = c.controller.translator.TranslateUpstreamConfig(cfg)
+	// Upstreams are updated in all clusters where they exist.
+	for _, clusterName := range c.controller.clusterNames() {
+		for _, port := range svc.Spec.Ports {
+			for _, subset := range subsets {
+				upsName := apisixv1.ComposeUpstreamName(namespace, name, subset.Name, port.Port)
+				ups, err := c.controller.apisix.Cluster(clusterName).Upstream().Get(ctx, upsName)
 				if err != nil {
-					log.Errorw("found malformed ApisixUpstream",
-						zap.Any("object", au),
-						zap.Error(err),
-					)
+					if err == apisixcache.ErrNotFound {
+						continue
+					}
+					log.Errorf("failed to get upstream %s: %s", upsName, err)
 					c.controller.recorderEvent(au, corev1.EventTypeWarning, _resourceSyncAborted, err)
 					c.controller.recordStatus(au, _resourceSyncAborted, err, metav1.ConditionFalse)
 					return err
 				}
-			} else {
-				newUps = apisixv1.NewDefaultUpstream()
-			}
+				var newUps *apisixv1.Upstream
+				if ev.Type != types.EventDelete {
+					cfg, ok := portLevelSettings[port.Port]
+					if !ok {
+						cfg = &au.Spec.ApisixUpstreamConfig
+					}
+					// FIXME Same ApisixUpstreamConfig might be translated multiple times.
+					newUps, err = c.controller.translator.TranslateUpstreamConfig(cfg)
+					if err != nil {
+						log.Errorw("found malformed ApisixUpstream",
+							zap.Any("object", au),
+							zap.Error(err),
+						)
+						c.controller.metricsCollector.IncrSyncOperation("ApisixUpstream", metrics.SyncResultTranslationFailure)
+						c.controller.recorderEvent(au, corev1.EventTypeWarning, _resourceSyncAborted, err)
+						c.controller.recordStatus(au, _resourceSyncAborted, err, metav1.ConditionFalse)
+						return err
+					}
+				} else {
+					newUps = apisixv1.NewDefaultUpstream()
+				}
 
-			newUps.Metadata = ups.Metadata
-			newUps.Nodes = ups.Nodes
-			log.Debugw("updating upstream since ApisixUpstream changed",
-				zap.String("event", ev.Type.String()),
-				zap.Any("upstream", newUps),
-				zap.Any("ApisixUpstream", au),
-			)
-			if _, err := c.controller.apisix.Cluster(clusterName).Upstream().Update(ctx, newUps); err != nil {
-				log.Errorw("failed to update upstream",
-					zap.Error(err),
+				newUps.Metadata = ups.Metadata
+				newUps.Nodes = ups.Nodes
+				log.Debugw("updating upstream since ApisixUpstream changed",
+					zap.String("event", ev.Type.String()),
 					zap.Any("upstream", newUps),
 					zap.Any("ApisixUpstream", au),
-					zap.String("cluster", clusterName),
 				)
-				c.controller.recorderEvent(au, corev1.EventTypeWarning, _resourceSyncAborted, err)
-				c.controller.recordStatus(au, _resourceSyncAborted, err, metav1.ConditionFalse)
-				return err
+				if _, err := c.controller.apisix.Cluster(clusterName).Upstream().Update(ctx, newUps); err != nil {
+					log.Errorw("failed to update upstream",
+						zap.Error(err),
+						zap.Any("upstream", newUps),
+						zap.Any("ApisixUpstream", au),
+						zap.String("cluster", clusterName),
+					)
+					c.controller.metricsCollector.IncrSyncOperation("ApisixUpstream", metrics.SyncResultPushFailure)
+					c.controller.recorderEvent(au, corev1.EventTypeWarning, _resourceSyncAborted, err)
+					c.controller.recordStatus(au, _resourceSyncAborted, err, metav1.ConditionFalse)
+					return err
+				}
 			}
 		}
 	}
+	if finalizing {
+		return c.controller.removeFinalizer(ctx, au)
+	}
+	if released != nil {
+		return c.controller.removeFinalizer(ctx, released)
+	}
 	if ev.Type != types.EventDelete {
 		c.controller.recorderEvent(au, corev1.EventTypeNormal, _resourceSynced, nil)
 		c.controller.recordStatus(au, _resourceSynced, nil, metav1.ConditionTrue)
@@ -204,6 +250,7 @@ func (c *apisixUpstreamController) sync(ctx context.Context, ev *types.Event) er
 func (c *apisixUpstreamController) handleSyncErr(obj interface{}, err error) {
 	if err == nil {
 		c.workqueue.Forget(obj)
+		c.controller.metricsCollector.IncrSyncOperation("ApisixUpstream", metrics.SyncResultSuccess)
 		return
 	}
 	log.Warnw("sync ApisixUpstream failed, will retry",
@@ -222,6 +269,12 @@ func (c *apisixUpstreamController) onAdd(obj interface{}) {
 	if !c.controller.namespaceWatching(key) {
 		return
 	}
+	if !c.controller.isApisixObjectEffective(obj.(*configv1.ApisixUpstream)) {
+		log.Debugw("ignore noneffective ApisixUpstream add event",
+			zap.Any("object", obj),
+		)
+		return
+	}
 	log.Debugw("ApisixUpstream add event arrived",
 		zap.Any("object", obj))
 
@@ -229,6 +282,7 @@ func (c *apisixUpstreamController) onAdd(obj interface{}) {
 		Type:   types.EventAdd,
 		Object: key,
 	})
+	c.controller.resyncDependents(sourceKey(_dependencyKindApisixUpstream, key))
 }
 
 func (c *apisixUpstreamController) onUpdate(oldObj, newObj interface{}) {
@@ -245,6 +299,18 @@ func (c *apisixUpstreamController) onUpdate(oldObj, newObj interface{}) {
 	if !c.controller.namespaceWatching(key) {
 		return
 	}
+	if !c.controller.isApisixObjectEffective(curr) {
+		if c.controller.isApisixObjectEffective(prev) {
+			// The ApisixUpstream is not handled by us anymore, delete its resources.
+			c.workqueue.AddRateLimited(&types.Event{
+				Type:      types.EventDelete,
+				Object:    key,
+				Tombstone: prev,
+			})
+			c.controller.resyncDependents(sourceKey(_dependencyKindApisixUpstream, key))
+		}
+		return
+	}
 	log.Debugw("ApisixUpstream update event arrived",
 		zap.Any("new object", curr),
 		zap.Any("old object", prev),
@@ -254,6 +320,9 @@ func (c *apisixUpstreamController) onUpdate(oldObj, newObj interface{}) {
 		Type:   types.EventUpdate,
 		Object: key,
 	})
+	if prev.Generation != curr.Generation || !c.controller.isApisixObjectEffective(prev) {
+		c.controller.resyncDependents(sourceKey(_dependencyKindApisixUpstream, key))
+	}
 }
 
 func (c *apisixUpstreamController) onDelete(obj interface{}) {
@@ -271,7 +340,7 @@ func (c *apisixUpstreamController) onDelete(obj interface{}) {
 		log.Errorf("found ApisixUpstream resource with bad meta namespace key: %s", err)
 		return
 	}
-	if !c.controller.namespaceWatching(key) {
+	if !c.controller.namespaceWatching(key) || !c.controller.isApisixObjectEffective(au) {
 		return
 	}
 	log.Debugw("ApisixUpstream delete event arrived",
@@ -282,4 +351,5 @@ func (c *apisixUpstreamController) onDelete(obj interface{}) {
 		Object:    key,
 		Tombstone: au,
 	})
+	c.controller.resyncDependents(sourceKey(_dependencyKindApisixUpstream, key))
 }
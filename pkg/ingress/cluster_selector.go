@@ -0,0 +1,232 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package ingress
+
+import (
+	"fmt"
+	"sort"
+
+	"go.uber.org/zap"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/apache/apisix-ingress-controller/pkg/apisix"
+	"github.com/apache/apisix-ingress-controller/pkg/kube"
+	configv1 "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/apis/config/v1"
+	configv2alpha1 "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/apis/config/v2alpha1"
+	"github.com/apache/apisix-ingress-controller/pkg/log"
+	"github.com/apache/apisix-ingress-controller/pkg/types"
+)
+
+// _clusterSelectorAnnotation is a label selector which chooses the APISIX
+// clusters (by labels of their ApisixClusterConfig objects) that resources
+// of the annotated object should be pushed to. Objects without it are
+// pushed to the default cluster.
+const _clusterSelectorAnnotation = "apisix.apache.org/cluster-selector"
+
+// clusterNames returns the sorted names of all APISIX clusters, i.e. the
+// default cluster and the ones registered by ApisixClusterConfig objects.
+func (c *Controller) clusterNames() []string {
+	names := []string{c.cfg.APISIX.DefaultClusterName}
+	accs, err := c.apisixClusterConfigLister.List(labels.Everything())
+	if err != nil {
+		log.Errorw("failed to list ApisixClusterConfig",
+			zap.Error(err),
+		)
+		return names
+	}
+	for _, acc := range accs {
+		if acc.Name != c.cfg.APISIX.DefaultClusterName && acc.Spec.Admin != nil {
+			names = append(names, acc.Name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// selectClusters returns the sorted names of the APISIX clusters which
+// resources of the object should be pushed to. Ingresses without the
+// annotation are pushed to the cluster referenced by their IngressClass
+// parameters if any.
+func (c *Controller) selectClusters(obj metav1.Object) ([]string, error) {
+	expr, ok := obj.GetAnnotations()[_clusterSelectorAnnotation]
+	if !ok {
+		if ing, err := kube.NewIngress(obj); err == nil {
+			cluster, ok, err := c.ingressClassCluster(ing)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				return []string{cluster}, nil
+			}
+		}
+		return []string{c.cfg.APISIX.DefaultClusterName}, nil
+	}
+	selector, err := labels.Parse(expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid annotation %s: %s", _clusterSelectorAnnotation, err)
+	}
+	var clusters []string
+	for _, name := range c.clusterNames() {
+		var lbls map[string]string
+		acc, err := c.apisixClusterConfigLister.Get(name)
+		if err == nil {
+			lbls = acc.Labels
+		} else if !k8serrors.IsNotFound(err) {
+			return nil, err
+		}
+		if selector.Matches(labels.Set(lbls)) {
+			clusters = append(clusters, name)
+		}
+	}
+	return clusters, nil
+}
+
+// placedClusters returns the clusters which resources of the source object
+// were pushed to.
+func (c *Controller) placedClusters(source string) []string {
+	if clusters, ok := c.sourceClusters.Load(source); ok {
+		return clusters.([]string)
+	}
+	return nil
+}
+
+// placeSource records the clusters which resources of the source object
+// are pushed to.
+func (c *Controller) placeSource(source string, clusters []string) {
+	if len(clusters) == 0 {
+		c.sourceClusters.Delete(source)
+		return
+	}
+	c.sourceClusters.Store(source, clusters)
+}
+
+// forgetCluster drops all states of the deleted cluster, so objects won't
+// try to delete their resources from it.
+func (c *Controller) forgetCluster(name string) {
+	if err := c.apisix.DeleteCluster(name); err != nil && err != apisix.ErrClusterNotExist {
+		log.Errorw("failed to delete cluster",
+			zap.String("cluster", name),
+			zap.Error(err),
+		)
+	}
+	c.upstreamIndex.deleteCluster(name)
+	c.watchAdminKey(nil, &apisix.ClusterOptions{Name: name})
+	c.sourceClusters.Range(func(k, v interface{}) bool {
+		clusters := v.([]string)
+		if !containsCluster(clusters, name) {
+			return true
+		}
+		var rest []string
+		for _, cluster := range clusters {
+			if cluster != name {
+				rest = append(rest, cluster)
+			}
+		}
+		c.placeSource(k.(string), rest)
+		return true
+	})
+}
+
+// resyncClusterSelectors enqueues objects which select clusters by the
+// annotation, so that their resources are pushed to the newly selected
+// clusters and deleted from the unselected ones.
+func (c *Controller) resyncClusterSelectors() {
+	for _, obj := range c.ingressInformer.GetStore().List() {
+		ing, err := kube.NewIngress(obj)
+		if err != nil || !hasClusterSelector(ingressObject(ing)) {
+			continue
+		}
+		if key, err := cache.MetaNamespaceKeyFunc(obj); err == nil {
+			c.ingressController.resync(key, types.EventUpdate)
+		}
+	}
+	for _, obj := range c.apisixRouteInformer.GetStore().List() {
+		ar, err := kube.NewApisixRoute(obj)
+		if err != nil || !hasClusterSelector(apisixRouteObject(ar)) {
+			continue
+		}
+		if key, err := cache.MetaNamespaceKeyFunc(obj); err == nil {
+			c.apisixRouteController.resync(key, types.EventUpdate)
+		}
+	}
+	for _, obj := range c.apisixTlsInformer.GetStore().List() {
+		tls, ok := obj.(*configv1.ApisixTls)
+		if !ok || !hasClusterSelector(tls) || !c.isApisixObjectEffective(tls) {
+			continue
+		}
+		key, err := cache.MetaNamespaceKeyFunc(obj)
+		if err != nil || !c.namespaceWatching(key) {
+			continue
+		}
+		c.apisixTlsController.workqueue.Add(&types.Event{
+			Type:   types.EventUpdate,
+			Object: key,
+		})
+	}
+	for _, obj := range c.apisixConsumerInformer.GetStore().List() {
+		ac, ok := obj.(*configv2alpha1.ApisixConsumer)
+		if !ok || !hasClusterSelector(ac) || !c.isApisixObjectEffective(ac) {
+			continue
+		}
+		key, err := cache.MetaNamespaceKeyFunc(obj)
+		if err != nil || !c.namespaceWatching(key) {
+			continue
+		}
+		c.apisixConsumerController.workqueue.Add(&types.Event{
+			Type:   types.EventUpdate,
+			Object: key,
+		})
+	}
+}
+
+func hasClusterSelector(obj metav1.Object) bool {
+	_, ok := obj.GetAnnotations()[_clusterSelectorAnnotation]
+	return ok
+}
+
+func containsCluster(clusters []string, name string) bool {
+	for _, cluster := range clusters {
+		if cluster == name {
+			return true
+		}
+	}
+	return false
+}
+
+// intersectClusters returns true if there are common clusters in a and b.
+func intersectClusters(a, b []string) bool {
+	for _, name := range a {
+		if containsCluster(b, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// unionClusters returns the sorted union of the cluster names.
+func unionClusters(a, b []string) []string {
+	union := make([]string, 0, len(a)+len(b))
+	union = append(union, a...)
+	for _, name := range b {
+		if !containsCluster(union, name) {
+			union = append(union, name)
+		}
+	}
+	sort.Strings(union)
+	return union
+}
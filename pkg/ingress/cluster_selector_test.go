@@ -0,0 +1,131 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package ingress
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/apache/apisix-ingress-controller/pkg/apisix"
+	"github.com/apache/apisix-ingress-controller/pkg/config"
+	configv2alpha1 "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/apis/config/v2alpha1"
+	listersv2alpha1 "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/client/listers/config/v2alpha1"
+)
+
+func (f *fakeAPISIX) DeleteCluster(name string) error {
+	f.deleted = append(f.deleted, name)
+	return nil
+}
+
+func newClusterSelectorController(t *testing.T) *Controller {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	accs := []*configv2alpha1.ApisixClusterConfig{
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   "default",
+				Labels: map[string]string{"traffic": "internal"},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   "external",
+				Labels: map[string]string{"traffic": "external"},
+			},
+			Spec: configv2alpha1.ApisixClusterConfigSpec{
+				Admin: &configv2alpha1.ApisixClusterAdminConfig{
+					BaseURL: "http://apisix-external:9180/apisix/admin",
+				},
+			},
+		},
+		{
+			// No admin config, not a cluster.
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   "staging",
+				Labels: map[string]string{"traffic": "external"},
+			},
+		},
+	}
+	for _, acc := range accs {
+		assert.Nil(t, indexer.Add(acc))
+	}
+	return &Controller{
+		cfg: &config.Config{
+			APISIX: config.APISIXConfig{
+				DefaultClusterName: "default",
+			},
+		},
+		apisixClusterConfigLister: listersv2alpha1.NewApisixClusterConfigLister(indexer),
+		sourceClusters:            new(sync.Map),
+		upstreamIndex:             newUpstreamIndex(),
+		adminKeySources:           new(sync.Map),
+	}
+}
+
+func TestSelectClusters(t *testing.T) {
+	c := newClusterSelectorController(t)
+	assert.Equal(t, []string{"default", "external"}, c.clusterNames())
+
+	obj := &metav1.ObjectMeta{}
+	clusters, err := c.selectClusters(obj)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"default"}, clusters)
+
+	obj.Annotations = map[string]string{_clusterSelectorAnnotation: "traffic=external"}
+	clusters, err = c.selectClusters(obj)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"external"}, clusters)
+
+	obj.Annotations[_clusterSelectorAnnotation] = "traffic in (internal, external)"
+	clusters, err = c.selectClusters(obj)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"default", "external"}, clusters)
+
+	obj.Annotations[_clusterSelectorAnnotation] = "traffic=canary"
+	clusters, err = c.selectClusters(obj)
+	assert.Nil(t, err)
+	assert.Len(t, clusters, 0)
+
+	obj.Annotations[_clusterSelectorAnnotation] = "traffic in ("
+	_, err = c.selectClusters(obj)
+	assert.NotNil(t, err)
+}
+
+func TestForgetCluster(t *testing.T) {
+	c := newClusterSelectorController(t)
+	cli := &fakeAPISIX{}
+	c.apisix = cli
+
+	c.placeSource("Ingress/default/foo", []string{"default", "external"})
+	c.placeSource("ApisixRoute/default/bar", []string{"external"})
+	c.upstreamIndex.set("external", "Ingress/default/foo", []string{"u1"})
+	c.watchAdminKey(&config.SecretKeyRef{Namespace: "apisix", Name: "admin-key", Key: "key"},
+		&apisix.ClusterOptions{Name: "external"})
+
+	c.forgetCluster("external")
+	assert.Equal(t, []string{"external"}, cli.deleted)
+	assert.Equal(t, []string{"default"}, c.placedClusters("Ingress/default/foo"))
+	assert.Nil(t, c.placedClusters("ApisixRoute/default/bar"))
+	assert.Nil(t, c.upstreamIndex.referencedBy("external", "u1"))
+	assert.False(t, c.isAdminKeySecret("apisix", "admin-key"))
+}
+
+func TestUnionClusters(t *testing.T) {
+	assert.Equal(t, []string{}, unionClusters(nil, nil))
+	assert.Equal(t, []string{"a", "b", "c"}, unionClusters([]string{"c", "a"}, []string{"b", "a"}))
+}
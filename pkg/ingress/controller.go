@@ -17,6 +17,7 @@ package ingress
 import (
 	"context"
 	"fmt"
+	"io/ioutil"
 	"os"
 	"sync"
 	"time"
@@ -27,6 +28,7 @@ import (
 	v1 "k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/client-go/kubernetes/scheme"
@@ -71,6 +73,9 @@ type Controller struct {
 	cfg               *config.Config
 	wg                sync.WaitGroup
 	watchingNamespace map[string]struct{}
+	// namespaceSelector selects the watched namespaces by their labels,
+	// nil means namespaces are not filtered by labels.
+	namespaceSelector labels.Selector
 	apisix            apisix.APISIX
 	podCache          types.PodCache
 	translator        translation.Translator
@@ -79,13 +84,27 @@ type Controller struct {
 	kubeClient        *kube.KubeClient
 	// recorder event
 	recorder record.EventRecorder
-	// this map enrolls which ApisixTls objects refer to a Kubernetes
-	// Secret object.
-	secretSSLMap *sync.Map
+	// adminKeySources records the Secrets which store admin keys of
+	// APISIX clusters, cluster name -> *adminKeySource.
+	adminKeySources *sync.Map
+	// upstreamIndex records which objects reference an upstream.
+	upstreamIndex *upstreamIndex
+	// dependencyIndex records which objects reference a Service, an
+	// ApisixUpstream or a Secret.
+	dependencyIndex *dependencyIndex
+	// sourceClusters records the APISIX clusters which resources of
+	// objects are pushed to, source key -> cluster names.
+	sourceClusters *sync.Map
 
 	// leaderContextCancelFunc will be called when apisix-ingress-controller
 	// decides to give up its leader role.
 	leaderContextCancelFunc context.CancelFunc
+	// cfgLock protects the config items which can be reloaded at runtime
+	// and the derived watchingNamespace, see Reload.
+	cfgLock sync.RWMutex
+	// leading is true once informers and resource controllers are
+	// initialized for the current leader term, protected by cfgLock.
+	leading bool
 
 	// common informers and listers
 	podInformer                 cache.SharedIndexInformer
@@ -96,6 +115,8 @@ type Controller struct {
 	svcLister                   listerscorev1.ServiceLister
 	ingressLister               kube.IngressLister
 	ingressInformer             cache.SharedIndexInformer
+	ingressClassInformer        cache.SharedIndexInformer
+	namespaceInformer           cache.SharedIndexInformer
 	secretInformer              cache.SharedIndexInformer
 	secretLister                listerscorev1.SecretLister
 	apisixUpstreamInformer      cache.SharedIndexInformer
@@ -121,6 +142,8 @@ type Controller struct {
 	apisixTlsController           *apisixTlsController
 	apisixClusterConfigController *apisixClusterConfigController
 	apisixConsumerController      *apisixConsumerController
+	statusController              *statusController
+	ingressStatusController       *ingressStatusController
 }
 
 // NewController creates an ingress apisix controller object.
@@ -145,13 +168,11 @@ func NewController(cfg *config.Config) (*Controller, error) {
 		return nil, err
 	}
 
-	var (
-		watchingNamespace map[string]struct{}
-	)
-	if len(cfg.Kubernetes.AppNamespaces) > 1 || cfg.Kubernetes.AppNamespaces[0] != v1.NamespaceAll {
-		watchingNamespace = make(map[string]struct{}, len(cfg.Kubernetes.AppNamespaces))
-		for _, ns := range cfg.Kubernetes.AppNamespaces {
-			watchingNamespace[ns] = struct{}{}
+	var namespaceSelector labels.Selector
+	if cfg.Kubernetes.NamespaceSelector != "" {
+		namespaceSelector, err = labels.Parse(cfg.Kubernetes.NamespaceSelector)
+		if err != nil {
+			return nil, err
 		}
 	}
 
@@ -168,8 +189,12 @@ func NewController(cfg *config.Config) (*Controller, error) {
 		apisix:            client,
 		metricsCollector:  metrics.NewPrometheusCollector(podName, podNamespace),
 		kubeClient:        kubeClient,
-		watchingNamespace: watchingNamespace,
-		secretSSLMap:      new(sync.Map),
+		watchingNamespace: newWatchingNamespace(cfg.Kubernetes.AppNamespaces),
+		namespaceSelector: namespaceSelector,
+		adminKeySources:   new(sync.Map),
+		upstreamIndex:     newUpstreamIndex(),
+		dependencyIndex:   newDependencyIndex(),
+		sourceClusters:    new(sync.Map),
 		recorder:          eventBroadcaster.NewRecorder(scheme.Scheme, v1.EventSource{Component: _component}),
 
 		podCache: types.NewPodCache(),
@@ -211,8 +236,11 @@ func (c *Controller) initWhenStartLeading() {
 		EndpointLister:       c.epLister,
 		ServiceLister:        c.svcLister,
 		ApisixUpstreamLister: c.apisixUpstreamLister,
-		SecretLister:         c.secretLister,
-		UseEndpointSlices:    c.cfg.Kubernetes.WatchEndpointSlices,
+		ApisixUpstreamFilter: func(au *configv1.ApisixUpstream) bool {
+			return c.isApisixObjectEffective(au)
+		},
+		SecretLister:      c.secretLister,
+		UseEndpointSlices: c.cfg.Kubernetes.WatchEndpointSlices,
 	})
 
 	if c.cfg.Kubernetes.IngressVersion == config.IngressNetworkingV1 {
@@ -222,6 +250,13 @@ func (c *Controller) initWhenStartLeading() {
 	} else {
 		ingressInformer = kubeFactory.Extensions().V1beta1().Ingresses().Informer()
 	}
+	// IngressClass is not served with the extensions/v1beta1 Ingress.
+	switch c.cfg.Kubernetes.IngressVersion {
+	case config.IngressNetworkingV1:
+		c.ingressClassInformer = kubeFactory.Networking().V1().IngressClasses().Informer()
+	case config.IngressNetworkingV1beta1:
+		c.ingressClassInformer = kubeFactory.Networking().V1beta1().IngressClasses().Informer()
+	}
 	switch c.cfg.Kubernetes.ApisixRouteVersion {
 	case config.ApisixRouteV1:
 		apisixRouteInformer = apisixFactory.Apisix().V1().ApisixRoutes().Informer()
@@ -231,6 +266,9 @@ func (c *Controller) initWhenStartLeading() {
 		apisixRouteInformer = apisixFactory.Apisix().V2beta1().ApisixRoutes().Informer()
 	}
 
+	if c.namespaceSelector != nil {
+		c.namespaceInformer = kubeFactory.Core().V1().Namespaces().Informer()
+	}
 	c.podInformer = kubeFactory.Core().V1().Pods().Informer()
 	c.svcInformer = kubeFactory.Core().V1().Services().Informer()
 	c.ingressInformer = ingressInformer
@@ -254,22 +292,29 @@ func (c *Controller) initWhenStartLeading() {
 	c.apisixTlsController = c.newApisixTlsController()
 	c.secretController = c.newSecretController()
 	c.apisixConsumerController = c.newApisixConsumerController()
+	c.statusController = c.newStatusController()
+	c.ingressStatusController = c.newIngressStatusController()
+
+	c.watchServices()
+	c.watchIngressClasses()
+	c.watchNamespaces()
 }
 
 // recorderEvent recorder events for resources
 func (c *Controller) recorderEvent(object runtime.Object, eventtype, reason string, err error) {
 	if err != nil {
 		message := fmt.Sprintf(_messageResourceFailed, _component, err.Error())
-		c.recorder.Event(object, eventtype, reason, message)
+		c.recorderEventS(object, eventtype, reason, message)
 	} else {
 		message := fmt.Sprintf(_messageResourceSynced, _component)
-		c.recorder.Event(object, eventtype, reason, message)
+		c.recorderEventS(object, eventtype, reason, message)
 	}
 }
 
-// recorderEvent recorder events for resources
+// recorderEvent recorder events for resources, sensitive values in the
+// message (e.g. echoed by the Admin API) are redacted.
 func (c *Controller) recorderEventS(object runtime.Object, eventtype, reason string, msg string) {
-	c.recorder.Event(object, eventtype, reason, msg)
+	c.recorder.Event(object, eventtype, reason, log.Redact(msg))
 }
 
 func (c *Controller) goAttach(handler func()) {
@@ -374,17 +419,20 @@ func (c *Controller) run(ctx context.Context) {
 	// give up leader
 	defer c.leaderContextCancelFunc()
 
-	clusterOpts := &apisix.ClusterOptions{
-		Name:     c.cfg.APISIX.DefaultClusterName,
-		AdminKey: c.cfg.APISIX.DefaultClusterAdminKey,
-		BaseURL:  c.cfg.APISIX.DefaultClusterBaseURL,
+	clusterOpts, err := c.defaultClusterOptions(ctx)
+	if err != nil {
+		log.Errorf("failed to load options of default cluster: %s", err)
+		return
 	}
-	err := c.apisix.AddCluster(clusterOpts)
+	err = c.apisix.AddCluster(clusterOpts)
 	if err != nil && err != apisix.ErrDuplicatedCluster {
 		// TODO give up the leader role
 		log.Errorf("failed to add default cluster: %s", err)
 		return
 	}
+	if ref := c.apisixConfig().DefaultClusterAdminKeySecretRef; !ref.IsEmpty() {
+		c.watchAdminKey(&ref, clusterOpts)
+	}
 
 	if err := c.apisix.Cluster(c.cfg.APISIX.DefaultClusterName).HasSynced(ctx); err != nil {
 		// TODO give up the leader role
@@ -399,7 +447,19 @@ func (c *Controller) run(ctx context.Context) {
 	}
 
 	c.initWhenStartLeading()
-
+	c.setLeading(true)
+	defer c.setLeading(false)
+
+	if c.namespaceInformer != nil {
+		// Other informers depend on it to filter events.
+		c.goAttach(func() {
+			c.namespaceInformer.Run(ctx.Done())
+		})
+		if !cache.WaitForCacheSync(ctx.Done(), c.namespaceInformer.HasSynced) {
+			log.Error("namespace cache sync failed")
+			return
+		}
+	}
 	c.goAttach(func() {
 		c.checkClusterHealth(ctx, cancelFunc)
 	})
@@ -415,6 +475,11 @@ func (c *Controller) run(ctx context.Context) {
 	c.goAttach(func() {
 		c.ingressInformer.Run(ctx.Done())
 	})
+	if c.ingressClassInformer != nil {
+		c.goAttach(func() {
+			c.ingressClassInformer.Run(ctx.Done())
+		})
+	}
 	c.goAttach(func() {
 		c.apisixRouteInformer.Run(ctx.Done())
 	})
@@ -464,6 +529,17 @@ func (c *Controller) run(ctx context.Context) {
 	c.goAttach(func() {
 		c.apisixConsumerController.run(ctx)
 	})
+	c.goAttach(func() {
+		c.statusController.run(ctx)
+	})
+	if c.ingressStatusController != nil {
+		c.goAttach(func() {
+			c.ingressStatusController.run(ctx)
+		})
+	}
+	c.goAttach(func() {
+		c.runReconciler(ctx)
+	})
 
 	c.metricsCollector.ResetLeader(true)
 
@@ -479,7 +555,10 @@ func (c *Controller) run(ctx context.Context) {
 // namespaceWatching accepts a resource key, getting the namespace part
 // and checking whether the namespace is being watched.
 func (c *Controller) namespaceWatching(key string) (ok bool) {
-	if c.watchingNamespace == nil {
+	c.cfgLock.RLock()
+	all := c.watchingNamespace == nil
+	c.cfgLock.RUnlock()
+	if all && c.namespaceSelector == nil {
 		ok = true
 		return
 	}
@@ -490,35 +569,28 @@ func (c *Controller) namespaceWatching(key string) (ok bool) {
 		log.Warnf("resource %s was ignored since: %s", key, err)
 		return
 	}
-	_, ok = c.watchingNamespace[ns]
+	ok = c.isNamespaceWatched(ns)
 	return
 }
 
-func (c *Controller) syncSSL(ctx context.Context, ssl *apisixv1.Ssl, event types.EventType) error {
-	var (
-		err error
-	)
-	clusterName := c.cfg.APISIX.DefaultClusterName
+// syncSSL pushes the SSL of the source object to the clusters, it's
+// deleted on delete event.
+func (c *Controller) syncSSL(ctx context.Context, source string, clusters []string, ssl *apisixv1.Ssl, event types.EventType) error {
+	m := &manifest{ssls: []*apisixv1.Ssl{ssl}}
 	if event == types.EventDelete {
-		err = c.apisix.Cluster(clusterName).SSL().Delete(ctx, ssl)
-	} else if event == types.EventUpdate {
-		_, err = c.apisix.Cluster(clusterName).SSL().Update(ctx, ssl)
-	} else {
-		_, err = c.apisix.Cluster(clusterName).SSL().Create(ctx, ssl)
+		return c.syncSourceManifests(ctx, source, clusters, m, nil)
 	}
-	return err
+	return c.syncSourceManifests(ctx, source, clusters, nil, m)
 }
 
-func (c *Controller) syncConsumer(ctx context.Context, consumer *apisixv1.Consumer, event types.EventType) (err error) {
-	clusterName := c.cfg.APISIX.DefaultClusterName
+// syncConsumer pushes the consumer of the source object to the clusters,
+// it's deleted on delete event.
+func (c *Controller) syncConsumer(ctx context.Context, source string, clusters []string, consumer *apisixv1.Consumer, event types.EventType) error {
+	m := &manifest{consumers: []*apisixv1.Consumer{consumer}}
 	if event == types.EventDelete {
-		err = c.apisix.Cluster(clusterName).Consumer().Delete(ctx, consumer)
-	} else if event == types.EventUpdate {
-		_, err = c.apisix.Cluster(clusterName).Consumer().Update(ctx, consumer)
-	} else {
-		_, err = c.apisix.Cluster(clusterName).Consumer().Create(ctx, consumer)
+		return c.syncSourceManifests(ctx, source, clusters, m, nil)
 	}
-	return
+	return c.syncSourceManifests(ctx, source, clusters, nil, m)
 }
 
 func (c *Controller) syncEndpoint(ctx context.Context, ep kube.Endpoint) error {
@@ -545,7 +617,7 @@ func (c *Controller) syncEndpoint(ctx context.Context, ep kube.Endpoint) error {
 		subsets = append(subsets, au.Spec.Subsets...)
 	}
 
-	clusters := c.apisix.ListClusters()
+	clusters := c.clusterNames()
 	for _, port := range svc.Spec.Ports {
 		for _, subset := range subsets {
 			nodes, err := c.translator.TranslateUpstreamNodes(ep, port.Port, subset.Labels)
@@ -567,7 +639,8 @@ func (c *Controller) syncEndpoint(ctx context.Context, ep kube.Endpoint) error {
 	return nil
 }
 
-func (c *Controller) syncUpstreamNodesChangeToCluster(ctx context.Context, cluster apisix.Cluster, nodes apisixv1.UpstreamNodes, upsName string) error {
+func (c *Controller) syncUpstreamNodesChangeToCluster(ctx context.Context, clusterName string, nodes apisixv1.UpstreamNodes, upsName string) error {
+	cluster := c.apisix.Cluster(clusterName)
 	upstream, err := cluster.Upstream().Get(ctx, upsName)
 	if err != nil {
 		if err == apisixcache.ErrNotFound {
@@ -596,7 +669,7 @@ func (c *Controller) syncUpstreamNodesChangeToCluster(ctx context.Context, clust
 	updated := &manifest{
 		upstreams: []*apisixv1.Upstream{upstream},
 	}
-	return c.syncManifests(ctx, nil, updated, nil)
+	return c.syncManifests(ctx, clusterName, nil, updated, nil)
 }
 
 func (c *Controller) checkClusterHealth(ctx context.Context, cancelFunc context.CancelFunc) {
@@ -617,3 +690,38 @@ func (c *Controller) checkClusterHealth(ctx context.Context, cancelFunc context.
 		log.Debugf("success check health for default cluster")
 	}
 }
+
+// defaultClusterOptions returns the options of the default APISIX cluster,
+// certificates are loaded from the files specified in the configuration,
+// and the admin key is loaded from the Secret if it's referenced.
+func (c *Controller) defaultClusterOptions(ctx context.Context) (*apisix.ClusterOptions, error) {
+	cfg := c.apisixConfig()
+	opts := &apisix.ClusterOptions{
+		Name:               cfg.DefaultClusterName,
+		AdminKey:           cfg.DefaultClusterAdminKey,
+		BaseURL:            cfg.DefaultClusterBaseURL,
+		ServerName:         cfg.DefaultClusterServerName,
+		InsecureSkipVerify: cfg.DefaultClusterInsecureSkipVerify,
+		MetricsCollector:   c.metricsCollector,
+	}
+	var err error
+	if ref := cfg.DefaultClusterAdminKeySecretRef; !ref.IsEmpty() {
+		if opts.AdminKey, err = c.loadAdminKey(ctx, &ref); err != nil {
+			return nil, err
+		}
+	}
+	if cfg.DefaultClusterCACert != "" {
+		if opts.CACert, err = ioutil.ReadFile(cfg.DefaultClusterCACert); err != nil {
+			return nil, err
+		}
+	}
+	if cfg.DefaultClusterClientCert != "" {
+		if opts.ClientCert, err = ioutil.ReadFile(cfg.DefaultClusterClientCert); err != nil {
+			return nil, err
+		}
+		if opts.ClientKey, err = ioutil.ReadFile(cfg.DefaultClusterClientKey); err != nil {
+			return nil, err
+		}
+	}
+	return opts, nil
+}
@@ -0,0 +1,170 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package ingress
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/apache/apisix-ingress-controller/pkg/kube"
+	"github.com/apache/apisix-ingress-controller/pkg/kube/translation"
+	"github.com/apache/apisix-ingress-controller/pkg/types"
+)
+
+const (
+	// _defaultBackendConflict is used when the default backend of an Ingress
+	// is shadowed by another Ingress.
+	_defaultBackendConflict = "DefaultBackendConflict"
+	// _dependencyKindDefaultBackend is the kind of default backend scopes in
+	// the dependency index, so that Ingresses are re-resolved once scopes of
+	// others change.
+	_dependencyKindDefaultBackend = "IngressDefaultBackend"
+	// _globalScope is the scope of default backends of Ingresses without
+	// hosts.
+	_globalScope = "*"
+)
+
+// defaultBackendScopes returns the scopes of the Ingress default backend,
+// i.e. hosts of its rules, or the global scope if there are none.
+func defaultBackendScopes(ing kube.Ingress) []string {
+	hosts, ok := translation.IngressDefaultBackendScope(ing)
+	if !ok {
+		return nil
+	}
+	if len(hosts) == 0 {
+		return []string{_globalScope}
+	}
+	return hosts
+}
+
+// precedes returns true if Ingress a takes precedence over Ingress b on the
+// default backend scopes and the TLS hosts they share, the older one wins
+// and ties are broken by their keys.
+func precedes(a kube.Ingress, aKey string, b kube.Ingress, bKey string) bool {
+	at := ingressObject(a).GetCreationTimestamp()
+	bt := ingressObject(b).GetCreationTimestamp()
+	if !at.Equal(&bt) {
+		return at.Before(&bt)
+	}
+	return aKey < bKey
+}
+
+// resolveDefaultBackend removes scopes of the default backend route which
+// are taken by other Ingresses from the translate context, the route is
+// dropped if no scope is left. The conflicts are returned.
+func (c *ingressController) resolveDefaultBackend(ing kube.Ingress, key string, tctx *translation.TranslateContext) []string {
+	scopes := defaultBackendScopes(ing)
+	if len(scopes) == 0 {
+		return nil
+	}
+	// scope -> key of the Ingress which takes it.
+	taken := make(map[string]string)
+	c.forEachPrecedingIngress(ing, key, func(other kube.Ingress, otherKey string) {
+		for _, scope := range defaultBackendScopes(other) {
+			if prev, ok := taken[scope]; !ok || otherKey < prev {
+				taken[scope] = otherKey
+			}
+		}
+	})
+	if len(taken) == 0 {
+		return nil
+	}
+
+	var (
+		kept      []string
+		conflicts []string
+	)
+	for _, scope := range scopes {
+		if winner, ok := taken[scope]; ok {
+			conflicts = append(conflicts, fmt.Sprintf("%s (taken by Ingress %s)", scope, winner))
+		} else {
+			kept = append(kept, scope)
+		}
+	}
+	if len(conflicts) == 0 {
+		return nil
+	}
+
+	name := translation.ComposeIngressDefaultBackendRouteName(ingressObject(ing).GetNamespace(), ingressObject(ing).GetName())
+	routes := tctx.Routes[:0]
+	for _, r := range tctx.Routes {
+		if r.Name == name {
+			if len(kept) == 0 {
+				continue
+			}
+			if kept[0] != _globalScope {
+				r.Hosts = kept
+			}
+		}
+		routes = append(routes, r)
+	}
+	tctx.Routes = routes
+	return conflicts
+}
+
+// forEachPrecedingIngress calls fn with the other effective Ingresses which
+// take precedence over the Ingress, only the ones pushed to the same APISIX
+// clusters are taken into account as they can't conflict otherwise.
+func (c *ingressController) forEachPrecedingIngress(ing kube.Ingress, key string, fn func(kube.Ingress, string)) {
+	clusters, err := c.controller.selectClusters(ingressObject(ing))
+	if err != nil {
+		return
+	}
+	for _, obj := range c.controller.ingressInformer.GetStore().List() {
+		otherKey, err := cache.MetaNamespaceKeyFunc(obj)
+		if err != nil || otherKey == key || !c.controller.namespaceWatching(otherKey) {
+			continue
+		}
+		other, err := kube.NewIngress(obj)
+		if err != nil || !c.isIngressEffective(other) || !precedes(other, otherKey, ing, key) {
+			continue
+		}
+		otherClusters, err := c.controller.selectClusters(ingressObject(other))
+		if err != nil || !intersectClusters(clusters, otherClusters) {
+			continue
+		}
+		fn(other, otherKey)
+	}
+}
+
+// resyncIngressScopes enqueues Ingresses sharing the default backend scopes
+// or the TLS hosts which are added to or removed from the Ingress, as the
+// conflicts between them should be resolved again.
+func (c *Controller) resyncIngressScopes(dependent string, prev, curr []string) {
+	changed := make(map[string]struct{})
+	for _, dep := range prev {
+		changed[dep] = struct{}{}
+	}
+	for _, dep := range curr {
+		if _, ok := changed[dep]; ok {
+			delete(changed, dep)
+		} else {
+			changed[dep] = struct{}{}
+		}
+	}
+	for dep := range changed {
+		if !strings.HasPrefix(dep, _dependencyKindDefaultBackend+"/") && !strings.HasPrefix(dep, _dependencyKindTLSHost+"/") {
+			continue
+		}
+		for _, other := range c.dependencyIndex.dependentsOf(dep) {
+			if other == dependent || !strings.HasPrefix(other, translation.SourceKindIngress+"/") {
+				continue
+			}
+			c.ingressController.resync(strings.TrimPrefix(other, translation.SourceKindIngress+"/"), types.EventAdd)
+		}
+	}
+}
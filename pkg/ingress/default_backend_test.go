@@ -0,0 +1,156 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package ingress
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+
+	apisixcache "github.com/apache/apisix-ingress-controller/pkg/apisix/cache"
+	"github.com/apache/apisix-ingress-controller/pkg/config"
+	"github.com/apache/apisix-ingress-controller/pkg/kube"
+	"github.com/apache/apisix-ingress-controller/pkg/kube/translation"
+	apisixv1 "github.com/apache/apisix-ingress-controller/pkg/types/apisix/v1"
+)
+
+func newDefaultBackendIngress(name string, created time.Time, hosts ...string) *networkingv1.Ingress {
+	ing := &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         "default",
+			CreationTimestamp: metav1.NewTime(created),
+			Annotations: map[string]string{
+				_ingressKey: "apisix",
+			},
+		},
+		Spec: networkingv1.IngressSpec{
+			DefaultBackend: &networkingv1.IngressBackend{
+				Service: &networkingv1.IngressServiceBackend{Name: "fallback"},
+			},
+		},
+	}
+	for _, host := range hosts {
+		ing.Spec.Rules = append(ing.Spec.Rules, networkingv1.IngressRule{Host: host})
+	}
+	return ing
+}
+
+func TestResolveDefaultBackend(t *testing.T) {
+	now := time.Now()
+	informer := cache.NewSharedIndexInformer(&cache.ListWatch{}, &networkingv1.Ingress{}, 0, cache.Indexers{})
+	c := &ingressController{
+		controller: &Controller{
+			cfg:             config.NewDefaultConfig(),
+			ingressInformer: informer,
+		},
+	}
+	resolve := func(ing *networkingv1.Ingress) ([]string, *translation.TranslateContext) {
+		hosts, _ := translation.IngressDefaultBackendScope(kube.MustNewIngress(ing))
+		route := apisixv1.NewDefaultRoute()
+		route.Name = translation.ComposeIngressDefaultBackendRouteName(ing.Namespace, ing.Name)
+		route.Hosts = hosts
+		tctx := &translation.TranslateContext{
+			Routes: []*apisixv1.Route{route},
+		}
+		conflicts := c.resolveDefaultBackend(kube.MustNewIngress(ing), ing.Namespace+"/"+ing.Name, tctx)
+		return conflicts, tctx
+	}
+
+	old := newDefaultBackendIngress("old", now.Add(-time.Hour), "foo.com")
+	global := newDefaultBackendIngress("global", now.Add(-time.Hour))
+	assert.Nil(t, informer.GetStore().Add(old))
+	assert.Nil(t, informer.GetStore().Add(global))
+
+	// The older Ingress keeps its scopes.
+	conflicts, tctx := resolve(old)
+	assert.Nil(t, conflicts)
+	assert.Equal(t, []string{"foo.com"}, tctx.Routes[0].Hosts)
+
+	// Only the conflicted host is removed.
+	ing := newDefaultBackendIngress("new", now, "foo.com", "bar.com")
+	conflicts, tctx = resolve(ing)
+	assert.Equal(t, []string{"foo.com (taken by Ingress default/old)"}, conflicts)
+	assert.Equal(t, []string{"bar.com"}, tctx.Routes[0].Hosts)
+
+	// The global scope is taken, the route is dropped.
+	ing = newDefaultBackendIngress("new", now)
+	conflicts, tctx = resolve(ing)
+	assert.Equal(t, []string{"* (taken by Ingress default/global)"}, conflicts)
+	assert.Len(t, tctx.Routes, 0)
+
+	// Ties are broken by keys.
+	ing = newDefaultBackendIngress("aaa", now.Add(-time.Hour))
+	conflicts, tctx = resolve(ing)
+	assert.Nil(t, conflicts)
+	assert.Len(t, tctx.Routes, 1)
+}
+
+func TestResolveDefaultBackendPerCluster(t *testing.T) {
+	now := time.Now()
+	c := newClusterSelectorController(t)
+	cli := &fakeAPISIX{
+		clusters: map[string]*fakeCluster{
+			"default":  newFakeCluster(t),
+			"external": newFakeCluster(t),
+		},
+	}
+	c.apisix = cli
+	c.cfg.Kubernetes = config.NewDefaultConfig().Kubernetes
+	informer := cache.NewSharedIndexInformer(&cache.ListWatch{}, &networkingv1.Ingress{}, 0, cache.Indexers{})
+	c.ingressInformer = informer
+	ic := &ingressController{controller: c}
+
+	external := newDefaultBackendIngress("external", now.Add(-time.Hour), "foo.com")
+	external.Annotations[_clusterSelectorAnnotation] = "traffic=external"
+	assert.Nil(t, informer.GetStore().Add(external))
+	ing := newDefaultBackendIngress("new", now, "foo.com")
+	newTranslateContext := func() *translation.TranslateContext {
+		route := apisixv1.NewDefaultRoute()
+		route.ID = "default-backend"
+		route.Name = translation.ComposeIngressDefaultBackendRouteName(ing.Namespace, ing.Name)
+		route.Hosts = []string{"foo.com"}
+		route.Labels = map[string]string{
+			apisixv1.LabelSourceKind:      "Ingress",
+			apisixv1.LabelSourceNamespace: "default",
+			apisixv1.LabelSourceName:      "new",
+		}
+		return &translation.TranslateContext{Routes: []*apisixv1.Route{route}}
+	}
+
+	// The Ingresses are pushed to different clusters, no conflicts.
+	tctx := newTranslateContext()
+	assert.Nil(t, ic.resolveDefaultBackend(kube.MustNewIngress(ing), "default/new", tctx))
+	assert.Len(t, tctx.Routes, 1)
+	source := sourceKey("Ingress", "default/new")
+	ctx := context.Background()
+	assert.Nil(t, c.syncSourceManifests(ctx, source, []string{"default"}, nil, &manifest{routes: tctx.Routes}))
+
+	// The older Ingress is moved to the default cluster, the default
+	// backend route of the newer one is deleted on resync.
+	delete(external.Annotations, _clusterSelectorAnnotation)
+	assert.Nil(t, informer.GetStore().Update(external))
+	tctx = newTranslateContext()
+	assert.Len(t, ic.resolveDefaultBackend(kube.MustNewIngress(ing), "default/new", tctx), 1)
+	assert.Len(t, tctx.Routes, 0)
+	assert.Nil(t, c.syncSourceManifests(ctx, source, []string{"default"}, nil, &manifest{routes: tctx.Routes}))
+	_, err := cli.clusters["default"].cache.GetRoute("default-backend")
+	assert.Equal(t, apisixcache.ErrNotFound, err)
+}
@@ -0,0 +1,368 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package ingress
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/apache/apisix-ingress-controller/pkg/kube"
+	configv1 "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/apis/config/v1"
+	configv2alpha1 "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/apis/config/v2alpha1"
+	"github.com/apache/apisix-ingress-controller/pkg/kube/translation"
+	"github.com/apache/apisix-ingress-controller/pkg/log"
+	"github.com/apache/apisix-ingress-controller/pkg/types"
+)
+
+const (
+	_dependencyKindService        = "Service"
+	_dependencyKindApisixUpstream = "ApisixUpstream"
+	_dependencyKindSecret         = "Secret"
+
+	// _dependentKindApisixClusterConfig is the kind of ApisixClusterConfigs
+	// in the index, they depend on the Secrets of the Admin API TLS settings.
+	_dependentKindApisixClusterConfig = "ApisixClusterConfig"
+)
+
+// dependencyIndex records the Services, ApisixUpstreams and Secrets which
+// are referenced by Ingresses, ApisixRoutes, ApisixTlses and ApisixConsumers,
+// so that they can be translated again once their dependencies change.
+type dependencyIndex struct {
+	sync.RWMutex
+	// dependency key -> dependent keys
+	dependents map[string]map[string]struct{}
+	// dependent key -> dependency keys
+	dependencies map[string]map[string]struct{}
+}
+
+func newDependencyIndex() *dependencyIndex {
+	return &dependencyIndex{
+		dependents:   make(map[string]map[string]struct{}),
+		dependencies: make(map[string]map[string]struct{}),
+	}
+}
+
+// set replaces the dependencies of the dependent, an empty deps removes
+// the dependent from the index.
+func (idx *dependencyIndex) set(dependent string, deps []string) {
+	idx.Lock()
+	defer idx.Unlock()
+
+	for dep := range idx.dependencies[dependent] {
+		delete(idx.dependents[dep], dependent)
+		if len(idx.dependents[dep]) == 0 {
+			delete(idx.dependents, dep)
+		}
+	}
+	delete(idx.dependencies, dependent)
+	if len(deps) == 0 {
+		return
+	}
+
+	owned := make(map[string]struct{}, len(deps))
+	for _, dep := range deps {
+		owned[dep] = struct{}{}
+		if idx.dependents[dep] == nil {
+			idx.dependents[dep] = make(map[string]struct{})
+		}
+		idx.dependents[dep][dependent] = struct{}{}
+	}
+	idx.dependencies[dependent] = owned
+}
+
+// dependentsOf returns the sorted dependent keys of the dependency.
+func (idx *dependencyIndex) dependentsOf(dep string) []string {
+	idx.RLock()
+	defer idx.RUnlock()
+
+	var dependents []string
+	for dependent := range idx.dependents[dep] {
+		dependents = append(dependents, dependent)
+	}
+	sort.Strings(dependents)
+	return dependents
+}
+
+// dependenciesOf returns the sorted dependency keys of the dependent.
+func (idx *dependencyIndex) dependenciesOf(dependent string) []string {
+	idx.RLock()
+	defer idx.RUnlock()
+
+	var deps []string
+	for dep := range idx.dependencies[dependent] {
+		deps = append(deps, dep)
+	}
+	sort.Strings(deps)
+	return deps
+}
+
+// serviceDependencies returns keys of the Services and the ApisixUpstreams
+// with the same names, as the latter decorate upstreams of the Services.
+func serviceDependencies(namespace string, services []string) []string {
+	var deps []string
+	for _, svc := range services {
+		if svc == "" {
+			continue
+		}
+		key := namespace + "/" + svc
+		deps = append(deps,
+			sourceKey(_dependencyKindService, key),
+			sourceKey(_dependencyKindApisixUpstream, key),
+		)
+	}
+	return deps
+}
+
+// ingressDependencies returns keys of objects referenced by the Ingress,
+// and keys of its default backend scopes and TLS hosts shared with others.
+func ingressDependencies(ing kube.Ingress) []string {
+	var (
+		namespace string
+		services  []string
+		secrets   []string
+	)
+	switch ing.GroupVersion() {
+	case kube.IngressV1:
+		obj := ing.V1()
+		namespace = obj.Namespace
+		if b := obj.Spec.DefaultBackend; b != nil && b.Service != nil {
+			services = append(services, b.Service.Name)
+		}
+		for _, rule := range obj.Spec.Rules {
+			if rule.HTTP == nil {
+				continue
+			}
+			for _, path := range rule.HTTP.Paths {
+				if path.Backend.Service != nil {
+					services = append(services, path.Backend.Service.Name)
+				}
+			}
+		}
+		for _, tls := range obj.Spec.TLS {
+			secrets = append(secrets, tls.SecretName)
+		}
+	case kube.IngressV1beta1:
+		obj := ing.V1beta1()
+		namespace = obj.Namespace
+		if b := obj.Spec.Backend; b != nil {
+			services = append(services, b.ServiceName)
+		}
+		for _, rule := range obj.Spec.Rules {
+			if rule.HTTP == nil {
+				continue
+			}
+			for _, path := range rule.HTTP.Paths {
+				services = append(services, path.Backend.ServiceName)
+			}
+		}
+		for _, tls := range obj.Spec.TLS {
+			secrets = append(secrets, tls.SecretName)
+		}
+	default:
+		obj := ing.ExtensionsV1beta1()
+		namespace = obj.Namespace
+		if b := obj.Spec.Backend; b != nil {
+			services = append(services, b.ServiceName)
+		}
+		for _, rule := range obj.Spec.Rules {
+			if rule.HTTP == nil {
+				continue
+			}
+			for _, path := range rule.HTTP.Paths {
+				services = append(services, path.Backend.ServiceName)
+			}
+		}
+		for _, tls := range obj.Spec.TLS {
+			secrets = append(secrets, tls.SecretName)
+		}
+	}
+	deps := serviceDependencies(namespace, services)
+	for _, secret := range secrets {
+		if secret != "" {
+			deps = append(deps, sourceKey(_dependencyKindSecret, namespace+"/"+secret))
+		}
+	}
+	for _, scope := range defaultBackendScopes(ing) {
+		deps = append(deps, sourceKey(_dependencyKindDefaultBackend, scope))
+	}
+	for _, host := range ingressTLSHosts(ing) {
+		deps = append(deps, sourceKey(_dependencyKindTLSHost, host))
+	}
+	return deps
+}
+
+// apisixRouteDependencies returns keys of objects referenced by the
+// ApisixRoute.
+func apisixRouteDependencies(ar kube.ApisixRoute) []string {
+	var (
+		namespace string
+		services  []string
+	)
+	switch ar.GroupVersion() {
+	case kube.ApisixRouteV1:
+		obj := ar.V1()
+		namespace = obj.Namespace
+		if obj.Spec != nil {
+			for _, rule := range obj.Spec.Rules {
+				for _, path := range rule.Http.Paths {
+					services = append(services, path.Backend.ServiceName)
+				}
+			}
+		}
+	case kube.ApisixRouteV2alpha1:
+		obj := ar.V2alpha1()
+		namespace = obj.Namespace
+		for _, part := range obj.Spec.HTTP {
+			if part.Backend != nil {
+				services = append(services, part.Backend.ServiceName)
+			}
+			for _, backend := range part.Backends {
+				services = append(services, backend.ServiceName)
+			}
+		}
+		for _, part := range obj.Spec.TCP {
+			services = append(services, part.Backend.ServiceName)
+		}
+	default:
+		obj := ar.V2beta1()
+		namespace = obj.Namespace
+		for _, part := range obj.Spec.HTTP {
+			services = append(services, part.Backend.ServiceName)
+			for _, backend := range part.Backends {
+				services = append(services, backend.ServiceName)
+			}
+		}
+		for _, part := range obj.Spec.Stream {
+			services = append(services, part.Backend.ServiceName)
+		}
+	}
+	return serviceDependencies(namespace, services)
+}
+
+// apisixTlsDependencies returns keys of Secrets referenced by the ApisixTls.
+func apisixTlsDependencies(tls *configv1.ApisixTls) []string {
+	deps := []string{
+		sourceKey(_dependencyKindSecret, tls.Spec.Secret.Namespace+"/"+tls.Spec.Secret.Name),
+	}
+	if tls.Spec.Client != nil {
+		ca := tls.Spec.Client.CASecret
+		deps = append(deps, sourceKey(_dependencyKindSecret, ca.Namespace+"/"+ca.Name))
+	}
+	return deps
+}
+
+// apisixConsumerDependencies returns keys of Secrets referenced by the
+// ApisixConsumer.
+func apisixConsumerDependencies(ac *configv2alpha1.ApisixConsumer) []string {
+	var deps []string
+	if auth := ac.Spec.AuthParameter.KeyAuth; auth != nil && auth.SecretRef != nil {
+		deps = append(deps, sourceKey(_dependencyKindSecret, ac.Namespace+"/"+auth.SecretRef.Name))
+	}
+	if auth := ac.Spec.AuthParameter.BasicAuth; auth != nil && auth.SecretRef != nil {
+		deps = append(deps, sourceKey(_dependencyKindSecret, ac.Namespace+"/"+auth.SecretRef.Name))
+	}
+	return deps
+}
+
+// apisixClusterConfigDependencies returns keys of Secrets referenced by the
+// Admin API TLS settings of the ApisixClusterConfig.
+func apisixClusterConfigDependencies(acc *configv2alpha1.ApisixClusterConfig) []string {
+	if acc.Spec.Admin == nil || acc.Spec.Admin.TLS == nil {
+		return nil
+	}
+	var deps []string
+	if ref := acc.Spec.Admin.TLS.CASecretRef; ref != nil {
+		deps = append(deps, sourceKey(_dependencyKindSecret, ref.Namespace+"/"+ref.Name))
+	}
+	if ref := acc.Spec.Admin.TLS.ClientCertSecretRef; ref != nil {
+		deps = append(deps, sourceKey(_dependencyKindSecret, ref.Namespace+"/"+ref.Name))
+	}
+	return deps
+}
+
+// resyncDependents enqueues objects which reference the dependency, so that
+// they will be translated again. Resources which are no longer desired (e.g.
+// the upstream of a changed Service port) are deleted as they're diffed with
+// the pushed ones in the cluster caches, see syncSourceManifests.
+func (c *Controller) resyncDependents(dep string) {
+	for _, dependent := range c.dependencyIndex.dependentsOf(dep) {
+		parts := strings.SplitN(dependent, "/", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		log.Debugw("dependency changed, resync its dependent",
+			zap.String("dependency", dep),
+			zap.String("dependent", dependent),
+		)
+		switch parts[0] {
+		case translation.SourceKindIngress:
+			c.ingressController.resync(parts[1], types.EventAdd)
+		case translation.SourceKindApisixRoute:
+			c.apisixRouteController.resync(parts[1], types.EventAdd)
+		case translation.SourceKindApisixTls:
+			c.apisixTlsController.workqueue.Add(&types.Event{
+				Type:   types.EventUpdate,
+				Object: parts[1],
+			})
+		case translation.SourceKindApisixConsumer:
+			c.apisixConsumerController.workqueue.Add(&types.Event{
+				Type:   types.EventUpdate,
+				Object: parts[1],
+			})
+		case _dependentKindApisixClusterConfig:
+			// The cluster client is updated with the new certificates.
+			c.apisixClusterConfigController.workqueue.Add(&types.Event{
+				Type:   types.EventUpdate,
+				Object: parts[1],
+			})
+		}
+	}
+}
+
+// watchServices re-translates dependents of Services once Services are
+// created or deleted, or their ports and cluster IPs are changed.
+func (c *Controller) watchServices() {
+	c.svcInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			c.onDependencyChange(_dependencyKindService, obj)
+		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			prev := oldObj.(*corev1.Service)
+			curr := newObj.(*corev1.Service)
+			if prev.Spec.ClusterIP == curr.Spec.ClusterIP && reflect.DeepEqual(prev.Spec.Ports, curr.Spec.Ports) {
+				return
+			}
+			c.onDependencyChange(_dependencyKindService, newObj)
+		},
+		DeleteFunc: func(obj interface{}) {
+			c.onDependencyChange(_dependencyKindService, obj)
+		},
+	})
+}
+
+func (c *Controller) onDependencyChange(kind string, obj interface{}) {
+	key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
+	if err != nil {
+		log.Errorf("found %s resource with bad meta namespace key: %s", kind, err)
+		return
+	}
+	c.resyncDependents(sourceKey(kind, key))
+}
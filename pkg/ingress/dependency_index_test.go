@@ -0,0 +1,246 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package ingress
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+
+	"github.com/apache/apisix-ingress-controller/pkg/config"
+	"github.com/apache/apisix-ingress-controller/pkg/kube"
+	configv1 "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/apis/config/v1"
+	configv2alpha1 "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/apis/config/v2alpha1"
+	configv2beta1 "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/apis/config/v2beta1"
+	"github.com/apache/apisix-ingress-controller/pkg/kube/translation"
+	"github.com/apache/apisix-ingress-controller/pkg/types"
+)
+
+func TestDependencyIndex(t *testing.T) {
+	idx := newDependencyIndex()
+	ing := sourceKey("Ingress", "default/foo")
+	ar := sourceKey("ApisixRoute", "default/bar")
+
+	idx.set(ing, []string{"Service/default/svc1", "Secret/default/cert"})
+	idx.set(ar, []string{"Service/default/svc1", "Service/default/svc2"})
+	assert.Equal(t, []string{"ApisixRoute/default/bar", "Ingress/default/foo"}, idx.dependentsOf("Service/default/svc1"))
+	assert.Equal(t, []string{"ApisixRoute/default/bar"}, idx.dependentsOf("Service/default/svc2"))
+	assert.Equal(t, []string{"Ingress/default/foo"}, idx.dependentsOf("Secret/default/cert"))
+
+	// The ApisixRoute no longer references svc2.
+	idx.set(ar, []string{"Service/default/svc1"})
+	assert.Nil(t, idx.dependentsOf("Service/default/svc2"))
+
+	idx.set(ing, nil)
+	assert.Equal(t, []string{"ApisixRoute/default/bar"}, idx.dependentsOf("Service/default/svc1"))
+	assert.Nil(t, idx.dependentsOf("Secret/default/cert"))
+	idx.set(ar, nil)
+	assert.Len(t, idx.dependents, 0)
+	assert.Len(t, idx.dependencies, 0)
+}
+
+func TestIngressDependencies(t *testing.T) {
+	ing := &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "foo",
+			Namespace: "default",
+		},
+		Spec: networkingv1.IngressSpec{
+			DefaultBackend: &networkingv1.IngressBackend{
+				Service: &networkingv1.IngressServiceBackend{Name: "fallback"},
+			},
+			TLS: []networkingv1.IngressTLS{
+				{SecretName: "cert"},
+			},
+			Rules: []networkingv1.IngressRule{
+				{
+					IngressRuleValue: networkingv1.IngressRuleValue{
+						HTTP: &networkingv1.HTTPIngressRuleValue{
+							Paths: []networkingv1.HTTPIngressPath{
+								{
+									Backend: networkingv1.IngressBackend{
+										Service: &networkingv1.IngressServiceBackend{Name: "svc"},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	assert.Equal(t, []string{
+		"Service/default/fallback",
+		"ApisixUpstream/default/fallback",
+		"Service/default/svc",
+		"ApisixUpstream/default/svc",
+		"Secret/default/cert",
+		"IngressDefaultBackend/*",
+	}, ingressDependencies(kube.MustNewIngress(ing)))
+}
+
+func TestApisixRouteDependencies(t *testing.T) {
+	ar := &configv2beta1.ApisixRoute{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "bar",
+			Namespace: "default",
+		},
+		Spec: configv2beta1.ApisixRouteSpec{
+			HTTP: []configv2beta1.ApisixRouteHTTP{
+				{
+					Name: "rule1",
+					Backends: []configv2alpha1.ApisixRouteHTTPBackend{
+						{ServiceName: "svc1"},
+						{ServiceName: "svc2"},
+					},
+				},
+			},
+			Stream: []configv2beta1.ApisixRouteStream{
+				{
+					Name: "rule2",
+					Backend: configv2beta1.ApisixRouteStreamBackend{
+						ServiceName: "svc3",
+					},
+				},
+			},
+		},
+	}
+	assert.Equal(t, []string{
+		"Service/default/svc1",
+		"ApisixUpstream/default/svc1",
+		"Service/default/svc2",
+		"ApisixUpstream/default/svc2",
+		"Service/default/svc3",
+		"ApisixUpstream/default/svc3",
+	}, apisixRouteDependencies(kube.MustNewApisixRoute(ar)))
+}
+
+func TestResyncSecretDependents(t *testing.T) {
+	tls := &configv1.ApisixTls{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "tls",
+			Namespace: "default",
+		},
+		Spec: &configv1.ApisixTlsSpec{
+			Secret: configv1.ApisixSecret{
+				Name:      "cert",
+				Namespace: "certs",
+			},
+			Client: &configv1.ApisixMutualTlsClientConfig{
+				CASecret: configv1.ApisixSecret{
+					Name:      "ca",
+					Namespace: "certs",
+				},
+			},
+		},
+	}
+	assert.Equal(t, []string{"Secret/certs/cert", "Secret/certs/ca"}, apisixTlsDependencies(tls))
+
+	ac := &configv2alpha1.ApisixConsumer{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "jack",
+			Namespace: "default",
+		},
+		Spec: configv2alpha1.ApisixConsumerSpec{
+			AuthParameter: configv2alpha1.ApisixConsumerAuthParameter{
+				KeyAuth: &configv2alpha1.ApisixConsumerKeyAuth{
+					SecretRef: &corev1.LocalObjectReference{Name: "jack-key"},
+				},
+			},
+		},
+	}
+	assert.Equal(t, []string{"Secret/default/jack-key"}, apisixConsumerDependencies(ac))
+
+	acc := &configv2alpha1.ApisixClusterConfig{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "external",
+		},
+		Spec: configv2alpha1.ApisixClusterConfigSpec{
+			Admin: &configv2alpha1.ApisixClusterAdminConfig{
+				TLS: &configv2alpha1.ApisixClusterAdminTLSConfig{
+					CASecretRef:         &corev1.SecretReference{Namespace: "certs", Name: "ca"},
+					ClientCertSecretRef: &corev1.SecretReference{Namespace: "certs", Name: "client"},
+				},
+			},
+		},
+	}
+	assert.Equal(t, []string{"Secret/certs/ca", "Secret/certs/client"}, apisixClusterConfigDependencies(acc))
+
+	c := &Controller{
+		dependencyIndex:               newDependencyIndex(),
+		apisixTlsController:           &apisixTlsController{workqueue: workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())},
+		apisixConsumerController:      &apisixConsumerController{workqueue: workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())},
+		apisixClusterConfigController: &apisixClusterConfigController{workqueue: workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())},
+	}
+	c.dependencyIndex.set(sourceKey("ApisixTls", "default/tls"), apisixTlsDependencies(tls))
+	c.dependencyIndex.set(sourceKey("ApisixConsumer", "default/jack"), apisixConsumerDependencies(ac))
+	c.dependencyIndex.set(sourceKey("ApisixClusterConfig", "external"), apisixClusterConfigDependencies(acc))
+
+	c.resyncDependents(sourceKey(_dependencyKindSecret, "default/jack-key"))
+	assert.Equal(t, 0, c.apisixTlsController.workqueue.Len())
+	assert.Equal(t, 1, c.apisixConsumerController.workqueue.Len())
+	obj, _ := c.apisixConsumerController.workqueue.Get()
+	assert.Equal(t, &types.Event{Type: types.EventUpdate, Object: "default/jack"}, obj)
+
+	c.resyncDependents(sourceKey(_dependencyKindSecret, "certs/ca"))
+	assert.Equal(t, 1, c.apisixTlsController.workqueue.Len())
+	obj, _ = c.apisixTlsController.workqueue.Get()
+	assert.Equal(t, &types.Event{Type: types.EventUpdate, Object: "default/tls"}, obj)
+	assert.Equal(t, 1, c.apisixClusterConfigController.workqueue.Len())
+	obj, _ = c.apisixClusterConfigController.workqueue.Get()
+	assert.Equal(t, &types.Event{Type: types.EventUpdate, Object: "external"}, obj)
+
+	c.resyncDependents(sourceKey(_dependencyKindSecret, "certs/client"))
+	assert.Equal(t, 0, c.apisixTlsController.workqueue.Len())
+	assert.Equal(t, 1, c.apisixClusterConfigController.workqueue.Len())
+}
+
+func TestResyncServiceDependentsOnDelete(t *testing.T) {
+	c := &Controller{
+		cfg:             config.NewDefaultConfig(),
+		dependencyIndex: newDependencyIndex(),
+		ingressInformer: cache.NewSharedIndexInformer(&cache.ListWatch{}, &networkingv1.Ingress{}, 0, cache.Indexers{}),
+	}
+	c.ingressController = &ingressController{
+		controller: c,
+		workqueue:  workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter()),
+	}
+	ing := &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "foo",
+			Namespace:   "default",
+			Annotations: map[string]string{_ingressKey: "apisix"},
+		},
+	}
+	assert.Nil(t, c.ingressInformer.GetStore().Add(ing))
+	c.dependencyIndex.set(sourceKey(translation.SourceKindIngress, "default/foo"), []string{sourceKey(_dependencyKindService, "default/httpbin")})
+
+	// The final state of the deleted Service is unknown.
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "httpbin",
+			Namespace: "default",
+		},
+	}
+	c.onDependencyChange(_dependencyKindService, cache.DeletedFinalStateUnknown{Key: "default/httpbin", Obj: svc})
+	assert.Equal(t, 1, c.ingressController.workqueue.Len())
+	obj, _ := c.ingressController.workqueue.Get()
+	assert.Equal(t, "default/foo", obj.(*types.Event).Object.(kube.IngressEvent).Key)
+}
@@ -25,6 +25,7 @@ import (
 
 	"github.com/apache/apisix-ingress-controller/pkg/kube"
 	"github.com/apache/apisix-ingress-controller/pkg/log"
+	"github.com/apache/apisix-ingress-controller/pkg/metrics"
 	"github.com/apache/apisix-ingress-controller/pkg/types"
 )
 
@@ -84,12 +85,17 @@ func (c *endpointsController) run(ctx context.Context) {
 
 func (c *endpointsController) sync(ctx context.Context, ev *types.Event) error {
 	ep := ev.Object.(kube.Endpoint)
-	return c.controller.syncEndpoint(ctx, ep)
+	if err := c.controller.syncEndpoint(ctx, ep); err != nil {
+		c.controller.metricsCollector.IncrSyncOperation("Endpoints", metrics.SyncResultPushFailure)
+		return err
+	}
+	return nil
 }
 
 func (c *endpointsController) handleSyncErr(obj interface{}, err error) {
 	if err == nil {
 		c.workqueue.Forget(obj)
+		c.controller.metricsCollector.IncrSyncOperation("Endpoints", metrics.SyncResultSuccess)
 		return
 	}
 	log.Warnw("sync endpoints failed, will retry",
@@ -24,6 +24,7 @@ import (
 	"k8s.io/client-go/util/workqueue"
 
 	"github.com/apache/apisix-ingress-controller/pkg/log"
+	"github.com/apache/apisix-ingress-controller/pkg/metrics"
 	"github.com/apache/apisix-ingress-controller/pkg/types"
 )
 
@@ -103,12 +104,17 @@ func (c *endpointSliceController) sync(ctx context.Context, ev *types.Event) err
 			epEvent.ServiceName, err)
 		return err
 	}
-	return c.controller.syncEndpoint(ctx, ep)
+	if err := c.controller.syncEndpoint(ctx, ep); err != nil {
+		c.controller.metricsCollector.IncrSyncOperation("EndpointSlice", metrics.SyncResultPushFailure)
+		return err
+	}
+	return nil
 }
 
 func (c *endpointSliceController) handleSyncErr(obj interface{}, err error) {
 	if err == nil {
 		c.workqueue.Forget(obj)
+		c.controller.metricsCollector.IncrSyncOperation("EndpointSlice", metrics.SyncResultSuccess)
 		return
 	}
 	log.Warnw("sync endpointSlice failed, will retry",
@@ -0,0 +1,221 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package ingress
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	"github.com/apache/apisix-ingress-controller/pkg/config"
+	"github.com/apache/apisix-ingress-controller/pkg/kube"
+	configv1 "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/apis/config/v1"
+	configv2alpha1 "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/apis/config/v2alpha1"
+	configv2beta1 "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/apis/config/v2beta1"
+	"github.com/apache/apisix-ingress-controller/pkg/log"
+)
+
+const (
+	// _finalizer is added to Apisix* objects, so that they won't be removed
+	// until their APISIX resources are deleted. Controllers of other classes
+	// than the default one suffix it with their class (see finalizer), it was
+	// shared by all controllers in previous versions.
+	_finalizer = "apisix.apache.org/finalizer"
+	// _forceDeleteAnnotation can be set to "true" on a deleting object to
+	// remove the finalizer without deleting its APISIX resources, it's
+	// useful when the APISIX cluster is gone.
+	_forceDeleteAnnotation = "apisix.apache.org/force-delete"
+	// _resourceForceDeleted is used when a finalizer is removed forcibly
+	_resourceForceDeleted = "ResourceForceDeleted"
+	// _messageResourceForceDeleted is used to report the forcible removal
+	_messageResourceForceDeleted = "%s removed the finalizer forcibly, resources in APISIX might be left behind"
+)
+
+// kubeObject is a Kubernetes object with metadata.
+type kubeObject interface {
+	metav1.Object
+	runtime.Object
+}
+
+// apisixRouteObject returns the object of the ApisixRoute in its own version.
+func apisixRouteObject(ar kube.ApisixRoute) kubeObject {
+	switch ar.GroupVersion() {
+	case kube.ApisixRouteV1:
+		return ar.V1()
+	case kube.ApisixRouteV2alpha1:
+		return ar.V2alpha1()
+	default:
+		return ar.V2beta1()
+	}
+}
+
+// finalizer returns the finalizer added by the controller, it's derived from
+// the ingress class, so that controllers of different classes never remove
+// the finalizers of each other.
+func (c *Controller) finalizer() string {
+	class := c.cfg.Kubernetes.IngressClass
+	if class == config.IngressClass {
+		return _finalizer
+	}
+	name := _finalizer + "-" + class
+	if len(validation.IsQualifiedName(name)) > 0 {
+		sum := sha256.Sum256([]byte(class))
+		name = _finalizer + "-" + hex.EncodeToString(sum[:8])
+	}
+	return name
+}
+
+func (c *Controller) hasFinalizer(obj metav1.Object) bool {
+	for _, f := range obj.GetFinalizers() {
+		if f == c.finalizer() {
+			return true
+		}
+	}
+	return false
+}
+
+// isFinalizing returns true if the object is being deleted and its APISIX
+// resources should be deleted by the controller. It's called on objects
+// handled by us, so the finalizer shared by previous versions counts.
+func (c *Controller) isFinalizing(obj metav1.Object) bool {
+	if obj.GetDeletionTimestamp() == nil {
+		return false
+	}
+	for _, f := range obj.GetFinalizers() {
+		if f == c.finalizer() || f == _finalizer {
+			return true
+		}
+	}
+	return false
+}
+
+// isForceDeleting returns true if the finalizer of the object should be
+// removed without deleting the APISIX resources.
+func isForceDeleting(obj metav1.Object) bool {
+	return obj.GetAnnotations()[_forceDeleteAnnotation] == "true"
+}
+
+// ensureFinalizer adds the finalizer to the object if it doesn't exist.
+func (c *Controller) ensureFinalizer(ctx context.Context, obj kubeObject) error {
+	if obj.GetDeletionTimestamp() != nil || c.hasFinalizer(obj) {
+		return nil
+	}
+	// The object might come from the lister cache, copy the finalizers
+	// rather than appending to them, which can write to the cached array.
+	finalizers := make([]string, 0, len(obj.GetFinalizers())+1)
+	finalizers = append(finalizers, obj.GetFinalizers()...)
+	finalizers = append(finalizers, c.finalizer())
+	return c.patchFinalizers(ctx, obj, finalizers)
+}
+
+// removeFinalizer removes the finalizer from the object, so it can be
+// removed by Kubernetes. The finalizer shared by previous versions is also
+// removed if the object is handled by us, finalizers of other controllers
+// are kept.
+func (c *Controller) removeFinalizer(ctx context.Context, obj kubeObject) error {
+	owned := map[string]struct{}{
+		c.finalizer(): {},
+	}
+	if c.isApisixObjectEffective(obj) {
+		owned[_finalizer] = struct{}{}
+	}
+	var finalizers []string
+	for _, f := range obj.GetFinalizers() {
+		if _, ok := owned[f]; !ok {
+			finalizers = append(finalizers, f)
+		}
+	}
+	if len(finalizers) == len(obj.GetFinalizers()) {
+		return nil
+	}
+	if err := c.patchFinalizers(ctx, obj, finalizers); err != nil {
+		if k8serrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	return nil
+}
+
+// forceRemoveFinalizer removes the finalizer without deleting the APISIX
+// resources of the object.
+func (c *Controller) forceRemoveFinalizer(ctx context.Context, obj kubeObject) error {
+	log.Warnw("removing the finalizer forcibly",
+		zap.String("namespace", obj.GetNamespace()),
+		zap.String("name", obj.GetName()),
+	)
+	if err := c.removeFinalizer(ctx, obj); err != nil {
+		return err
+	}
+	c.recorderEventS(obj, corev1.EventTypeWarning, _resourceForceDeleted,
+		fmt.Sprintf(_messageResourceForceDeleted, _component))
+	return nil
+}
+
+func (c *Controller) patchFinalizers(ctx context.Context, obj kubeObject, finalizers []string) error {
+	if finalizers == nil {
+		finalizers = []string{}
+	}
+	// The resource version is used for the optimistic concurrency control,
+	// as the finalizers list will be replaced totally.
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"finalizers":      finalizers,
+			"resourceVersion": obj.GetResourceVersion(),
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	client := c.kubeClient.APISIXClient
+	switch v := obj.(type) {
+	case *configv1.ApisixRoute:
+		_, err = client.ApisixV1().ApisixRoutes(v.Namespace).Patch(ctx, v.Name, k8stypes.MergePatchType, patch, metav1.PatchOptions{})
+	case *configv2alpha1.ApisixRoute:
+		_, err = client.ApisixV2alpha1().ApisixRoutes(v.Namespace).Patch(ctx, v.Name, k8stypes.MergePatchType, patch, metav1.PatchOptions{})
+	case *configv2beta1.ApisixRoute:
+		_, err = client.ApisixV2beta1().ApisixRoutes(v.Namespace).Patch(ctx, v.Name, k8stypes.MergePatchType, patch, metav1.PatchOptions{})
+	case *configv1.ApisixUpstream:
+		_, err = client.ApisixV1().ApisixUpstreams(v.Namespace).Patch(ctx, v.Name, k8stypes.MergePatchType, patch, metav1.PatchOptions{})
+	case *configv1.ApisixTls:
+		_, err = client.ApisixV1().ApisixTlses(v.Namespace).Patch(ctx, v.Name, k8stypes.MergePatchType, patch, metav1.PatchOptions{})
+	case *configv2alpha1.ApisixConsumer:
+		_, err = client.ApisixV2alpha1().ApisixConsumers(v.Namespace).Patch(ctx, v.Name, k8stypes.MergePatchType, patch, metav1.PatchOptions{})
+	case *configv2alpha1.ApisixClusterConfig:
+		_, err = client.ApisixV2alpha1().ApisixClusterConfigs().Patch(ctx, v.Name, k8stypes.MergePatchType, patch, metav1.PatchOptions{})
+	default:
+		return fmt.Errorf("unsupported object type %T", obj)
+	}
+	if err != nil {
+		log.Errorw("failed to patch finalizers",
+			zap.String("namespace", obj.GetNamespace()),
+			zap.String("name", obj.GetName()),
+			zap.Strings("finalizers", finalizers),
+			zap.Error(err),
+		)
+	}
+	return err
+}
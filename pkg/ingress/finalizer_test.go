@@ -0,0 +1,247 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package ingress
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+
+	apisixcache "github.com/apache/apisix-ingress-controller/pkg/apisix/cache"
+	"github.com/apache/apisix-ingress-controller/pkg/config"
+	"github.com/apache/apisix-ingress-controller/pkg/kube"
+	configv1 "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/apis/config/v1"
+	configv2alpha1 "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/apis/config/v2alpha1"
+	"github.com/apache/apisix-ingress-controller/pkg/kube/apisix/client/clientset/versioned/fake"
+	listersv1 "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/client/listers/config/v1"
+	listersv2alpha1 "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/client/listers/config/v2alpha1"
+	"github.com/apache/apisix-ingress-controller/pkg/types"
+	apisixv1 "github.com/apache/apisix-ingress-controller/pkg/types/apisix/v1"
+)
+
+func TestIsFinalizing(t *testing.T) {
+	c := &Controller{cfg: config.NewDefaultConfig()}
+	obj := &metav1.ObjectMeta{}
+	assert.False(t, c.isFinalizing(obj))
+
+	obj.Finalizers = []string{_finalizer}
+	assert.False(t, c.isFinalizing(obj))
+
+	now := metav1.Now()
+	obj.DeletionTimestamp = &now
+	assert.True(t, c.isFinalizing(obj))
+
+	obj.Finalizers = []string{"foo.bar/finalizer"}
+	assert.False(t, c.isFinalizing(obj))
+
+	assert.False(t, isForceDeleting(obj))
+	obj.Annotations = map[string]string{_forceDeleteAnnotation: "true"}
+	assert.True(t, isForceDeleting(obj))
+}
+
+func TestEnsureAndRemoveFinalizer(t *testing.T) {
+	// Spare capacity, so appending to it in place would be unnoticed.
+	finalizers := make([]string, 1, 2)
+	finalizers[0] = "foo.bar/finalizer"
+	ac := &configv2alpha1.ApisixConsumer{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "jack",
+			Namespace:  "default",
+			Finalizers: finalizers,
+		},
+	}
+	client := fake.NewSimpleClientset(ac)
+	c := &Controller{
+		kubeClient: &kube.KubeClient{
+			APISIXClient: client,
+		},
+		cfg:      config.NewDefaultConfig(),
+		recorder: record.NewFakeRecorder(1),
+	}
+	ctx := context.Background()
+	get := func() *configv2alpha1.ApisixConsumer {
+		obj, err := client.ApisixV2alpha1().ApisixConsumers("default").Get(ctx, "jack", metav1.GetOptions{})
+		assert.Nil(t, err)
+		return obj
+	}
+
+	assert.Nil(t, c.ensureFinalizer(ctx, ac))
+	// The object (maybe cached by the lister) isn't changed.
+	assert.Equal(t, "", finalizers[:2][1])
+	ac = get()
+	assert.Equal(t, []string{"foo.bar/finalizer", _finalizer}, ac.Finalizers)
+
+	// Already added.
+	assert.Nil(t, c.ensureFinalizer(ctx, ac))
+	assert.Equal(t, []string{"foo.bar/finalizer", _finalizer}, get().Finalizers)
+
+	assert.Nil(t, c.removeFinalizer(ctx, ac))
+	assert.Equal(t, []string{"foo.bar/finalizer"}, get().Finalizers)
+
+	// Objects which have gone are ignored.
+	ac.Name = "rose"
+	assert.Nil(t, c.removeFinalizer(ctx, ac))
+}
+
+func TestFinalizerOfClass(t *testing.T) {
+	cfg := config.NewDefaultConfig()
+	cfg.Kubernetes.IngressClass = "internal"
+	ac := &configv2alpha1.ApisixConsumer{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "jack",
+			Namespace:   "default",
+			Annotations: map[string]string{_ingressKey: "internal"},
+		},
+	}
+	client := fake.NewSimpleClientset(ac)
+	c := &Controller{
+		kubeClient: &kube.KubeClient{
+			APISIXClient: client,
+		},
+		cfg: cfg,
+	}
+	assert.Equal(t, "apisix.apache.org/finalizer-internal", c.finalizer())
+	ctx := context.Background()
+	get := func() *configv2alpha1.ApisixConsumer {
+		obj, err := client.ApisixV2alpha1().ApisixConsumers("default").Get(ctx, "jack", metav1.GetOptions{})
+		assert.Nil(t, err)
+		return obj
+	}
+
+	assert.Nil(t, c.ensureFinalizer(ctx, ac))
+	ac = get()
+	assert.Equal(t, []string{"apisix.apache.org/finalizer-internal"}, ac.Finalizers)
+
+	// The class is moved to the default controller, which adds its own
+	// finalizer, ours is removed only.
+	ac.Annotations[_ingressKey] = "apisix"
+	ac.Finalizers = append(ac.Finalizers, _finalizer)
+	ac, err := client.ApisixV2alpha1().ApisixConsumers("default").Update(ctx, ac, metav1.UpdateOptions{})
+	assert.Nil(t, err)
+	assert.Nil(t, c.removeFinalizer(ctx, ac))
+	assert.Equal(t, []string{_finalizer}, get().Finalizers)
+
+	// The finalizer shared by previous versions is removed once the object
+	// handled by us is finalized.
+	ac = get()
+	ac.Annotations[_ingressKey] = "internal"
+	now := metav1.Now()
+	ac.DeletionTimestamp = &now
+	assert.True(t, c.isFinalizing(ac))
+	assert.Nil(t, c.removeFinalizer(ctx, ac))
+	assert.Len(t, get().Finalizers, 0)
+
+	// Class names which are not valid in finalizers are hashed.
+	cfg.Kubernetes.IngressClass = "internal/gateway"
+	assert.Regexp(t, "^apisix.apache.org/finalizer-[0-9a-f]{16}$", c.finalizer())
+}
+
+func TestFinalizeApisixRouteV1WithoutTranslation(t *testing.T) {
+	now := metav1.Now()
+	ar := &configv1.ApisixRoute{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "foo",
+			Namespace:         "default",
+			Finalizers:        []string{_finalizer},
+			DeletionTimestamp: &now,
+		},
+	}
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	assert.Nil(t, indexer.Add(ar))
+	client := fake.NewSimpleClientset(ar)
+
+	c := newClusterSelectorController(t)
+	c.cfg.Kubernetes = config.NewDefaultConfig().Kubernetes
+	cli := &fakeAPISIX{
+		clusters: map[string]*fakeCluster{
+			"default":  newFakeCluster(t),
+			"external": newFakeCluster(t),
+		},
+	}
+	c.apisix = cli
+	c.dependencyIndex = newDependencyIndex()
+	c.kubeClient = &kube.KubeClient{APISIXClient: client}
+	c.apisixRouteLister = kube.NewApisixRouteLister(listersv1.NewApisixRouteLister(indexer), nil, nil)
+	arc := &apisixRouteController{controller: c}
+
+	labels := map[string]string{
+		apisixv1.LabelSourceKind:      "ApisixRoute",
+		apisixv1.LabelSourceNamespace: "default",
+		apisixv1.LabelSourceName:      "foo",
+	}
+	db := cli.clusters["default"].cache
+	assert.Nil(t, db.InsertUpstream(&apisixv1.Upstream{Metadata: apisixv1.Metadata{ID: "u1", Labels: labels}}))
+	assert.Nil(t, db.InsertRoute(&apisixv1.Route{Metadata: apisixv1.Metadata{ID: "r1", Labels: labels}, UpstreamId: "u1"}))
+
+	// The Service is deleted together with the ApisixRoute, the translator
+	// (nil here) is not used.
+	err := arc.sync(context.Background(), &types.Event{
+		Type: types.EventUpdate,
+		Object: kube.ApisixRouteEvent{
+			Key:          "default/foo",
+			GroupVersion: kube.ApisixRouteV1,
+		},
+	})
+	assert.Nil(t, err)
+	_, err = db.GetRoute("r1")
+	assert.Equal(t, apisixcache.ErrNotFound, err)
+	_, err = db.GetUpstream("u1")
+	assert.Equal(t, apisixcache.ErrNotFound, err)
+	obj, err := client.ApisixV1().ApisixRoutes("default").Get(context.Background(), "foo", metav1.GetOptions{})
+	assert.Nil(t, err)
+	assert.Len(t, obj.Finalizers, 0)
+}
+
+func TestFinalizeUnregisteredApisixClusterConfig(t *testing.T) {
+	now := metav1.Now()
+	acc := &configv2alpha1.ApisixClusterConfig{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "external",
+			Finalizers:        []string{_finalizer},
+			DeletionTimestamp: &now,
+		},
+		Spec: configv2alpha1.ApisixClusterConfigSpec{
+			Admin: &configv2alpha1.ApisixClusterAdminConfig{
+				BaseURL: "http://apisix-external:9180/apisix/admin",
+			},
+		},
+	}
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	assert.Nil(t, indexer.Add(acc))
+	client := fake.NewSimpleClientset(acc)
+	c := &Controller{
+		cfg:                       config.NewDefaultConfig(),
+		apisix:                    &fakeAPISIX{},
+		dependencyIndex:           newDependencyIndex(),
+		kubeClient:                &kube.KubeClient{APISIXClient: client},
+		apisixClusterConfigLister: listersv2alpha1.NewApisixClusterConfigLister(indexer),
+	}
+	accc := &apisixClusterConfigController{controller: c}
+
+	// The cluster is never registered (e.g. its Secrets are missing), the
+	// finalizer is removed anyway.
+	err := accc.sync(context.Background(), &types.Event{
+		Type:   types.EventUpdate,
+		Object: "external",
+	})
+	assert.Nil(t, err)
+	obj, err := client.ApisixV2alpha1().ApisixClusterConfigs().Get(context.Background(), "external", metav1.GetOptions{})
+	assert.Nil(t, err)
+	assert.Len(t, obj.Finalizers, 0)
+}
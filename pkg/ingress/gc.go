@@ -0,0 +1,243 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package ingress
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/apache/apisix-ingress-controller/pkg/apisix"
+	"github.com/apache/apisix-ingress-controller/pkg/config"
+	"github.com/apache/apisix-ingress-controller/pkg/kube"
+	"github.com/apache/apisix-ingress-controller/pkg/kube/translation"
+	"github.com/apache/apisix-ingress-controller/pkg/log"
+	apisixv1 "github.com/apache/apisix-ingress-controller/pkg/types/apisix/v1"
+)
+
+// collectGarbage deletes the resources in the snapshot of the cluster which
+// are managed by apisix-ingress-controller but their source Kubernetes objects
+// no longer exist. Resources which are still desired by some objects are kept.
+func (c *Controller) collectGarbage(ctx context.Context, clusterName string, snap *apisix.Snapshot, items []*reconcileItem) {
+	desired := make(map[[2]string]struct{})
+	for _, item := range items {
+		for _, r := range item.manifest.resources() {
+			desired[r] = struct{}{}
+		}
+	}
+	orphans := findOrphans(snap, desired, c.sourceExists)
+	// Upstreams might be shared, keep those still referenced by others.
+	var upstreams []*apisixv1.Upstream
+	for _, u := range orphans.upstreams {
+		if len(c.upstreamIndex.referencedBy(clusterName, u.ID)) == 0 {
+			upstreams = append(upstreams, u)
+		}
+	}
+	orphans.upstreams = upstreams
+	res := orphans.resources()
+	if len(res) == 0 {
+		return
+	}
+	names := make([]string, 0, len(res))
+	for _, r := range res {
+		names = append(names, r[0]+" "+r[1])
+	}
+
+	if len(res) > c.cfg.APISIX.GCMaxDeletions {
+		log.Errorw("too many orphaned resources in APISIX, garbage collection aborted",
+			zap.String("cluster", clusterName),
+			zap.Int("orphans", len(res)),
+			zap.Int("max_deletions", c.cfg.APISIX.GCMaxDeletions),
+			zap.Strings("resources", names),
+		)
+		return
+	}
+	if c.cfg.APISIX.GCDryRun {
+		log.Warnw("found orphaned resources in APISIX (dry run)",
+			zap.String("cluster", clusterName),
+			zap.Strings("resources", names),
+		)
+		return
+	}
+	if err := c.syncManifests(ctx, clusterName, nil, nil, orphans); err != nil {
+		log.Errorw("failed to delete orphaned resources in APISIX",
+			zap.String("cluster", clusterName),
+			zap.Strings("resources", names),
+			zap.Error(err),
+		)
+		return
+	}
+	log.Infow("orphaned resources in APISIX deleted",
+		zap.String("cluster", clusterName),
+		zap.Strings("resources", names),
+	)
+}
+
+// findOrphans returns resources in the snapshot which are managed by
+// apisix-ingress-controller, not desired, and whose source objects don't
+// exist. Resources without source labels (e.g. created by older versions)
+// are never treated as orphans.
+func findOrphans(snap *apisix.Snapshot, desired map[[2]string]struct{}, exists func(map[string]string) (bool, error)) *manifest {
+	isOrphan := func(typ, id string, labels map[string]string) bool {
+		if labels[apisixv1.LabelManagedBy] != apisixv1.LabelManagedByValue {
+			return false
+		}
+		if labels[apisixv1.LabelSourceKind] == "" || labels[apisixv1.LabelSourceName] == "" {
+			return false
+		}
+		if _, ok := desired[[2]string{typ, id}]; ok {
+			return false
+		}
+		ok, err := exists(labels)
+		if err != nil {
+			log.Warnw("failed to check the source object of resource, skip collecting it",
+				zap.String("resource", typ),
+				zap.String("id", id),
+				zap.Error(err),
+			)
+			return false
+		}
+		return !ok
+	}
+
+	orphans := &manifest{}
+	for _, r := range snap.Routes {
+		if isOrphan("route", r.ID, r.Labels) {
+			orphans.routes = append(orphans.routes, r)
+		}
+	}
+	for _, u := range snap.Upstreams {
+		if isOrphan("upstream", u.ID, u.Labels) {
+			orphans.upstreams = append(orphans.upstreams, u)
+		}
+	}
+	for _, sr := range snap.StreamRoutes {
+		if isOrphan("stream_route", sr.ID, sr.Labels) {
+			orphans.streamRoutes = append(orphans.streamRoutes, sr)
+		}
+	}
+	for _, ssl := range snap.SSLs {
+		if isOrphan("ssl", ssl.ID, ssl.Labels) {
+			orphans.ssls = append(orphans.ssls, ssl)
+		}
+	}
+	for _, consumer := range snap.Consumers {
+		if isOrphan("consumer", consumer.Username, consumer.Labels) {
+			orphans.consumers = append(orphans.consumers, consumer)
+		}
+	}
+	return orphans
+}
+
+// sourceExists checks whether the source Kubernetes object recorded in the
+// labels still exists, an object with the same name but a different UID is
+// treated as another one.
+func (c *Controller) sourceExists(labels map[string]string) (bool, error) {
+	var (
+		obj metav1.Object
+		err error
+
+		namespace = labels[apisixv1.LabelSourceNamespace]
+		name      = labels[apisixv1.LabelSourceName]
+	)
+	switch labels[apisixv1.LabelSourceKind] {
+	case translation.SourceKindIngress:
+		var ing kube.Ingress
+		switch c.cfg.Kubernetes.IngressVersion {
+		case config.IngressNetworkingV1:
+			if ing, err = c.ingressLister.V1(namespace, name); err == nil {
+				obj = ing.V1()
+			}
+		case config.IngressNetworkingV1beta1:
+			if ing, err = c.ingressLister.V1beta1(namespace, name); err == nil {
+				obj = ing.V1beta1()
+			}
+		default:
+			if ing, err = c.ingressLister.ExtensionsV1beta1(namespace, name); err == nil {
+				obj = ing.ExtensionsV1beta1()
+			}
+		}
+	case translation.SourceKindApisixRoute:
+		var ar kube.ApisixRoute
+		switch c.cfg.Kubernetes.ApisixRouteVersion {
+		case config.ApisixRouteV1:
+			if ar, err = c.apisixRouteLister.V1(namespace, name); err == nil {
+				obj = ar.V1()
+			}
+		case config.ApisixRouteV2alpha1:
+			if ar, err = c.apisixRouteLister.V2alpha1(namespace, name); err == nil {
+				obj = ar.V2alpha1()
+			}
+		default:
+			if ar, err = c.apisixRouteLister.V2beta1(namespace, name); err == nil {
+				obj = ar.V2beta1()
+			}
+		}
+	case translation.SourceKindApisixTls:
+		obj, err = c.apisixTlsLister.ApisixTlses(namespace).Get(name)
+	case translation.SourceKindApisixConsumer:
+		obj, err = c.apisixConsumerLister.ApisixConsumers(namespace).Get(name)
+	default:
+		// Unknown kinds are never collected.
+		return true, nil
+	}
+	if err != nil {
+		if !k8serrors.IsNotFound(err) {
+			return false, err
+		}
+		// Long names are truncated in the label, see
+		// apisixv1.ComposeSourceNameLabel.
+		if len(name) < apisixv1.MaxLabelValueLength {
+			return false, nil
+		}
+		if obj = c.findSourceByNameLabel(labels[apisixv1.LabelSourceKind], namespace, name); obj == nil {
+			return false, nil
+		}
+	}
+	uid := labels[apisixv1.LabelSourceUID]
+	return uid == "" || uid == string(obj.GetUID()), nil
+}
+
+// findSourceByNameLabel finds the source Kubernetes object whose name label
+// (see apisixv1.ComposeSourceNameLabel) is the given one.
+func (c *Controller) findSourceByNameLabel(kind, namespace, label string) metav1.Object {
+	var store cache.Store
+	switch kind {
+	case translation.SourceKindIngress:
+		store = c.ingressInformer.GetStore()
+	case translation.SourceKindApisixRoute:
+		store = c.apisixRouteInformer.GetStore()
+	case translation.SourceKindApisixTls:
+		store = c.apisixTlsInformer.GetStore()
+	case translation.SourceKindApisixConsumer:
+		store = c.apisixConsumerInformer.GetStore()
+	default:
+		return nil
+	}
+	for _, item := range store.List() {
+		obj, err := meta.Accessor(item)
+		if err != nil {
+			continue
+		}
+		if obj.GetNamespace() == namespace && apisixv1.ComposeSourceNameLabel(obj.GetName()) == label {
+			return obj
+		}
+	}
+	return nil
+}
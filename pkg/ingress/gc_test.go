@@ -0,0 +1,124 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package ingress
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/apache/apisix-ingress-controller/pkg/apisix"
+	configv1 "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/apis/config/v1"
+	"github.com/apache/apisix-ingress-controller/pkg/kube/translation"
+	apisixv1 "github.com/apache/apisix-ingress-controller/pkg/types/apisix/v1"
+)
+
+func sourceLabels(kind, name string) map[string]string {
+	return map[string]string{
+		apisixv1.LabelManagedBy:       apisixv1.LabelManagedByValue,
+		apisixv1.LabelSourceKind:      kind,
+		apisixv1.LabelSourceNamespace: "default",
+		apisixv1.LabelSourceName:      name,
+	}
+}
+
+func TestFindOrphans(t *testing.T) {
+	newRoute := func(id string, labels map[string]string) *apisixv1.Route {
+		return &apisixv1.Route{
+			Metadata: apisixv1.Metadata{
+				ID:     id,
+				Labels: labels,
+			},
+		}
+	}
+	snap := &apisix.Snapshot{
+		Routes: []*apisixv1.Route{
+			// Source exists.
+			newRoute("1", sourceLabels("ApisixRoute", "exists")),
+			// Source deleted.
+			newRoute("2", sourceLabels("ApisixRoute", "deleted")),
+			// Source deleted but still desired by another object.
+			newRoute("3", sourceLabels("ApisixRoute", "deleted")),
+			// Not managed by apisix-ingress-controller.
+			newRoute("4", map[string]string{
+				apisixv1.LabelSourceKind: "ApisixRoute",
+				apisixv1.LabelSourceName: "deleted",
+			}),
+			// Without source labels.
+			newRoute("5", map[string]string{
+				apisixv1.LabelManagedBy: apisixv1.LabelManagedByValue,
+			}),
+			// Failed to check the source.
+			newRoute("6", sourceLabels("ApisixRoute", "unknown")),
+		},
+		Upstreams: []*apisixv1.Upstream{
+			{
+				Metadata: apisixv1.Metadata{
+					ID:     "u1",
+					Labels: sourceLabels("Ingress", "deleted"),
+				},
+			},
+		},
+		Consumers: []*apisixv1.Consumer{
+			{
+				Username: "default_jack",
+				Labels:   sourceLabels("ApisixConsumer", "exists"),
+			},
+		},
+	}
+	desired := map[[2]string]struct{}{
+		{"route", "3"}: {},
+	}
+	exists := func(labels map[string]string) (bool, error) {
+		switch labels[apisixv1.LabelSourceName] {
+		case "exists":
+			return true, nil
+		case "deleted":
+			return false, nil
+		default:
+			return false, errors.New("unknown")
+		}
+	}
+
+	orphans := findOrphans(snap, desired, exists)
+	assert.Equal(t, [][2]string{{"route", "2"}, {"upstream", "u1"}}, orphans.resources())
+}
+
+func TestFindSourceByNameLabel(t *testing.T) {
+	informer := cache.NewSharedIndexInformer(&cache.ListWatch{}, &configv1.ApisixTls{}, 0, cache.Indexers{})
+	c := &Controller{
+		apisixTlsInformer: informer,
+	}
+	name := strings.Repeat("a", 253)
+	tls := &configv1.ApisixTls{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+		},
+	}
+	assert.Nil(t, informer.GetStore().Add(tls))
+
+	label := apisixv1.ComposeSourceNameLabel(name)
+	obj := c.findSourceByNameLabel(translation.SourceKindApisixTls, "default", label)
+	assert.NotNil(t, obj)
+	assert.Equal(t, name, obj.GetName())
+	assert.Nil(t, c.findSourceByNameLabel(translation.SourceKindApisixTls, "test", label))
+	assert.Nil(t, c.findSourceByNameLabel(translation.SourceKindApisixTls, "default", name[:64]))
+	assert.Nil(t, c.findSourceByNameLabel("Unknown", "default", label))
+}
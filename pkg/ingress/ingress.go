@@ -17,15 +17,19 @@ package ingress
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/util/workqueue"
 
 	"github.com/apache/apisix-ingress-controller/pkg/kube"
+	"github.com/apache/apisix-ingress-controller/pkg/kube/translation"
 	"github.com/apache/apisix-ingress-controller/pkg/log"
+	"github.com/apache/apisix-ingress-controller/pkg/metrics"
 	"github.com/apache/apisix-ingress-controller/pkg/types"
 )
 
@@ -115,7 +119,9 @@ func (c *ingressController) sync(ctx context.Context, ev *types.Event) error {
 		}
 	}
 	if ev.Type == types.EventDelete {
-		if ing != nil {
+		// The Ingress might still exist but not be handled anymore, e.g. its
+		// namespace is excluded, its resources should be deleted.
+		if ing != nil && c.controller.namespaceWatching(ingEv.Key) && c.isIngressEffective(ing) {
 			// We still find the resource while we are processing the DELETE event,
 			// that means object with same namespace and name was created, discarding
 			// this stale DELETE event.
@@ -125,56 +131,91 @@ func (c *ingressController) sync(ctx context.Context, ev *types.Event) error {
 		ing = ev.Tombstone.(kube.Ingress)
 	}
 
-	tctx, err := c.controller.translator.TranslateIngress(ing)
-	if err != nil {
-		log.Errorw("failed to translate ingress",
+	dependent := sourceKey(translation.SourceKindIngress, ingEv.Key)
+	prevDeps := c.controller.dependencyIndex.dependenciesOf(dependent)
+	var deps []string
+	if ev.Type != types.EventDelete {
+		deps = ingressDependencies(ing)
+	}
+	c.controller.dependencyIndex.set(dependent, deps)
+	c.controller.resyncIngressScopes(dependent, prevDeps, deps)
+
+	clusters, err := c.controller.selectClusters(ingressObject(ing))
+	if err != nil && ev.Type != types.EventDelete {
+		log.Errorw("failed to select apisix clusters",
 			zap.Error(err),
 			zap.Any("ingress", ing),
 		)
+		c.controller.metricsCollector.IncrSyncOperation("Ingress", metrics.SyncResultTranslationFailure)
 		return err
 	}
 
-	log.Debugw("translated ingress resource to a couple of routes and upstreams",
-		zap.Any("ingress", ing),
-		zap.Any("routes", tctx.Routes),
-		zap.Any("upstreams", tctx.Upstreams),
-	)
+	var om, m *manifest
+	// Resources are deleted from the cluster caches by their source labels
+	// on delete event, the Ingress is not translated as its Services and
+	// Secrets might be deleted together.
+	if ev.Type != types.EventDelete {
+		tctx, err := c.controller.translator.TranslateIngress(ing)
+		if err != nil {
+			log.Errorw("failed to translate ingress",
+				zap.Error(err),
+				zap.Any("ingress", ing),
+			)
+			c.controller.metricsCollector.IncrSyncOperation("Ingress", metrics.SyncResultTranslationFailure)
+			return err
+		}
+		if conflicts := c.resolveDefaultBackend(ing, ingEv.Key, tctx); len(conflicts) > 0 {
+			log.Warnw("ingress default backend conflicts with other ingresses",
+				zap.String("ingress", ingEv.Key),
+				zap.Strings("conflicts", conflicts),
+			)
+			c.controller.recorderEventS(ingressObject(ing), corev1.EventTypeWarning, _defaultBackendConflict,
+				fmt.Sprintf("default backend is shadowed for scopes: %s", strings.Join(conflicts, ", ")))
+		}
+		if conflicts := c.resolveTLSHosts(ing, ingEv.Key, tctx); len(conflicts) > 0 {
+			log.Warnw("ingress tls hosts conflict with other ingresses",
+				zap.String("ingress", ingEv.Key),
+				zap.Strings("conflicts", conflicts),
+			)
+			c.controller.recorderEventS(ingressObject(ing), corev1.EventTypeWarning, _tlsHostConflict,
+				fmt.Sprintf("certificates are shadowed for hosts: %s", strings.Join(conflicts, ", ")))
+		}
 
-	m := &manifest{
-		routes:    tctx.Routes,
-		upstreams: tctx.Upstreams,
+		log.Debugw("translated ingress resource to a couple of routes and upstreams",
+			zap.Any("ingress", ing),
+			zap.Any("routes", tctx.Routes),
+			zap.Any("upstreams", tctx.Upstreams),
+		)
+		m = &manifest{
+			routes:    tctx.Routes,
+			upstreams: tctx.Upstreams,
+			ssls:      tctx.SSLs,
+		}
 	}
-
-	var (
-		added   *manifest
-		updated *manifest
-		deleted *manifest
-	)
-
-	if ev.Type == types.EventDelete {
-		deleted = m
-	} else if ev.Type == types.EventAdd {
-		added = m
-	} else {
+	if ev.Type == types.EventUpdate {
+		// The old manifest is only used to diff, stale resources are also
+		// found in the cluster caches, so failures are tolerated.
 		oldCtx, err := c.controller.translator.TranslateIngress(ingEv.OldObject)
 		if err != nil {
-			log.Errorw("failed to translate ingress",
+			log.Warnw("failed to translate old ingress, diff with the pushed resources",
 				zap.String("event", "update"),
 				zap.Error(err),
 				zap.Any("ingress", ingEv.OldObject),
 			)
-			return err
-		}
-		om := &manifest{
-			routes:    oldCtx.Routes,
-			upstreams: oldCtx.Upstreams,
+		} else {
+			om = &manifest{
+				routes:    oldCtx.Routes,
+				upstreams: oldCtx.Upstreams,
+				ssls:      oldCtx.SSLs,
+			}
 		}
-		added, updated, deleted = m.diff(om)
 	}
-	if err := c.controller.syncManifests(ctx, added, updated, deleted); err != nil {
+	source := sourceKey(translation.SourceKindIngress, ingEv.Key)
+	if err := c.controller.syncSourceManifests(ctx, source, clusters, om, m); err != nil {
 		log.Errorw("failed to sync ingress artifacts",
 			zap.Error(err),
 		)
+		c.controller.metricsCollector.IncrSyncOperation("Ingress", metrics.SyncResultPushFailure)
 		return err
 	}
 	return nil
@@ -183,6 +224,7 @@ func (c *ingressController) sync(ctx context.Context, ev *types.Event) error {
 func (c *ingressController) handleSyncErr(obj interface{}, err error) {
 	if err == nil {
 		c.workqueue.Forget(obj)
+		c.controller.metricsCollector.IncrSyncOperation("Ingress", metrics.SyncResultSuccess)
 		return
 	}
 	log.Warnw("sync ingress failed, will retry",
@@ -299,28 +341,47 @@ func (c *ingressController) OnDelete(obj interface{}) {
 	})
 }
 
-func (c *ingressController) isIngressEffective(ing kube.Ingress) bool {
-	var (
-		ic  *string
-		ica string
-	)
-	if ing.GroupVersion() == kube.IngressV1 {
-		ic = ing.V1().Spec.IngressClassName
-		ica = ing.V1().GetAnnotations()[_ingressKey]
-	} else if ing.GroupVersion() == kube.IngressV1beta1 {
-		ic = ing.V1beta1().Spec.IngressClassName
-		ica = ing.V1beta1().GetAnnotations()[_ingressKey]
-	} else {
-		ic = ing.ExtensionsV1beta1().Spec.IngressClassName
-		ica = ing.ExtensionsV1beta1().GetAnnotations()[_ingressKey]
+// resync enqueues the Ingress by its key so it will be translated again,
+// the current object is used as the old one for update events.
+func (c *ingressController) resync(key string, typ types.EventType) {
+	obj, exists, err := c.controller.ingressInformer.GetStore().GetByKey(key)
+	if err != nil || !exists || !c.controller.namespaceWatching(key) {
+		return
 	}
-
-	// kubernetes.io/ingress.class takes the precedence.
-	if ica != "" {
-		return ica == c.controller.cfg.Kubernetes.IngressClass
+	ing, err := kube.NewIngress(obj)
+	if err != nil || !c.isIngressEffective(ing) {
+		return
+	}
+	ev := kube.IngressEvent{
+		Key:          key,
+		GroupVersion: ing.GroupVersion(),
 	}
-	if ic != nil {
-		return *ic == c.controller.cfg.Kubernetes.IngressClass
+	if typ == types.EventUpdate {
+		ev.OldObject = ing
 	}
-	return false
+	c.workqueue.Add(&types.Event{
+		Type:   typ,
+		Object: ev,
+	})
+}
+
+// ingressObject returns the object of the Ingress in its own version.
+func ingressObject(ing kube.Ingress) kubeObject {
+	switch ing.GroupVersion() {
+	case kube.IngressV1:
+		return ing.V1()
+	case kube.IngressV1beta1:
+		return ing.V1beta1()
+	default:
+		return ing.ExtensionsV1beta1()
+	}
+}
+
+// isIngressEffective reports whether the Ingress should be handled, i.e. its
+// IngressClass (or the default one) has our controller name. If the class
+// object doesn't exist, the class name is compared with the configured
+// ingress class.
+func (c *ingressController) isIngressEffective(ing kube.Ingress) bool {
+	name, _ := c.controller.ingressClassOf(ing)
+	return name != "" && c.controller.isClassEffective(name)
 }
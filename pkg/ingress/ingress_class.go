@@ -0,0 +1,243 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package ingress
+
+import (
+	"fmt"
+
+	"go.uber.org/zap"
+	networkingv1 "k8s.io/api/networking/v1"
+	networkingv1beta1 "k8s.io/api/networking/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/apache/apisix-ingress-controller/pkg/kube"
+	"github.com/apache/apisix-ingress-controller/pkg/kube/translation"
+	"github.com/apache/apisix-ingress-controller/pkg/log"
+	"github.com/apache/apisix-ingress-controller/pkg/types"
+)
+
+const (
+	_ingressClassDefaultAnnotation = "ingressclass.kubernetes.io/is-default-class"
+
+	_apisixAPIGroup                = "apisix.apache.org"
+	_apisixClusterConfigKind       = "ApisixClusterConfig"
+	_ingressClassParametersCluster = "Cluster"
+)
+
+// ingressClass is the version independent view of the IngressClass.
+type ingressClass struct {
+	name       string
+	controller string
+	isDefault  bool
+	parameters *networkingv1.IngressClassParametersReference
+}
+
+func newIngressClass(obj interface{}) (*ingressClass, bool) {
+	switch ic := obj.(type) {
+	case *networkingv1.IngressClass:
+		return &ingressClass{
+			name:       ic.Name,
+			controller: ic.Spec.Controller,
+			isDefault:  ic.Annotations[_ingressClassDefaultAnnotation] == "true",
+			parameters: ic.Spec.Parameters,
+		}, true
+	case *networkingv1beta1.IngressClass:
+		class := &ingressClass{
+			name:       ic.Name,
+			controller: ic.Spec.Controller,
+			isDefault:  ic.Annotations[_ingressClassDefaultAnnotation] == "true",
+		}
+		if p := ic.Spec.Parameters; p != nil {
+			class.parameters = &networkingv1.IngressClassParametersReference{
+				APIGroup:  p.APIGroup,
+				Kind:      p.Kind,
+				Name:      p.Name,
+				Scope:     p.Scope,
+				Namespace: p.Namespace,
+			}
+		}
+		return class, true
+	default:
+		return nil, false
+	}
+}
+
+// watchIngressClasses re-translates Ingresses once IngressClasses change,
+// as the effectiveness and the APISIX clusters of Ingresses depend on them.
+func (c *Controller) watchIngressClasses() {
+	if c.ingressClassInformer == nil {
+		return
+	}
+	c.ingressClassInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: c.onIngressClassChange,
+		UpdateFunc: func(_, newObj interface{}) {
+			c.onIngressClassChange(newObj)
+		},
+		DeleteFunc: c.onIngressClassChange,
+	})
+}
+
+func (c *Controller) onIngressClassChange(obj interface{}) {
+	key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
+	if err != nil {
+		log.Errorf("found IngressClass resource with bad meta namespace key: %s", err)
+		return
+	}
+	log.Debugw("ingress class changed, resync ingresses",
+		zap.String("ingress class", key),
+	)
+	for _, obj := range c.ingressInformer.GetStore().List() {
+		key, err := cache.MetaNamespaceKeyFunc(obj)
+		if err != nil {
+			continue
+		}
+		if c.ingressStatusController != nil {
+			c.ingressStatusController.workqueue.Add(key)
+		}
+		ing, err := kube.NewIngress(obj)
+		if err != nil {
+			continue
+		}
+		if c.ingressController.isIngressEffective(ing) {
+			c.ingressController.resync(key, types.EventUpdate)
+			continue
+		}
+		// The Ingress is not handled anymore, e.g. the controller of its
+		// class is changed or the class is deleted, delete its resources.
+		if !c.namespaceWatching(key) || len(c.placedClusters(sourceKey(translation.SourceKindIngress, key))) == 0 {
+			continue
+		}
+		c.ingressController.workqueue.Add(&types.Event{
+			Type: types.EventDelete,
+			Object: kube.IngressEvent{
+				Key:          key,
+				GroupVersion: ing.GroupVersion(),
+			},
+			Tombstone: ing,
+		})
+	}
+}
+
+// getIngressClass returns the IngressClass by its name, nil is returned if
+// it doesn't exist or IngressClasses are not watched.
+func (c *Controller) getIngressClass(name string) *ingressClass {
+	if c.ingressClassInformer == nil {
+		return nil
+	}
+	obj, exists, err := c.ingressClassInformer.GetStore().GetByKey(name)
+	if err != nil || !exists {
+		return nil
+	}
+	class, _ := newIngressClass(obj)
+	return class
+}
+
+// defaultIngressClass returns the default IngressClass, nil is returned if
+// there is no default IngressClass or there are several of them.
+func (c *Controller) defaultIngressClass() *ingressClass {
+	if c.ingressClassInformer == nil {
+		return nil
+	}
+	var def *ingressClass
+	for _, obj := range c.ingressClassInformer.GetStore().List() {
+		class, ok := newIngressClass(obj)
+		if !ok || !class.isDefault {
+			continue
+		}
+		if def != nil {
+			return nil
+		}
+		def = class
+	}
+	return def
+}
+
+// ingressClassOf returns the IngressClass used by the Ingress, the name is
+// always returned even if the IngressClass object doesn't exist. An empty
+// name means the Ingress doesn't specify the class and there is no default
+// IngressClass.
+func (c *Controller) ingressClassOf(ing kube.Ingress) (string, *ingressClass) {
+	var (
+		ic  *string
+		ica string
+	)
+	switch ing.GroupVersion() {
+	case kube.IngressV1:
+		ic = ing.V1().Spec.IngressClassName
+		ica = ing.V1().GetAnnotations()[_ingressKey]
+	case kube.IngressV1beta1:
+		ic = ing.V1beta1().Spec.IngressClassName
+		ica = ing.V1beta1().GetAnnotations()[_ingressKey]
+	default:
+		ic = ing.ExtensionsV1beta1().Spec.IngressClassName
+		ica = ing.ExtensionsV1beta1().GetAnnotations()[_ingressKey]
+	}
+
+	// kubernetes.io/ingress.class takes the precedence.
+	if ica != "" {
+		return ica, c.getIngressClass(ica)
+	}
+	if ic != nil {
+		return *ic, c.getIngressClass(*ic)
+	}
+	if class := c.defaultIngressClass(); class != nil {
+		return class.name, class
+	}
+	return "", nil
+}
+
+// isClassEffective reports whether objects of the class should be handled,
+// the IngressClass decides it by its controller if it exists, otherwise
+// the class name is compared with the configured ingress class.
+func (c *Controller) isClassEffective(name string) bool {
+	if class := c.getIngressClass(name); class != nil {
+		return class.controller == c.cfg.Kubernetes.ControllerName
+	}
+	return name == c.cfg.Kubernetes.IngressClass
+}
+
+// isApisixObjectEffective reports whether the Apisix* object should be
+// handled according to its "kubernetes.io/ingress.class" annotation.
+func (c *Controller) isApisixObjectEffective(obj metav1.Object) bool {
+	class := obj.GetAnnotations()[_ingressKey]
+	if class == "" {
+		return c.cfg.Kubernetes.WatchApisixWithoutClass
+	}
+	return c.isClassEffective(class)
+}
+
+// ingressClassCluster returns the APISIX cluster referenced by parameters of
+// the IngressClass of the Ingress, ok is false if the parameters are not an
+// ApisixClusterConfig.
+func (c *Controller) ingressClassCluster(ing kube.Ingress) (cluster string, ok bool, err error) {
+	_, class := c.ingressClassOf(ing)
+	if class == nil || class.controller != c.cfg.Kubernetes.ControllerName || class.parameters == nil {
+		return "", false, nil
+	}
+	params := class.parameters
+	if params.APIGroup == nil || *params.APIGroup != _apisixAPIGroup || params.Kind != _apisixClusterConfigKind {
+		return "", false, nil
+	}
+	if params.Scope != nil && *params.Scope != _ingressClassParametersCluster {
+		return "", false, fmt.Errorf("IngressClass %s: %s parameters should be cluster scoped", class.name, _apisixClusterConfigKind)
+	}
+	for _, name := range c.clusterNames() {
+		if name == params.Name {
+			return name, true, nil
+		}
+	}
+	return "", false, fmt.Errorf("IngressClass %s: %s %s not found", class.name, _apisixClusterConfigKind, params.Name)
+}
@@ -0,0 +1,255 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package ingress
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+
+	"github.com/apache/apisix-ingress-controller/pkg/config"
+	"github.com/apache/apisix-ingress-controller/pkg/kube"
+	configv1 "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/apis/config/v1"
+	"github.com/apache/apisix-ingress-controller/pkg/kube/translation"
+	"github.com/apache/apisix-ingress-controller/pkg/types"
+)
+
+func newIngressClassObject(name, controller string, isDefault bool) *networkingv1.IngressClass {
+	ic := &networkingv1.IngressClass{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Annotations: map[string]string{},
+		},
+		Spec: networkingv1.IngressClassSpec{
+			Controller: controller,
+		},
+	}
+	if isDefault {
+		ic.Annotations[_ingressClassDefaultAnnotation] = "true"
+	}
+	return ic
+}
+
+func TestIsIngressEffectiveWithIngressClass(t *testing.T) {
+	informer := cache.NewSharedIndexInformer(&cache.ListWatch{}, &networkingv1.IngressClass{}, 0, cache.Indexers{})
+	c := &ingressController{
+		controller: &Controller{
+			cfg:                  config.NewDefaultConfig(),
+			ingressClassInformer: informer,
+		},
+	}
+	newIngress := func(class string) kube.Ingress {
+		ing := &networkingv1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "foo",
+				Namespace: "default",
+			},
+		}
+		if class != "" {
+			ing.Spec.IngressClassName = &class
+		}
+		return kube.MustNewIngress(ing)
+	}
+
+	// No IngressClass objects, compare with the configured class.
+	assert.True(t, c.isIngressEffective(newIngress("apisix")))
+	assert.False(t, c.isIngressEffective(newIngress("nginx")))
+	assert.False(t, c.isIngressEffective(newIngress("")))
+
+	assert.Nil(t, informer.GetStore().Add(newIngressClassObject("apisix", "k8s.io/ingress-nginx", false)))
+	assert.Nil(t, informer.GetStore().Add(newIngressClassObject("gateway", config.ControllerName, false)))
+	// The class object decides by its controller.
+	assert.False(t, c.isIngressEffective(newIngress("apisix")))
+	assert.True(t, c.isIngressEffective(newIngress("gateway")))
+	assert.False(t, c.isIngressEffective(newIngress("")))
+
+	assert.Nil(t, informer.GetStore().Update(newIngressClassObject("gateway", config.ControllerName, true)))
+	assert.True(t, c.isIngressEffective(newIngress("")))
+
+	// Ambiguous default classes.
+	assert.Nil(t, informer.GetStore().Update(newIngressClassObject("apisix", "k8s.io/ingress-nginx", true)))
+	assert.False(t, c.isIngressEffective(newIngress("")))
+}
+
+func TestIngressClassDeleted(t *testing.T) {
+	c := &Controller{
+		cfg:                  config.NewDefaultConfig(),
+		ingressClassInformer: cache.NewSharedIndexInformer(&cache.ListWatch{}, &networkingv1.IngressClass{}, 0, cache.Indexers{}),
+		ingressInformer:      cache.NewSharedIndexInformer(&cache.ListWatch{}, &networkingv1.Ingress{}, 0, cache.Indexers{}),
+		sourceClusters:       new(sync.Map),
+	}
+	c.ingressController = &ingressController{
+		controller: c,
+		workqueue:  workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter()),
+	}
+	class := "gateway"
+	ing := &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "foo",
+			Namespace: "default",
+		},
+		Spec: networkingv1.IngressSpec{
+			IngressClassName: &class,
+		},
+	}
+	ic := newIngressClassObject(class, config.ControllerName, false)
+	assert.Nil(t, c.ingressClassInformer.GetStore().Add(ic))
+	assert.Nil(t, c.ingressInformer.GetStore().Add(ing))
+
+	// The Ingress is translated again while it's still effective.
+	c.onIngressClassChange(ic)
+	obj, _ := c.ingressController.workqueue.Get()
+	ev := obj.(*types.Event)
+	assert.True(t, ev.Type == types.EventUpdate)
+	c.ingressController.workqueue.Done(obj)
+
+	// Nothing to delete if resources of the Ingress were not pushed.
+	assert.Nil(t, c.ingressClassInformer.GetStore().Delete(ic))
+	c.onIngressClassChange(ic)
+	assert.Equal(t, 0, c.ingressController.workqueue.Len())
+
+	c.placeSource(sourceKey(translation.SourceKindIngress, "default/foo"), []string{"default"})
+	c.onIngressClassChange(ic)
+	obj, _ = c.ingressController.workqueue.Get()
+	ev = obj.(*types.Event)
+	assert.True(t, ev.Type == types.EventDelete)
+	assert.Equal(t, "default/foo", ev.Object.(kube.IngressEvent).Key)
+	assert.Equal(t, kube.MustNewIngress(ing), ev.Tombstone)
+}
+
+func TestSelectClustersByIngressClass(t *testing.T) {
+	c := newClusterSelectorController(t)
+	c.cfg.Kubernetes.ControllerName = config.ControllerName
+	informer := cache.NewSharedIndexInformer(&cache.ListWatch{}, &networkingv1.IngressClass{}, 0, cache.Indexers{})
+	c.ingressClassInformer = informer
+
+	apiGroup := _apisixAPIGroup
+	ic := newIngressClassObject("external", config.ControllerName, false)
+	ic.Spec.Parameters = &networkingv1.IngressClassParametersReference{
+		APIGroup: &apiGroup,
+		Kind:     _apisixClusterConfigKind,
+		Name:     "external",
+	}
+	assert.Nil(t, informer.GetStore().Add(ic))
+
+	class := "external"
+	ing := &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "foo",
+			Namespace: "default",
+		},
+		Spec: networkingv1.IngressSpec{
+			IngressClassName: &class,
+		},
+	}
+	clusters, err := c.selectClusters(ing)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"external"}, clusters)
+
+	// The annotation takes precedence.
+	ing.Annotations = map[string]string{_clusterSelectorAnnotation: "traffic=internal"}
+	clusters, err = c.selectClusters(ing)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"default"}, clusters)
+	ing.Annotations = nil
+
+	// Not a cluster.
+	ic = ic.DeepCopy()
+	ic.Spec.Parameters.Name = "staging"
+	assert.Nil(t, informer.GetStore().Update(ic))
+	_, err = c.selectClusters(ing)
+	assert.Equal(t, "IngressClass external: ApisixClusterConfig staging not found", err.Error())
+
+	// Parameters of other kinds are ignored.
+	ic = ic.DeepCopy()
+	ic.Spec.Parameters.Kind = "ConfigMap"
+	assert.Nil(t, informer.GetStore().Update(ic))
+	clusters, err = c.selectClusters(ing)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"default"}, clusters)
+}
+
+func TestIsApisixObjectEffective(t *testing.T) {
+	informer := cache.NewSharedIndexInformer(&cache.ListWatch{}, &networkingv1.IngressClass{}, 0, cache.Indexers{})
+	c := &Controller{
+		cfg:                  config.NewDefaultConfig(),
+		ingressClassInformer: informer,
+	}
+	newTls := func(class string) *configv1.ApisixTls {
+		tls := &configv1.ApisixTls{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "foo",
+				Namespace: "default",
+			},
+		}
+		if class != "" {
+			tls.Annotations = map[string]string{_ingressKey: class}
+		}
+		return tls
+	}
+
+	assert.True(t, c.isApisixObjectEffective(newTls("")))
+	assert.True(t, c.isApisixObjectEffective(newTls("apisix")))
+	assert.False(t, c.isApisixObjectEffective(newTls("internal")))
+
+	c.cfg.Kubernetes.WatchApisixWithoutClass = false
+	assert.False(t, c.isApisixObjectEffective(newTls("")))
+
+	assert.Nil(t, informer.GetStore().Add(newIngressClassObject("internal", config.ControllerName, false)))
+	assert.True(t, c.isApisixObjectEffective(newTls("internal")))
+}
+
+func TestApisixTlsClassChange(t *testing.T) {
+	c := &apisixTlsController{
+		controller: &Controller{
+			cfg: config.NewDefaultConfig(),
+		},
+		workqueue: workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter()),
+	}
+	prev := &configv1.ApisixTls{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "foo",
+			Namespace:       "default",
+			ResourceVersion: "1",
+			Annotations:     map[string]string{_ingressKey: "apisix"},
+		},
+	}
+	curr := prev.DeepCopy()
+	curr.ResourceVersion = "2"
+	curr.Annotations[_ingressKey] = "nginx"
+
+	// Objects of other classes are ignored.
+	c.onAdd(curr)
+	c.onDelete(curr)
+	assert.Equal(t, 0, c.workqueue.Len())
+
+	// Resources are deleted once the object is not handled by us anymore.
+	c.onUpdate(prev, curr)
+	obj, _ := c.workqueue.Get()
+	ev := obj.(*types.Event)
+	assert.True(t, ev.Type == types.EventDelete)
+	assert.Equal(t, "default/foo", ev.Object)
+	assert.Equal(t, prev, ev.Tombstone)
+
+	next := curr.DeepCopy()
+	next.ResourceVersion = "3"
+	c.onUpdate(curr, next)
+	assert.Equal(t, 0, c.workqueue.Len())
+}
@@ -0,0 +1,323 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package ingress
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"reflect"
+	"sort"
+	"time"
+
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/client-go/util/workqueue"
+
+	"github.com/apache/apisix-ingress-controller/pkg/kube"
+	"github.com/apache/apisix-ingress-controller/pkg/log"
+)
+
+// ingressStatusController keeps status.loadBalancer of effective Ingresses
+// in sync with addresses of the APISIX gateway, it's created only if the
+// publish service or the publish status address is configured.
+type ingressStatusController struct {
+	controller *Controller
+	workqueue  workqueue.RateLimitingInterface
+	workers    int
+}
+
+func (c *Controller) newIngressStatusController() *ingressStatusController {
+	if c.cfg.Kubernetes.PublishService == "" && len(c.cfg.Kubernetes.PublishStatusAddress) == 0 {
+		return nil
+	}
+	ctl := &ingressStatusController{
+		controller: c,
+		workqueue:  workqueue.NewNamedRateLimitingQueue(workqueue.NewItemFastSlowRateLimiter(time.Second, 60*time.Second, 5), "IngressStatus"),
+		workers:    1,
+	}
+	c.ingressInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: ctl.onIngressChange,
+		UpdateFunc: func(_, newObj interface{}) {
+			ctl.onIngressChange(newObj)
+		},
+	})
+	if len(c.cfg.Kubernetes.PublishStatusAddress) == 0 {
+		c.svcInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+			AddFunc: ctl.onServiceChange,
+			UpdateFunc: func(_, newObj interface{}) {
+				ctl.onServiceChange(newObj)
+			},
+			DeleteFunc: ctl.onServiceChange,
+		})
+	}
+	return ctl
+}
+
+func (c *ingressStatusController) run(ctx context.Context) {
+	log.Info("ingress status controller started")
+	defer log.Info("ingress status controller exited")
+	defer c.workqueue.ShutDown()
+
+	if ok := cache.WaitForCacheSync(ctx.Done(), c.controller.ingressInformer.HasSynced, c.controller.svcInformer.HasSynced); !ok {
+		log.Error("cache sync failed")
+		return
+	}
+	for i := 0; i < c.workers; i++ {
+		go c.runWorker(ctx)
+	}
+	<-ctx.Done()
+}
+
+func (c *ingressStatusController) runWorker(ctx context.Context) {
+	for {
+		obj, quit := c.workqueue.Get()
+		if quit {
+			return
+		}
+		err := c.sync(ctx, obj.(string))
+		c.workqueue.Done(obj)
+		c.handleSyncErr(obj, err)
+	}
+}
+
+// sync writes the gateway addresses to the status of the Ingress if it's
+// effective, or clears the addresses written before if it's not.
+func (c *ingressStatusController) sync(ctx context.Context, key string) error {
+	obj, exists, err := c.controller.ingressInformer.GetStore().GetByKey(key)
+	if err != nil || !exists {
+		return err
+	}
+	ing, err := kube.NewIngress(obj)
+	if err != nil {
+		return nil
+	}
+	addrs, err := c.controller.publishAddresses()
+	if err != nil {
+		return err
+	}
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		return c.patchLoadBalancer(ctx, ing.GroupVersion(), key, addrs)
+	})
+}
+
+// patchLoadBalancer fetches the Ingress from the API server rather than
+// informers, and patches its load balancer status if it's changed.
+func (c *ingressStatusController) patchLoadBalancer(ctx context.Context, groupVersion, key string, addrs []corev1.LoadBalancerIngress) error {
+	ing, err := c.controller.getIngress(ctx, groupVersion, key)
+	if err != nil {
+		return err
+	}
+	current := ingressLoadBalancer(ing)
+	desired := addrs
+	if !c.controller.namespaceWatching(key) || !c.controller.ingressController.isIngressEffective(ing) {
+		// Only clear the addresses written by us, the Ingress might be
+		// owned by other controllers.
+		if len(current) == 0 || !loadBalancerEqual(current, addrs) {
+			return nil
+		}
+		desired = nil
+	}
+	if loadBalancerEqual(current, desired) {
+		return nil
+	}
+	log.Debugw("updating ingress load balancer status",
+		zap.String("ingress", key),
+		zap.Any("addresses", desired),
+	)
+	return c.controller.patchIngressLoadBalancer(ctx, ing, desired)
+}
+
+func (c *ingressStatusController) handleSyncErr(obj interface{}, err error) {
+	if err == nil || k8serrors.IsNotFound(err) {
+		c.workqueue.Forget(obj)
+		return
+	}
+	log.Warnw("failed to update ingress status, will retry",
+		zap.Any("object", obj),
+		zap.Error(err),
+	)
+	c.workqueue.AddRateLimited(obj)
+}
+
+func (c *ingressStatusController) onIngressChange(obj interface{}) {
+	key, err := cache.MetaNamespaceKeyFunc(obj)
+	if err != nil {
+		log.Errorf("found ingress resource with bad meta namespace key: %s", err)
+		return
+	}
+	c.workqueue.Add(key)
+}
+
+// onServiceChange enqueues all Ingresses once the publish service changes.
+func (c *ingressStatusController) onServiceChange(obj interface{}) {
+	key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
+	if err != nil || key != c.controller.cfg.Kubernetes.PublishService {
+		return
+	}
+	for _, key := range c.controller.ingressInformer.GetStore().ListKeys() {
+		c.workqueue.Add(key)
+	}
+}
+
+// publishAddresses returns the sorted addresses of the APISIX gateway,
+// either the static ones or the ones of the publish service.
+func (c *Controller) publishAddresses() ([]corev1.LoadBalancerIngress, error) {
+	var addrs []corev1.LoadBalancerIngress
+	if static := c.cfg.Kubernetes.PublishStatusAddress; len(static) > 0 {
+		for _, addr := range static {
+			if net.ParseIP(addr) != nil {
+				addrs = append(addrs, corev1.LoadBalancerIngress{IP: addr})
+			} else {
+				addrs = append(addrs, corev1.LoadBalancerIngress{Hostname: addr})
+			}
+		}
+		sortLoadBalancer(addrs)
+		return addrs, nil
+	}
+
+	obj, exists, err := c.svcInformer.GetStore().GetByKey(c.cfg.Kubernetes.PublishService)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		log.Warnw("publish service not found",
+			zap.String("service", c.cfg.Kubernetes.PublishService),
+		)
+		return nil, nil
+	}
+	svc := obj.(*corev1.Service)
+	if svc.Spec.Type == corev1.ServiceTypeLoadBalancer {
+		for _, lb := range svc.Status.LoadBalancer.Ingress {
+			addrs = append(addrs, corev1.LoadBalancerIngress{IP: lb.IP, Hostname: lb.Hostname})
+		}
+	}
+	if len(addrs) == 0 {
+		for _, ip := range svc.Spec.ExternalIPs {
+			addrs = append(addrs, corev1.LoadBalancerIngress{IP: ip})
+		}
+	}
+	sortLoadBalancer(addrs)
+	return addrs, nil
+}
+
+// getIngress fetches the latest Ingress from the API server.
+func (c *Controller) getIngress(ctx context.Context, groupVersion, key string) (kube.Ingress, error) {
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		return nil, err
+	}
+	var obj interface{}
+	switch groupVersion {
+	case kube.IngressV1:
+		obj, err = c.kubeClient.Client.NetworkingV1().Ingresses(namespace).Get(ctx, name, metav1.GetOptions{})
+	case kube.IngressV1beta1:
+		obj, err = c.kubeClient.Client.NetworkingV1beta1().Ingresses(namespace).Get(ctx, name, metav1.GetOptions{})
+	default:
+		obj, err = c.kubeClient.Client.ExtensionsV1beta1().Ingresses(namespace).Get(ctx, name, metav1.GetOptions{})
+	}
+	if err != nil {
+		return nil, err
+	}
+	return kube.NewIngress(obj)
+}
+
+// ingressStatusPatch is the merge patch of status.loadBalancer, the
+// resource version makes the patch fail with a conflict if the Ingress is
+// changed.
+type ingressStatusPatch struct {
+	Metadata statusPatchMetadata      `json:"metadata"`
+	Status   ingressStatusPatchStatus `json:"status"`
+}
+
+type ingressStatusPatchStatus struct {
+	LoadBalancer ingressStatusPatchLoadBalancer `json:"loadBalancer"`
+}
+
+type ingressStatusPatchLoadBalancer struct {
+	Ingress []corev1.LoadBalancerIngress `json:"ingress"`
+}
+
+// patchIngressLoadBalancer replaces status.loadBalancer.ingress of the
+// Ingress.
+func (c *Controller) patchIngressLoadBalancer(ctx context.Context, ing kube.Ingress, addrs []corev1.LoadBalancerIngress) error {
+	obj := ingressObject(ing)
+	patch := &ingressStatusPatch{
+		Metadata: statusPatchMetadata{ResourceVersion: obj.GetResourceVersion()},
+		Status: ingressStatusPatchStatus{
+			LoadBalancer: ingressStatusPatchLoadBalancer{Ingress: addrs},
+		},
+	}
+	data, err := json.Marshal(patch)
+	if err != nil {
+		return err
+	}
+	var (
+		namespace = obj.GetNamespace()
+		name      = obj.GetName()
+		pt        = k8stypes.MergePatchType
+		opts      = metav1.PatchOptions{}
+	)
+	switch ing.GroupVersion() {
+	case kube.IngressV1:
+		_, err = c.kubeClient.Client.NetworkingV1().Ingresses(namespace).Patch(ctx, name, pt, data, opts, "status")
+	case kube.IngressV1beta1:
+		_, err = c.kubeClient.Client.NetworkingV1beta1().Ingresses(namespace).Patch(ctx, name, pt, data, opts, "status")
+	default:
+		_, err = c.kubeClient.Client.ExtensionsV1beta1().Ingresses(namespace).Patch(ctx, name, pt, data, opts, "status")
+	}
+	return err
+}
+
+// ingressLoadBalancer returns the sorted status.loadBalancer.ingress of the
+// Ingress.
+func ingressLoadBalancer(ing kube.Ingress) []corev1.LoadBalancerIngress {
+	var lb []corev1.LoadBalancerIngress
+	switch ing.GroupVersion() {
+	case kube.IngressV1:
+		lb = ing.V1().Status.LoadBalancer.Ingress
+	case kube.IngressV1beta1:
+		lb = ing.V1beta1().Status.LoadBalancer.Ingress
+	default:
+		lb = ing.ExtensionsV1beta1().Status.LoadBalancer.Ingress
+	}
+	addrs := make([]corev1.LoadBalancerIngress, 0, len(lb))
+	for _, addr := range lb {
+		addrs = append(addrs, corev1.LoadBalancerIngress{IP: addr.IP, Hostname: addr.Hostname})
+	}
+	sortLoadBalancer(addrs)
+	return addrs
+}
+
+func sortLoadBalancer(addrs []corev1.LoadBalancerIngress) {
+	sort.Slice(addrs, func(i, j int) bool {
+		if addrs[i].IP != addrs[j].IP {
+			return addrs[i].IP < addrs[j].IP
+		}
+		return addrs[i].Hostname < addrs[j].Hostname
+	})
+}
+
+func loadBalancerEqual(a, b []corev1.LoadBalancerIngress) bool {
+	if len(a) == 0 && len(b) == 0 {
+		return true
+	}
+	return reflect.DeepEqual(a, b)
+}
@@ -0,0 +1,164 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package ingress
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/apache/apisix-ingress-controller/pkg/config"
+	"github.com/apache/apisix-ingress-controller/pkg/kube"
+)
+
+func TestPublishAddresses(t *testing.T) {
+	svcInformer := cache.NewSharedIndexInformer(&cache.ListWatch{}, &corev1.Service{}, 0, cache.Indexers{})
+	c := &Controller{
+		cfg:         config.NewDefaultConfig(),
+		svcInformer: svcInformer,
+	}
+	c.cfg.Kubernetes.PublishStatusAddress = []string{"lb.example.com", "10.0.0.2", "10.0.0.1"}
+	addrs, err := c.publishAddresses()
+	assert.Nil(t, err)
+	assert.Equal(t, []corev1.LoadBalancerIngress{
+		{Hostname: "lb.example.com"},
+		{IP: "10.0.0.1"},
+		{IP: "10.0.0.2"},
+	}, addrs)
+
+	c.cfg.Kubernetes.PublishStatusAddress = nil
+	c.cfg.Kubernetes.PublishService = "apisix/apisix-gateway"
+	addrs, err = c.publishAddresses()
+	assert.Nil(t, err)
+	assert.Len(t, addrs, 0)
+
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "apisix-gateway",
+			Namespace: "apisix",
+		},
+		Spec: corev1.ServiceSpec{
+			Type:        corev1.ServiceTypeLoadBalancer,
+			ExternalIPs: []string{"192.168.0.1"},
+		},
+	}
+	assert.Nil(t, svcInformer.GetStore().Add(svc))
+	// The load balancer is not ready, fallback to the external IPs.
+	addrs, err = c.publishAddresses()
+	assert.Nil(t, err)
+	assert.Equal(t, []corev1.LoadBalancerIngress{{IP: "192.168.0.1"}}, addrs)
+
+	svc.Status.LoadBalancer.Ingress = []corev1.LoadBalancerIngress{{Hostname: "gw.example.com"}}
+	addrs, err = c.publishAddresses()
+	assert.Nil(t, err)
+	assert.Equal(t, []corev1.LoadBalancerIngress{{Hostname: "gw.example.com"}}, addrs)
+}
+
+func TestIngressStatusSync(t *testing.T) {
+	ing := &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "foo",
+			Namespace: "default",
+			Annotations: map[string]string{
+				_ingressKey: "apisix",
+			},
+		},
+	}
+	client := fake.NewSimpleClientset(ing)
+	ingressInformer := cache.NewSharedIndexInformer(&cache.ListWatch{}, &networkingv1.Ingress{}, 0, cache.Indexers{})
+	assert.Nil(t, ingressInformer.GetStore().Add(ing))
+
+	c := &Controller{
+		cfg:             config.NewDefaultConfig(),
+		kubeClient:      &kube.KubeClient{Client: client},
+		ingressInformer: ingressInformer,
+	}
+	c.cfg.Kubernetes.PublishStatusAddress = []string{"10.0.0.1"}
+	c.ingressController = &ingressController{controller: c}
+	ctl := &ingressStatusController{controller: c}
+
+	getStatus := func() []corev1.LoadBalancerIngress {
+		obj, err := client.NetworkingV1().Ingresses("default").Get(context.Background(), "foo", metav1.GetOptions{})
+		assert.Nil(t, err)
+		// Reflect the update to the cache.
+		assert.Nil(t, ingressInformer.GetStore().Update(obj))
+		return obj.Status.LoadBalancer.Ingress
+	}
+
+	assert.Nil(t, ctl.sync(context.Background(), "default/foo"))
+	assert.Equal(t, []corev1.LoadBalancerIngress{{IP: "10.0.0.1"}}, getStatus())
+
+	// The Ingress doesn't match the class anymore.
+	obj, _, _ := ingressInformer.GetStore().GetByKey("default/foo")
+	ing = obj.(*networkingv1.Ingress).DeepCopy()
+	ing.Annotations[_ingressKey] = "nginx"
+	_, err := client.NetworkingV1().Ingresses("default").Update(context.Background(), ing, metav1.UpdateOptions{})
+	assert.Nil(t, err)
+	getStatus()
+	assert.Nil(t, ctl.sync(context.Background(), "default/foo"))
+	assert.Len(t, getStatus(), 0)
+
+	// Addresses written by other controllers are kept.
+	ing.Status.LoadBalancer.Ingress = []corev1.LoadBalancerIngress{{IP: "10.0.0.9"}}
+	_, err = client.NetworkingV1().Ingresses("default").UpdateStatus(context.Background(), ing, metav1.UpdateOptions{})
+	assert.Nil(t, err)
+	getStatus()
+	assert.Nil(t, ctl.sync(context.Background(), "default/foo"))
+	assert.Equal(t, []corev1.LoadBalancerIngress{{IP: "10.0.0.9"}}, getStatus())
+}
+
+func TestIngressStatusSyncWithStaleCache(t *testing.T) {
+	ing := &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "foo",
+			Namespace: "default",
+			Annotations: map[string]string{
+				_ingressKey: "apisix",
+			},
+		},
+	}
+	client := fake.NewSimpleClientset(ing)
+	ingressInformer := cache.NewSharedIndexInformer(&cache.ListWatch{}, &networkingv1.Ingress{}, 0, cache.Indexers{})
+	assert.Nil(t, ingressInformer.GetStore().Add(ing))
+
+	c := &Controller{
+		cfg:             config.NewDefaultConfig(),
+		kubeClient:      &kube.KubeClient{Client: client},
+		ingressInformer: ingressInformer,
+	}
+	c.cfg.Kubernetes.PublishStatusAddress = []string{"10.0.0.1"}
+	c.ingressController = &ingressController{controller: c}
+	ctl := &ingressStatusController{controller: c}
+
+	// The status is written but the informer is not updated yet.
+	assert.Nil(t, ctl.sync(context.Background(), "default/foo"))
+	client.ClearActions()
+	assert.Nil(t, ctl.sync(context.Background(), "default/foo"))
+	for _, action := range client.Actions() {
+		assert.Equal(t, "get", action.GetVerb())
+	}
+
+	latest, err := client.NetworkingV1().Ingresses("default").Get(context.Background(), "foo", metav1.GetOptions{})
+	assert.Nil(t, err)
+	assert.Equal(t, []corev1.LoadBalancerIngress{{IP: "10.0.0.1"}}, latest.Status.LoadBalancer.Ingress)
+	// The object in the informer is not mutated.
+	assert.Len(t, ing.Status.LoadBalancer.Ingress, 0)
+}
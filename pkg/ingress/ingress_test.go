@@ -15,6 +15,7 @@
 package ingress
 
 import (
+	"context"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -22,9 +23,16 @@ import (
 	networkingv1 "k8s.io/api/networking/v1"
 	networkingv1beta1 "k8s.io/api/networking/v1beta1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	listersextensionsv1beta1 "k8s.io/client-go/listers/extensions/v1beta1"
+	listersnetworkingv1 "k8s.io/client-go/listers/networking/v1"
+	listersnetworkingv1beta1 "k8s.io/client-go/listers/networking/v1beta1"
+	"k8s.io/client-go/tools/cache"
 
+	apisixcache "github.com/apache/apisix-ingress-controller/pkg/apisix/cache"
 	"github.com/apache/apisix-ingress-controller/pkg/config"
 	"github.com/apache/apisix-ingress-controller/pkg/kube"
+	"github.com/apache/apisix-ingress-controller/pkg/types"
+	apisixv1 "github.com/apache/apisix-ingress-controller/pkg/types/apisix/v1"
 )
 
 func TestIsIngressEffective(t *testing.T) {
@@ -169,3 +177,62 @@ func TestIsIngressEffective(t *testing.T) {
 	// Spec.IngressClassName takes the precedence.
 	assert.Equal(t, c.isIngressEffective(ing), false)
 }
+
+func TestIngressDeleteWithoutTranslation(t *testing.T) {
+	c := newClusterSelectorController(t)
+	cli := &fakeAPISIX{
+		clusters: map[string]*fakeCluster{
+			"default":  newFakeCluster(t),
+			"external": newFakeCluster(t),
+		},
+	}
+	c.apisix = cli
+	c.dependencyIndex = newDependencyIndex()
+	c.ingressClassInformer = cache.NewSharedIndexInformer(&cache.ListWatch{}, &networkingv1.IngressClass{}, 0, cache.Indexers{})
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	c.ingressLister = kube.NewIngressLister(
+		listersnetworkingv1.NewIngressLister(indexer),
+		listersnetworkingv1beta1.NewIngressLister(indexer),
+		listersextensionsv1beta1.NewIngressLister(indexer),
+	)
+	ic := &ingressController{controller: c}
+
+	labels := map[string]string{
+		apisixv1.LabelSourceKind:      "Ingress",
+		apisixv1.LabelSourceNamespace: "default",
+		apisixv1.LabelSourceName:      "foo",
+	}
+	db := cli.clusters["default"].cache
+	assert.Nil(t, db.InsertUpstream(&apisixv1.Upstream{Metadata: apisixv1.Metadata{ID: "u1", Labels: labels}}))
+	assert.Nil(t, db.InsertRoute(&apisixv1.Route{Metadata: apisixv1.Metadata{ID: "r1", Labels: labels}, UpstreamId: "u1"}))
+	assert.Nil(t, db.InsertSSL(&apisixv1.Ssl{ID: "s1", Labels: labels}))
+
+	// The Secret and the Service are deleted together with the Ingress, the
+	// translator (nil here) is not used.
+	ing := &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "foo",
+			Namespace: "default",
+		},
+		Spec: networkingv1.IngressSpec{
+			TLS: []networkingv1.IngressTLS{
+				{Hosts: []string{"foo.com"}, SecretName: "foo-cert"},
+			},
+		},
+	}
+	err := ic.sync(context.Background(), &types.Event{
+		Type: types.EventDelete,
+		Object: kube.IngressEvent{
+			Key:          "default/foo",
+			GroupVersion: kube.IngressV1,
+		},
+		Tombstone: kube.MustNewIngress(ing),
+	})
+	assert.Nil(t, err)
+	_, err = db.GetRoute("r1")
+	assert.Equal(t, apisixcache.ErrNotFound, err)
+	_, err = db.GetUpstream("u1")
+	assert.Equal(t, apisixcache.ErrNotFound, err)
+	_, err = db.GetSSL("s1")
+	assert.Equal(t, apisixcache.ErrNotFound, err)
+}
@@ -0,0 +1,104 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package ingress
+
+import (
+	"fmt"
+
+	"github.com/apache/apisix-ingress-controller/pkg/kube"
+	"github.com/apache/apisix-ingress-controller/pkg/kube/translation"
+)
+
+const (
+	// _tlsHostConflict is used when TLS hosts of an Ingress are taken by
+	// another Ingress.
+	_tlsHostConflict = "TLSHostConflict"
+	// _dependencyKindTLSHost is the kind of TLS hosts in the dependency
+	// index, so that Ingresses are re-resolved once TLS hosts of others
+	// change.
+	_dependencyKindTLSHost = "IngressTLSHost"
+)
+
+// ingressTLSHosts returns the hosts of TLS entries in the Ingress.
+func ingressTLSHosts(ing kube.Ingress) []string {
+	var entries [][]string
+	switch ing.GroupVersion() {
+	case kube.IngressV1:
+		for _, tls := range ing.V1().Spec.TLS {
+			entries = append(entries, tls.Hosts)
+		}
+	case kube.IngressV1beta1:
+		for _, tls := range ing.V1beta1().Spec.TLS {
+			entries = append(entries, tls.Hosts)
+		}
+	default:
+		for _, tls := range ing.ExtensionsV1beta1().Spec.TLS {
+			entries = append(entries, tls.Hosts)
+		}
+	}
+	var hosts []string
+	seen := make(map[string]struct{})
+	for _, entry := range entries {
+		for _, host := range entry {
+			if _, ok := seen[host]; !ok {
+				seen[host] = struct{}{}
+				hosts = append(hosts, host)
+			}
+		}
+	}
+	return hosts
+}
+
+// resolveTLSHosts removes SNIs which are taken by other Ingresses from the
+// SSL objects in the translate context, SSL objects are dropped if no SNI
+// is left. So that there is only one SSL object for each host. The
+// conflicts are returned.
+func (c *ingressController) resolveTLSHosts(ing kube.Ingress, key string, tctx *translation.TranslateContext) []string {
+	if len(tctx.SSLs) == 0 {
+		return nil
+	}
+	// host -> key of the Ingress which takes it.
+	taken := make(map[string]string)
+	c.forEachPrecedingIngress(ing, key, func(other kube.Ingress, otherKey string) {
+		for _, host := range ingressTLSHosts(other) {
+			if prev, ok := taken[host]; !ok || otherKey < prev {
+				taken[host] = otherKey
+			}
+		}
+	})
+	if len(taken) == 0 {
+		return nil
+	}
+
+	var conflicts []string
+	ssls := tctx.SSLs[:0]
+	for _, ssl := range tctx.SSLs {
+		var snis []string
+		for _, sni := range ssl.Snis {
+			if winner, ok := taken[sni]; ok {
+				conflicts = append(conflicts, fmt.Sprintf("%s (taken by Ingress %s)", sni, winner))
+			} else {
+				snis = append(snis, sni)
+			}
+		}
+		if len(snis) == 0 {
+			continue
+		}
+		ssl.Snis = snis
+		ssls = append(ssls, ssl)
+	}
+	tctx.SSLs = ssls
+	return conflicts
+}
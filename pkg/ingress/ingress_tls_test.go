@@ -0,0 +1,97 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package ingress
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/apache/apisix-ingress-controller/pkg/config"
+	"github.com/apache/apisix-ingress-controller/pkg/kube"
+	"github.com/apache/apisix-ingress-controller/pkg/kube/translation"
+	apisixv1 "github.com/apache/apisix-ingress-controller/pkg/types/apisix/v1"
+)
+
+func newTLSIngress(name string, created time.Time, hosts ...string) *networkingv1.Ingress {
+	return &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         "default",
+			CreationTimestamp: metav1.NewTime(created),
+			Annotations: map[string]string{
+				_ingressKey: "apisix",
+			},
+		},
+		Spec: networkingv1.IngressSpec{
+			TLS: []networkingv1.IngressTLS{
+				{Hosts: hosts, SecretName: name + "-cert"},
+			},
+		},
+	}
+}
+
+func TestResolveTLSHosts(t *testing.T) {
+	now := time.Now()
+	informer := cache.NewSharedIndexInformer(&cache.ListWatch{}, &networkingv1.Ingress{}, 0, cache.Indexers{})
+	c := &ingressController{
+		controller: &Controller{
+			cfg:             config.NewDefaultConfig(),
+			ingressInformer: informer,
+		},
+	}
+	resolve := func(ing *networkingv1.Ingress) ([]string, *translation.TranslateContext) {
+		tctx := &translation.TranslateContext{
+			SSLs: []*apisixv1.Ssl{
+				{ID: ing.Name, Snis: append([]string(nil), ing.Spec.TLS[0].Hosts...)},
+			},
+		}
+		conflicts := c.resolveTLSHosts(kube.MustNewIngress(ing), ing.Namespace+"/"+ing.Name, tctx)
+		return conflicts, tctx
+	}
+
+	old := newTLSIngress("old", now.Add(-time.Hour), "foo.com")
+	assert.Nil(t, informer.GetStore().Add(old))
+
+	// The older Ingress keeps its hosts.
+	conflicts, tctx := resolve(old)
+	assert.Nil(t, conflicts)
+	assert.Equal(t, []string{"foo.com"}, tctx.SSLs[0].Snis)
+
+	// Only the conflicted host is removed.
+	ing := newTLSIngress("new", now, "foo.com", "bar.com")
+	conflicts, tctx = resolve(ing)
+	assert.Equal(t, []string{"foo.com (taken by Ingress default/old)"}, conflicts)
+	assert.Equal(t, []string{"bar.com"}, tctx.SSLs[0].Snis)
+
+	// All hosts are taken, the SSL is dropped.
+	ing = newTLSIngress("new", now, "foo.com")
+	conflicts, tctx = resolve(ing)
+	assert.Len(t, conflicts, 1)
+	assert.Len(t, tctx.SSLs, 0)
+
+	assert.Equal(t, []string{"foo.com", "bar.com"}, ingressTLSHosts(kube.MustNewIngress(&networkingv1.Ingress{
+		Spec: networkingv1.IngressSpec{
+			TLS: []networkingv1.IngressTLS{
+				{Hosts: []string{"foo.com"}},
+				{Hosts: []string{"foo.com", "bar.com"}},
+			},
+		},
+	})))
+}
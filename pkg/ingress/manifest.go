@@ -108,44 +108,193 @@ func diffStreamRoutes(olds, news []*apisixv1.StreamRoute) (added, updated, delet
 	return
 }
 
+func diffSSLs(olds, news []*apisixv1.Ssl) (added, updated, deleted []*apisixv1.Ssl) {
+	oldMap := make(map[string]*apisixv1.Ssl, len(olds))
+	newMap := make(map[string]*apisixv1.Ssl, len(news))
+	for _, ssl := range olds {
+		oldMap[ssl.ID] = ssl
+	}
+	for _, ssl := range news {
+		newMap[ssl.ID] = ssl
+	}
+
+	for _, ssl := range news {
+		if ossl, ok := oldMap[ssl.ID]; !ok {
+			added = append(added, ssl)
+		} else if !reflect.DeepEqual(ossl, ssl) {
+			updated = append(updated, ssl)
+		}
+	}
+	for _, ssl := range olds {
+		if _, ok := newMap[ssl.ID]; !ok {
+			deleted = append(deleted, ssl)
+		}
+	}
+	return
+}
+
+func diffConsumers(olds, news []*apisixv1.Consumer) (added, updated, deleted []*apisixv1.Consumer) {
+	oldMap := make(map[string]*apisixv1.Consumer, len(olds))
+	newMap := make(map[string]*apisixv1.Consumer, len(news))
+	for _, c := range olds {
+		oldMap[c.Username] = c
+	}
+	for _, c := range news {
+		newMap[c.Username] = c
+	}
+
+	for _, c := range news {
+		if oc, ok := oldMap[c.Username]; !ok {
+			added = append(added, c)
+		} else if !reflect.DeepEqual(oc, c) {
+			updated = append(updated, c)
+		}
+	}
+	for _, c := range olds {
+		if _, ok := newMap[c.Username]; !ok {
+			deleted = append(deleted, c)
+		}
+	}
+	return
+}
+
+func diffGlobalRules(olds, news []*apisixv1.GlobalRule) (added, updated, deleted []*apisixv1.GlobalRule) {
+	oldMap := make(map[string]*apisixv1.GlobalRule, len(olds))
+	newMap := make(map[string]*apisixv1.GlobalRule, len(news))
+	for _, gr := range olds {
+		oldMap[gr.ID] = gr
+	}
+	for _, gr := range news {
+		newMap[gr.ID] = gr
+	}
+
+	for _, gr := range news {
+		if ogr, ok := oldMap[gr.ID]; !ok {
+			added = append(added, gr)
+		} else if !reflect.DeepEqual(ogr, gr) {
+			updated = append(updated, gr)
+		}
+	}
+	for _, gr := range olds {
+		if _, ok := newMap[gr.ID]; !ok {
+			deleted = append(deleted, gr)
+		}
+	}
+	return
+}
+
 type manifest struct {
 	routes       []*apisixv1.Route
 	upstreams    []*apisixv1.Upstream
 	streamRoutes []*apisixv1.StreamRoute
+	ssls         []*apisixv1.Ssl
+	consumers    []*apisixv1.Consumer
+	globalRules  []*apisixv1.GlobalRule
 }
 
 func (m *manifest) diff(om *manifest) (added, updated, deleted *manifest) {
 	ar, ur, dr := diffRoutes(om.routes, m.routes)
 	au, uu, du := diffUpstreams(om.upstreams, m.upstreams)
 	asr, usr, dsr := diffStreamRoutes(om.streamRoutes, m.streamRoutes)
-	if ar != nil || au != nil || asr != nil {
+	as, us, ds := diffSSLs(om.ssls, m.ssls)
+	ac, uc, dc := diffConsumers(om.consumers, m.consumers)
+	ag, ug, dg := diffGlobalRules(om.globalRules, m.globalRules)
+	if ar != nil || au != nil || asr != nil || as != nil || ac != nil || ag != nil {
 		added = &manifest{
 			routes:       ar,
 			upstreams:    au,
 			streamRoutes: asr,
+			ssls:         as,
+			consumers:    ac,
+			globalRules:  ag,
 		}
 	}
-	if ur != nil || uu != nil || usr != nil {
+	if ur != nil || uu != nil || usr != nil || us != nil || uc != nil || ug != nil {
 		updated = &manifest{
 			routes:       ur,
 			upstreams:    uu,
 			streamRoutes: usr,
+			ssls:         us,
+			consumers:    uc,
+			globalRules:  ug,
 		}
 	}
-	if dr != nil || du != nil || dsr != nil {
+	if dr != nil || du != nil || dsr != nil || ds != nil || dc != nil || dg != nil {
 		deleted = &manifest{
 			routes:       dr,
 			upstreams:    du,
 			streamRoutes: dsr,
+			ssls:         ds,
+			consumers:    dc,
+			globalRules:  dg,
 		}
 	}
 	return
 }
 
-func (c *Controller) syncManifests(ctx context.Context, added, updated, deleted *manifest) error {
+// empty returns true if the manifest contains nothing.
+func (m *manifest) empty() bool {
+	return len(m.routes) == 0 && len(m.upstreams) == 0 && len(m.streamRoutes) == 0 &&
+		len(m.ssls) == 0 && len(m.consumers) == 0 && len(m.globalRules) == 0
+}
+
+// subtract returns resources in the manifest but not in the other one,
+// they are compared by IDs (usernames for consumers).
+func (m *manifest) subtract(o *manifest) *manifest {
+	if m == nil {
+		return nil
+	}
+	if o == nil {
+		o = &manifest{}
+	}
+	_, _, dr := diffRoutes(m.routes, o.routes)
+	_, _, du := diffUpstreams(m.upstreams, o.upstreams)
+	_, _, dsr := diffStreamRoutes(m.streamRoutes, o.streamRoutes)
+	_, _, ds := diffSSLs(m.ssls, o.ssls)
+	_, _, dc := diffConsumers(m.consumers, o.consumers)
+	_, _, dg := diffGlobalRules(m.globalRules, o.globalRules)
+	left := &manifest{
+		routes:       dr,
+		upstreams:    du,
+		streamRoutes: dsr,
+		ssls:         ds,
+		consumers:    dc,
+		globalRules:  dg,
+	}
+	if left.empty() {
+		return nil
+	}
+	return left
+}
+
+// merge returns the union of resources in the manifest and the other one,
+// resources in the manifest take precedence over the ones with the same
+// IDs in the other one.
+func (m *manifest) merge(o *manifest) *manifest {
+	if o == nil {
+		return m
+	}
+	if m == nil {
+		return o
+	}
+	extra := o.subtract(m)
+	if extra == nil {
+		return m
+	}
+	return &manifest{
+		routes:       append(append([]*apisixv1.Route(nil), m.routes...), extra.routes...),
+		upstreams:    append(append([]*apisixv1.Upstream(nil), m.upstreams...), extra.upstreams...),
+		streamRoutes: append(append([]*apisixv1.StreamRoute(nil), m.streamRoutes...), extra.streamRoutes...),
+		ssls:         append(append([]*apisixv1.Ssl(nil), m.ssls...), extra.ssls...),
+		consumers:    append(append([]*apisixv1.Consumer(nil), m.consumers...), extra.consumers...),
+		globalRules:  append(append([]*apisixv1.GlobalRule(nil), m.globalRules...), extra.globalRules...),
+	}
+}
+
+// syncManifests pushes the changes of resources to the APISIX cluster.
+func (c *Controller) syncManifests(ctx context.Context, clusterName string, added, updated, deleted *manifest) error {
 	var merr *multierror.Error
 
-	clusterName := c.cfg.APISIX.DefaultClusterName
 	if deleted != nil {
 		for _, r := range deleted.routes {
 			if err := c.apisix.Cluster(clusterName).Route().Delete(ctx, r); err != nil {
@@ -157,17 +306,19 @@ func (c *Controller) syncManifests(ctx context.Context, added, updated, deleted
 				merr = multierror.Append(merr, err)
 			}
 		}
-		for _, u := range deleted.upstreams {
-			if err := c.apisix.Cluster(clusterName).Upstream().Delete(ctx, u); err != nil {
-				// Upstream might be referenced by other routes.
-				if err != cache.ErrStillInUse {
-					merr = multierror.Append(merr, err)
-				} else {
-					log.Infow("upstream was referenced by other routes",
-						zap.String("upstream_id", u.ID),
-						zap.String("upstream_name", u.Name),
-					)
-				}
+		for _, ssl := range deleted.ssls {
+			if err := c.apisix.Cluster(clusterName).SSL().Delete(ctx, ssl); err != nil {
+				merr = multierror.Append(merr, err)
+			}
+		}
+		for _, consumer := range deleted.consumers {
+			if err := c.apisix.Cluster(clusterName).Consumer().Delete(ctx, consumer); err != nil {
+				merr = multierror.Append(merr, err)
+			}
+		}
+		for _, gr := range deleted.globalRules {
+			if err := c.apisix.Cluster(clusterName).GlobalRule().Delete(ctx, gr); err != nil {
+				merr = multierror.Append(merr, err)
 			}
 		}
 	}
@@ -188,6 +339,21 @@ func (c *Controller) syncManifests(ctx context.Context, added, updated, deleted
 				merr = multierror.Append(merr, err)
 			}
 		}
+		for _, ssl := range added.ssls {
+			if _, err := c.apisix.Cluster(clusterName).SSL().Create(ctx, ssl); err != nil {
+				merr = multierror.Append(merr, err)
+			}
+		}
+		for _, consumer := range added.consumers {
+			if _, err := c.apisix.Cluster(clusterName).Consumer().Create(ctx, consumer); err != nil {
+				merr = multierror.Append(merr, err)
+			}
+		}
+		for _, gr := range added.globalRules {
+			if _, err := c.apisix.Cluster(clusterName).GlobalRule().Create(ctx, gr); err != nil {
+				merr = multierror.Append(merr, err)
+			}
+		}
 	}
 	if updated != nil {
 		for _, r := range updated.upstreams {
@@ -205,6 +371,38 @@ func (c *Controller) syncManifests(ctx context.Context, added, updated, deleted
 				merr = multierror.Append(merr, err)
 			}
 		}
+		for _, ssl := range updated.ssls {
+			if _, err := c.apisix.Cluster(clusterName).SSL().Update(ctx, ssl); err != nil {
+				merr = multierror.Append(merr, err)
+			}
+		}
+		for _, consumer := range updated.consumers {
+			if _, err := c.apisix.Cluster(clusterName).Consumer().Update(ctx, consumer); err != nil {
+				merr = multierror.Append(merr, err)
+			}
+		}
+		for _, gr := range updated.globalRules {
+			if _, err := c.apisix.Cluster(clusterName).GlobalRule().Update(ctx, gr); err != nil {
+				merr = multierror.Append(merr, err)
+			}
+		}
+	}
+	if deleted != nil {
+		// Delete upstreams lastly, as they might be referenced by the
+		// updated routes (e.g. in the traffic-split plugin) before.
+		for _, u := range deleted.upstreams {
+			if err := c.apisix.Cluster(clusterName).Upstream().Delete(ctx, u); err != nil {
+				// Upstream might be referenced by other routes.
+				if err != cache.ErrStillInUse {
+					merr = multierror.Append(merr, err)
+				} else {
+					log.Infow("upstream was referenced by other routes",
+						zap.String("upstream_id", u.ID),
+						zap.String("upstream_name", u.Name),
+					)
+				}
+			}
+		}
 	}
 	if merr != nil {
 		return merr
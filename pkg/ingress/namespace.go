@@ -0,0 +1,214 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package ingress
+
+import (
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+
+	"github.com/apache/apisix-ingress-controller/pkg/kube"
+	"github.com/apache/apisix-ingress-controller/pkg/log"
+	"github.com/apache/apisix-ingress-controller/pkg/types"
+)
+
+// isNamespaceWatched reports whether the namespace is one of the app
+// namespaces and its labels match the namespace selector.
+func (c *Controller) isNamespaceWatched(ns string) bool {
+	c.cfgLock.RLock()
+	watching := c.watchingNamespace
+	c.cfgLock.RUnlock()
+	if watching != nil {
+		if _, ok := watching[ns]; !ok {
+			return false
+		}
+	}
+	if c.namespaceSelector == nil {
+		return true
+	}
+	if c.namespaceInformer == nil {
+		return false
+	}
+	obj, exists, err := c.namespaceInformer.GetStore().GetByKey(ns)
+	if err != nil || !exists {
+		return false
+	}
+	return c.namespaceSelector.Matches(labels.Set(obj.(*corev1.Namespace).Labels))
+}
+
+// watchNamespaces syncs resources in namespaces once they are selected, and
+// deletes their resources from APISIX once they are not selected anymore.
+func (c *Controller) watchNamespaces() {
+	if c.namespaceInformer == nil {
+		return
+	}
+	c.namespaceInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			ns := obj.(*corev1.Namespace)
+			if c.isNamespaceWatched(ns.Name) {
+				c.includeNamespace(ns.Name)
+			}
+		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			prev := oldObj.(*corev1.Namespace)
+			curr := newObj.(*corev1.Namespace)
+			if c.namespaceSelector.Matches(labels.Set(prev.Labels)) == c.namespaceSelector.Matches(labels.Set(curr.Labels)) {
+				return
+			}
+			if c.isNamespaceWatched(curr.Name) {
+				c.includeNamespace(curr.Name)
+			} else {
+				c.excludeNamespace(curr.Name)
+			}
+		},
+		DeleteFunc: func(obj interface{}) {
+			name, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
+			if err != nil {
+				log.Errorf("found namespace with bad meta key: %s", err)
+				return
+			}
+			c.excludeNamespace(name)
+		},
+	})
+}
+
+// includeNamespace enqueues objects in the namespace, so their resources
+// will be created in APISIX.
+func (c *Controller) includeNamespace(ns string) {
+	log.Infow("namespace is included, syncing its objects",
+		zap.String("namespace", ns),
+	)
+	for _, obj := range namespacedObjects(c.podInformer, ns) {
+		c.podController.onAdd(obj)
+	}
+	// Upstream nodes are translated from Endpoints (or EndpointSlices), so
+	// they are synced again as well.
+	for _, obj := range namespacedObjects(c.epInformer, ns) {
+		if c.endpointSliceController != nil {
+			c.endpointSliceController.onAdd(obj)
+		} else {
+			c.endpointsController.onAdd(obj)
+		}
+	}
+	for _, obj := range namespacedObjects(c.ingressInformer, ns) {
+		if key, err := cache.MetaNamespaceKeyFunc(obj); err == nil {
+			c.ingressController.resync(key, types.EventAdd)
+			if c.ingressStatusController != nil {
+				c.ingressStatusController.workqueue.Add(key)
+			}
+		}
+	}
+	for _, obj := range namespacedObjects(c.apisixRouteInformer, ns) {
+		if key, err := cache.MetaNamespaceKeyFunc(obj); err == nil {
+			c.apisixRouteController.resync(key, types.EventAdd)
+		}
+	}
+	c.enqueueNamespacedObjects(c.apisixUpstreamInformer, c.apisixUpstreamController.workqueue, ns, types.EventAdd)
+	c.enqueueNamespacedObjects(c.apisixTlsInformer, c.apisixTlsController.workqueue, ns, types.EventAdd)
+	c.enqueueNamespacedObjects(c.apisixConsumerInformer, c.apisixConsumerController.workqueue, ns, types.EventAdd)
+}
+
+// excludeNamespace enqueues delete events for objects in the namespace, so
+// their resources will be deleted from APISIX.
+func (c *Controller) excludeNamespace(ns string) {
+	log.Infow("namespace is excluded, deleting resources of its objects",
+		zap.String("namespace", ns),
+	)
+	for _, obj := range namespacedObjects(c.podInformer, ns) {
+		if err := c.podCache.Delete(obj.(*corev1.Pod)); err != nil {
+			log.Debugw("failed to delete pod from cache",
+				zap.Error(err),
+				zap.Any("pod", obj),
+			)
+		}
+	}
+	for _, obj := range namespacedObjects(c.ingressInformer, ns) {
+		key, err := cache.MetaNamespaceKeyFunc(obj)
+		if err != nil {
+			continue
+		}
+		if c.ingressStatusController != nil {
+			c.ingressStatusController.workqueue.Add(key)
+		}
+		ing, err := kube.NewIngress(obj)
+		if err != nil || !c.ingressController.isIngressEffective(ing) {
+			continue
+		}
+		c.ingressController.workqueue.Add(&types.Event{
+			Type: types.EventDelete,
+			Object: kube.IngressEvent{
+				Key:          key,
+				GroupVersion: ing.GroupVersion(),
+			},
+			Tombstone: ing,
+		})
+	}
+	for _, obj := range namespacedObjects(c.apisixRouteInformer, ns) {
+		key, err := cache.MetaNamespaceKeyFunc(obj)
+		if err != nil {
+			continue
+		}
+		ar, err := kube.NewApisixRoute(obj)
+		if err != nil || !c.isApisixObjectEffective(apisixRouteObject(ar)) {
+			continue
+		}
+		c.apisixRouteController.workqueue.Add(&types.Event{
+			Type: types.EventDelete,
+			Object: kube.ApisixRouteEvent{
+				Key:          key,
+				GroupVersion: ar.GroupVersion(),
+			},
+			Tombstone: ar,
+		})
+	}
+	c.enqueueNamespacedObjects(c.apisixUpstreamInformer, c.apisixUpstreamController.workqueue, ns, types.EventDelete)
+	c.enqueueNamespacedObjects(c.apisixTlsInformer, c.apisixTlsController.workqueue, ns, types.EventDelete)
+	c.enqueueNamespacedObjects(c.apisixConsumerInformer, c.apisixConsumerController.workqueue, ns, types.EventDelete)
+}
+
+// enqueueNamespacedObjects enqueues the effective Apisix* objects in the
+// namespace by their keys, objects themselves are used as tombstones of
+// delete events.
+func (c *Controller) enqueueNamespacedObjects(informer cache.SharedIndexInformer, queue workqueue.RateLimitingInterface, ns string, typ types.EventType) {
+	for _, obj := range namespacedObjects(informer, ns) {
+		key, err := cache.MetaNamespaceKeyFunc(obj)
+		if err != nil || !c.isApisixObjectEffective(obj.(metav1.Object)) {
+			continue
+		}
+		ev := &types.Event{
+			Type:   typ,
+			Object: key,
+		}
+		if typ == types.EventDelete {
+			ev.Tombstone = obj
+		}
+		queue.Add(ev)
+	}
+}
+
+func namespacedObjects(informer cache.SharedIndexInformer, ns string) []interface{} {
+	objs, err := informer.GetIndexer().ByIndex(cache.NamespaceIndex, ns)
+	if err != nil {
+		log.Errorw("failed to list objects by namespace",
+			zap.String("namespace", ns),
+			zap.Error(err),
+		)
+		return nil
+	}
+	return objs
+}
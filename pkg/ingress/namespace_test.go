@@ -0,0 +1,165 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package ingress
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+
+	"github.com/apache/apisix-ingress-controller/pkg/config"
+	"github.com/apache/apisix-ingress-controller/pkg/kube"
+	configv1 "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/apis/config/v1"
+	configv2alpha1 "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/apis/config/v2alpha1"
+	"github.com/apache/apisix-ingress-controller/pkg/types"
+)
+
+func newNamespacedInformer(obj runtime.Object) cache.SharedIndexInformer {
+	return cache.NewSharedIndexInformer(&cache.ListWatch{}, obj, 0, cache.Indexers{
+		cache.NamespaceIndex: cache.MetaNamespaceIndexFunc,
+	})
+}
+
+func newNamespaceController(t *testing.T) *Controller {
+	selector, err := labels.Parse("tenant")
+	assert.Nil(t, err)
+	c := &Controller{
+		cfg:                    config.NewDefaultConfig(),
+		namespaceSelector:      selector,
+		namespaceInformer:      cache.NewSharedIndexInformer(&cache.ListWatch{}, &corev1.Namespace{}, 0, cache.Indexers{}),
+		podCache:               types.NewPodCache(),
+		podInformer:            newNamespacedInformer(&corev1.Pod{}),
+		epInformer:             newNamespacedInformer(&corev1.Endpoints{}),
+		ingressInformer:        newNamespacedInformer(&networkingv1.Ingress{}),
+		apisixRouteInformer:    newNamespacedInformer(&configv2alpha1.ApisixRoute{}),
+		apisixUpstreamInformer: newNamespacedInformer(&configv1.ApisixUpstream{}),
+		apisixTlsInformer:      newNamespacedInformer(&configv1.ApisixTls{}),
+		apisixConsumerInformer: newNamespacedInformer(&configv2alpha1.ApisixConsumer{}),
+	}
+	newQueue := func() workqueue.RateLimitingInterface {
+		return workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
+	}
+	c.podController = &podController{controller: c}
+	c.endpointsController = &endpointsController{
+		controller: c,
+		// Events are enqueued without delay.
+		workqueue: workqueue.NewRateLimitingQueue(workqueue.NewItemFastSlowRateLimiter(0, 0, 0)),
+	}
+	c.ingressController = &ingressController{controller: c, workqueue: newQueue()}
+	c.apisixRouteController = &apisixRouteController{controller: c, workqueue: newQueue()}
+	c.apisixUpstreamController = &apisixUpstreamController{controller: c, workqueue: newQueue()}
+	c.apisixTlsController = &apisixTlsController{controller: c, workqueue: newQueue()}
+	c.apisixConsumerController = &apisixConsumerController{controller: c, workqueue: newQueue()}
+	return c
+}
+
+func TestNamespaceWatching(t *testing.T) {
+	c := newNamespaceController(t)
+	assert.Nil(t, c.namespaceInformer.GetStore().Add(&corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "foo",
+			Labels: map[string]string{"tenant": "foo"},
+		},
+	}))
+	assert.Nil(t, c.namespaceInformer.GetStore().Add(&corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{Name: "bar"},
+	}))
+
+	assert.True(t, c.namespaceWatching("foo/httpbin"))
+	assert.False(t, c.namespaceWatching("bar/httpbin"))
+	assert.False(t, c.namespaceWatching("baz/httpbin"))
+
+	// The selector filters app namespaces further.
+	c.watchingNamespace = map[string]struct{}{"bar": {}}
+	assert.False(t, c.namespaceWatching("foo/httpbin"))
+}
+
+func TestIncludeAndExcludeNamespace(t *testing.T) {
+	c := newNamespaceController(t)
+	ns := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "foo",
+			Labels: map[string]string{"tenant": "foo"},
+		},
+	}
+	assert.Nil(t, c.namespaceInformer.GetStore().Add(ns))
+
+	meta := func(name, namespace string) metav1.ObjectMeta {
+		return metav1.ObjectMeta{
+			Name:        name,
+			Namespace:   namespace,
+			Annotations: map[string]string{_ingressKey: "apisix"},
+		}
+	}
+	assert.Nil(t, c.ingressInformer.GetStore().Add(&networkingv1.Ingress{ObjectMeta: meta("ing", "foo")}))
+	assert.Nil(t, c.ingressInformer.GetStore().Add(&networkingv1.Ingress{ObjectMeta: meta("ing", "bar")}))
+	assert.Nil(t, c.apisixRouteInformer.GetStore().Add(&configv2alpha1.ApisixRoute{ObjectMeta: meta("ar", "foo")}))
+	assert.Nil(t, c.apisixTlsInformer.GetStore().Add(&configv1.ApisixTls{ObjectMeta: meta("tls", "foo")}))
+	other := &configv1.ApisixTls{ObjectMeta: meta("other", "foo")}
+	other.Annotations[_ingressKey] = "nginx"
+	assert.Nil(t, c.apisixTlsInformer.GetStore().Add(other))
+	assert.Nil(t, c.podInformer.GetStore().Add(&corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "pod", Namespace: "foo"},
+		Status:     corev1.PodStatus{PodIP: "10.0.0.1"},
+	}))
+	assert.Nil(t, c.epInformer.GetStore().Add(&corev1.Endpoints{ObjectMeta: metav1.ObjectMeta{Name: "svc", Namespace: "foo"}}))
+	assert.Nil(t, c.epInformer.GetStore().Add(&corev1.Endpoints{ObjectMeta: metav1.ObjectMeta{Name: "svc", Namespace: "bar"}}))
+
+	c.includeNamespace("foo")
+	name, err := c.podCache.GetNameByIP("10.0.0.1")
+	assert.Nil(t, err)
+	assert.Equal(t, "pod", name)
+
+	pop := func(queue workqueue.RateLimitingInterface) *types.Event {
+		assert.Equal(t, 1, queue.Len())
+		obj, _ := queue.Get()
+		queue.Done(obj)
+		return obj.(*types.Event)
+	}
+	ev := pop(c.endpointsController.workqueue)
+	assert.True(t, ev.Type == types.EventAdd)
+	assert.Equal(t, "foo", ev.Object.(kube.Endpoint).Namespace())
+	ev = pop(c.ingressController.workqueue)
+	assert.True(t, ev.Type == types.EventAdd)
+	ev = pop(c.apisixRouteController.workqueue)
+	assert.True(t, ev.Type == types.EventAdd)
+	ev = pop(c.apisixTlsController.workqueue)
+	assert.True(t, ev.Type == types.EventAdd)
+	assert.Equal(t, "foo/tls", ev.Object)
+	assert.Equal(t, 0, c.apisixConsumerController.workqueue.Len())
+
+	ns = ns.DeepCopy()
+	ns.Labels = nil
+	assert.Nil(t, c.namespaceInformer.GetStore().Update(ns))
+	c.excludeNamespace("foo")
+	_, err = c.podCache.GetNameByIP("10.0.0.1")
+	assert.NotNil(t, err)
+
+	ev = pop(c.ingressController.workqueue)
+	assert.True(t, ev.Type == types.EventDelete)
+	assert.NotNil(t, ev.Tombstone)
+	ev = pop(c.apisixRouteController.workqueue)
+	assert.True(t, ev.Type == types.EventDelete)
+	ev = pop(c.apisixTlsController.workqueue)
+	assert.True(t, ev.Type == types.EventDelete)
+	assert.Equal(t, "foo/tls", ev.Tombstone.(*configv1.ApisixTls).Namespace+"/"+ev.Tombstone.(*configv1.ApisixTls).Name)
+}
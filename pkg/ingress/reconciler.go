@@ -0,0 +1,445 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package ingress
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/apache/apisix-ingress-controller/pkg/apisix"
+	"github.com/apache/apisix-ingress-controller/pkg/kube"
+	configv1 "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/apis/config/v1"
+	configv2alpha1 "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/apis/config/v2alpha1"
+	"github.com/apache/apisix-ingress-controller/pkg/kube/translation"
+	"github.com/apache/apisix-ingress-controller/pkg/log"
+	apisixv1 "github.com/apache/apisix-ingress-controller/pkg/types/apisix/v1"
+)
+
+const (
+	// _driftRepaired is used when drifted resources in APISIX are repaired
+	_driftRepaired = "DriftRepaired"
+	// _messageDriftRepaired is used to report the repaired resources
+	_messageDriftRepaired = "%s repaired drifted resources in APISIX: %s"
+	// _driftRepairFailed is used when drifted resources in APISIX cannot be repaired
+	_driftRepairFailed = "DriftRepairFailed"
+	// _messageDriftRepairFailed is used to report error
+	_messageDriftRepairFailed = "%s failed to repair drifted resources in APISIX: %s, with error: %s"
+)
+
+// reconcileItem is the desired APISIX resources of a Kubernetes object.
+type reconcileItem struct {
+	kind     string
+	key      string
+	object   runtime.Object
+	manifest *manifest
+	// clusters are the APISIX clusters selected by the object.
+	clusters []string
+}
+
+// apisixState indexes the resources in APISIX by their IDs (usernames
+// for consumers).
+type apisixState struct {
+	routes       map[string]*apisixv1.Route
+	upstreams    map[string]*apisixv1.Upstream
+	streamRoutes map[string]*apisixv1.StreamRoute
+	ssls         map[string]*apisixv1.Ssl
+	consumers    map[string]*apisixv1.Consumer
+	globalRules  map[string]*apisixv1.GlobalRule
+}
+
+func newApisixState(snap *apisix.Snapshot) *apisixState {
+	s := &apisixState{
+		routes:       make(map[string]*apisixv1.Route, len(snap.Routes)),
+		upstreams:    make(map[string]*apisixv1.Upstream, len(snap.Upstreams)),
+		streamRoutes: make(map[string]*apisixv1.StreamRoute, len(snap.StreamRoutes)),
+		ssls:         make(map[string]*apisixv1.Ssl, len(snap.SSLs)),
+		consumers:    make(map[string]*apisixv1.Consumer, len(snap.Consumers)),
+		globalRules:  make(map[string]*apisixv1.GlobalRule, len(snap.GlobalRules)),
+	}
+	s.apply(&manifest{
+		routes:       snap.Routes,
+		upstreams:    snap.Upstreams,
+		streamRoutes: snap.StreamRoutes,
+		ssls:         snap.SSLs,
+		consumers:    snap.Consumers,
+		globalRules:  snap.GlobalRules,
+	})
+	return s
+}
+
+// apply records resources in the manifest to the state.
+func (s *apisixState) apply(m *manifest) {
+	if m == nil {
+		return
+	}
+	for _, r := range m.routes {
+		s.routes[r.ID] = r
+	}
+	for _, u := range m.upstreams {
+		s.upstreams[u.ID] = u
+	}
+	for _, sr := range m.streamRoutes {
+		s.streamRoutes[sr.ID] = sr
+	}
+	for _, ssl := range m.ssls {
+		s.ssls[ssl.ID] = ssl
+	}
+	for _, c := range m.consumers {
+		s.consumers[c.Username] = c
+	}
+	for _, gr := range m.globalRules {
+		s.globalRules[gr.ID] = gr
+	}
+}
+
+// drift compares the desired manifest with the state, resources which
+// are missing are put into added, and those which don't cover the desired
+// ones (see apisixv1.Covers) are put into updated.
+func (s *apisixState) drift(m *manifest) (added, updated *manifest) {
+	added = &manifest{}
+	updated = &manifest{}
+	for _, r := range m.routes {
+		if actual, ok := s.routes[r.ID]; !ok {
+			added.routes = append(added.routes, r)
+		} else if !apisixv1.Covers(actual, r) {
+			updated.routes = append(updated.routes, r)
+		}
+	}
+	for _, u := range m.upstreams {
+		actual, ok := s.upstreams[u.ID]
+		if !ok {
+			added.upstreams = append(added.upstreams, u)
+			continue
+		}
+		// Upstreams might be shared by multiple objects, so the
+		// source labels are not compared.
+		desired := *u
+		desired.Labels = apisixv1.TrimSourceLabels(u.Labels)
+		pushed := *actual
+		pushed.Labels = apisixv1.TrimSourceLabels(actual.Labels)
+		if !apisixv1.Covers(&pushed, &desired) {
+			updated.upstreams = append(updated.upstreams, u)
+		}
+	}
+	for _, sr := range m.streamRoutes {
+		if actual, ok := s.streamRoutes[sr.ID]; !ok {
+			added.streamRoutes = append(added.streamRoutes, sr)
+		} else if !apisixv1.Covers(actual, sr) {
+			updated.streamRoutes = append(updated.streamRoutes, sr)
+		}
+	}
+	for _, ssl := range m.ssls {
+		if actual, ok := s.ssls[ssl.ID]; !ok {
+			added.ssls = append(added.ssls, ssl)
+		} else if !apisixv1.Covers(actual, ssl) {
+			updated.ssls = append(updated.ssls, ssl)
+		}
+	}
+	for _, c := range m.consumers {
+		if actual, ok := s.consumers[c.Username]; !ok {
+			added.consumers = append(added.consumers, c)
+		} else if !apisixv1.Covers(actual, c) {
+			updated.consumers = append(updated.consumers, c)
+		}
+	}
+	for _, gr := range m.globalRules {
+		if actual, ok := s.globalRules[gr.ID]; !ok {
+			added.globalRules = append(added.globalRules, gr)
+		} else if !apisixv1.Covers(actual, gr) {
+			updated.globalRules = append(updated.globalRules, gr)
+		}
+	}
+	if added.empty() {
+		added = nil
+	}
+	if updated.empty() {
+		updated = nil
+	}
+	return
+}
+
+// resources returns the resource type and ID (username for consumers) of
+// each resource in the manifest.
+func (m *manifest) resources() [][2]string {
+	if m == nil {
+		return nil
+	}
+	var res [][2]string
+	for _, r := range m.routes {
+		res = append(res, [2]string{"route", r.ID})
+	}
+	for _, u := range m.upstreams {
+		res = append(res, [2]string{"upstream", u.ID})
+	}
+	for _, sr := range m.streamRoutes {
+		res = append(res, [2]string{"stream_route", sr.ID})
+	}
+	for _, ssl := range m.ssls {
+		res = append(res, [2]string{"ssl", ssl.ID})
+	}
+	for _, c := range m.consumers {
+		res = append(res, [2]string{"consumer", c.Username})
+	}
+	for _, gr := range m.globalRules {
+		res = append(res, [2]string{"global_rule", gr.ID})
+	}
+	return res
+}
+
+// runReconciler reconciles the APISIX clusters periodically, it does
+// nothing if the reconcile interval is not configured.
+func (c *Controller) runReconciler(ctx context.Context) {
+	interval := c.cfg.APISIX.ReconcileInterval.Duration
+	if interval <= 0 {
+		return
+	}
+	if ok := cache.WaitForCacheSync(ctx.Done(),
+		c.ingressInformer.HasSynced,
+		c.apisixRouteInformer.HasSynced,
+		c.apisixTlsInformer.HasSynced,
+		c.apisixConsumerInformer.HasSynced,
+		c.apisixClusterConfigInformer.HasSynced,
+	); !ok {
+		log.Error("cache sync failed")
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			items := c.translateAll()
+			for _, name := range c.clusterNames() {
+				c.reconcile(ctx, name, items)
+			}
+		}
+	}
+}
+
+// reconcile lists all resources from the APISIX cluster (the cache is also
+// refreshed), then compares them with the translated Kubernetes objects
+// which select the cluster, drifted resources will be pushed to APISIX
+// again, and orphaned resources will be collected.
+func (c *Controller) reconcile(ctx context.Context, clusterName string, all []*reconcileItem) {
+	snap, err := c.apisix.Cluster(clusterName).Resync(ctx)
+	if err != nil {
+		log.Errorw("failed to list resources from APISIX, reconciliation aborted",
+			zap.String("cluster", clusterName),
+			zap.Error(err),
+		)
+		return
+	}
+
+	var items []*reconcileItem
+	for _, item := range all {
+		if containsCluster(item.clusters, clusterName) {
+			items = append(items, item)
+		}
+	}
+	state := newApisixState(snap)
+	for _, item := range items {
+		added, updated := state.drift(item.manifest)
+		if added == nil && updated == nil {
+			continue
+		}
+		res := append(added.resources(), updated.resources()...)
+		names := make([]string, 0, len(res))
+		for _, r := range res {
+			names = append(names, r[0]+" "+r[1])
+		}
+		log.Warnw("found drifted resources in APISIX",
+			zap.String("cluster", clusterName),
+			zap.String("kind", item.kind),
+			zap.String("key", item.key),
+			zap.Strings("resources", names),
+		)
+
+		err := c.syncManifests(ctx, clusterName, added, updated, nil)
+		for _, r := range res {
+			c.metricsCollector.IncrDriftRepair(r[0], err == nil)
+		}
+		if err != nil {
+			log.Errorw("failed to repair drifted resources in APISIX",
+				zap.String("cluster", clusterName),
+				zap.String("kind", item.kind),
+				zap.String("key", item.key),
+				zap.Error(err),
+			)
+			c.recorderEventS(item.object, corev1.EventTypeWarning, _driftRepairFailed,
+				fmt.Sprintf(_messageDriftRepairFailed, _component, strings.Join(names, ", "), err))
+			continue
+		}
+		// Resources like upstreams might be shared by multiple objects,
+		// record them to avoid repairing again.
+		state.apply(added)
+		state.apply(updated)
+		c.recorderEventS(item.object, corev1.EventTypeNormal, _driftRepaired,
+			fmt.Sprintf(_messageDriftRepaired, _component, strings.Join(names, ", ")))
+	}
+
+	c.collectGarbage(ctx, clusterName, snap, items)
+}
+
+// translateAll translates all the watched Ingresses, ApisixRoutes, ApisixTlses,
+// ApisixConsumers and the ApisixClusterConfigs of APISIX clusters. Objects
+// which fail to be translated are skipped, as they are handled by their own
+// controllers.
+func (c *Controller) translateAll() []*reconcileItem {
+	var items []*reconcileItem
+	add := func(kind string, obj interface{}, translate func() (*manifest, error)) {
+		key, err := cache.MetaNamespaceKeyFunc(obj)
+		if err != nil {
+			return
+		}
+		var clusters []string
+		if acc, ok := obj.(*configv2alpha1.ApisixClusterConfig); ok {
+			clusters = []string{acc.Name}
+		} else {
+			clusters, err = c.selectClusters(obj.(metav1.Object))
+			if err != nil {
+				log.Warnw("failed to select apisix clusters, skip reconciling it",
+					zap.String("kind", kind),
+					zap.String("key", key),
+					zap.Error(err),
+				)
+				return
+			}
+		}
+		m, err := translate()
+		if err != nil {
+			log.Warnw("failed to translate object, skip reconciling it",
+				zap.String("kind", kind),
+				zap.String("key", key),
+				zap.Error(err),
+			)
+			return
+		}
+		items = append(items, &reconcileItem{
+			kind:     kind,
+			key:      key,
+			object:   obj.(runtime.Object),
+			manifest: m,
+			clusters: clusters,
+		})
+	}
+
+	for _, obj := range c.ingressInformer.GetStore().List() {
+		key, err := cache.MetaNamespaceKeyFunc(obj)
+		if err != nil || !c.namespaceWatching(key) {
+			continue
+		}
+		ing := kube.MustNewIngress(obj)
+		if !c.ingressController.isIngressEffective(ing) {
+			continue
+		}
+		add(translation.SourceKindIngress, obj, func() (*manifest, error) {
+			tctx, err := c.translator.TranslateIngress(ing)
+			if err != nil {
+				return nil, err
+			}
+			c.ingressController.resolveDefaultBackend(ing, key, tctx)
+			c.ingressController.resolveTLSHosts(ing, key, tctx)
+			return &manifest{
+				routes:    tctx.Routes,
+				upstreams: tctx.Upstreams,
+				ssls:      tctx.SSLs,
+			}, nil
+		})
+	}
+	for _, obj := range c.apisixRouteInformer.GetStore().List() {
+		key, err := cache.MetaNamespaceKeyFunc(obj)
+		if err != nil || !c.namespaceWatching(key) {
+			continue
+		}
+		ar := kube.MustNewApisixRoute(obj)
+		if !c.isApisixObjectEffective(apisixRouteObject(ar)) {
+			continue
+		}
+		add(translation.SourceKindApisixRoute, obj, func() (*manifest, error) {
+			var (
+				tctx *translation.TranslateContext
+				err  error
+			)
+			switch ar.GroupVersion() {
+			case kube.ApisixRouteV1:
+				tctx, err = c.translator.TranslateRouteV1(ar.V1())
+			case kube.ApisixRouteV2alpha1:
+				tctx, err = c.translator.TranslateRouteV2alpha1(ar.V2alpha1())
+			case kube.ApisixRouteV2beta1:
+				tctx, err = c.translator.TranslateRouteV2beta1(ar.V2beta1())
+			}
+			if err != nil {
+				return nil, err
+			}
+			return &manifest{
+				routes:       tctx.Routes,
+				upstreams:    tctx.Upstreams,
+				streamRoutes: tctx.StreamRoutes,
+			}, nil
+		})
+	}
+	for _, obj := range c.apisixTlsInformer.GetStore().List() {
+		key, err := cache.MetaNamespaceKeyFunc(obj)
+		if err != nil || !c.namespaceWatching(key) || !c.isApisixObjectEffective(obj.(*configv1.ApisixTls)) {
+			continue
+		}
+		add(translation.SourceKindApisixTls, obj, func() (*manifest, error) {
+			ssl, err := c.translator.TranslateSSL(obj.(*configv1.ApisixTls))
+			if err != nil {
+				return nil, err
+			}
+			return &manifest{ssls: []*apisixv1.Ssl{ssl}}, nil
+		})
+	}
+	for _, obj := range c.apisixConsumerInformer.GetStore().List() {
+		key, err := cache.MetaNamespaceKeyFunc(obj)
+		if err != nil || !c.namespaceWatching(key) || !c.isApisixObjectEffective(obj.(*configv2alpha1.ApisixConsumer)) {
+			continue
+		}
+		add(translation.SourceKindApisixConsumer, obj, func() (*manifest, error) {
+			consumer, err := c.translator.TranslateApisixConsumer(obj.(*configv2alpha1.ApisixConsumer))
+			if err != nil {
+				return nil, err
+			}
+			return &manifest{consumers: []*apisixv1.Consumer{consumer}}, nil
+		})
+	}
+	clusters := c.clusterNames()
+	for _, obj := range c.apisixClusterConfigInformer.GetStore().List() {
+		acc := obj.(*configv2alpha1.ApisixClusterConfig)
+		if !containsCluster(clusters, acc.Name) {
+			continue
+		}
+		add("ApisixClusterConfig", obj, func() (*manifest, error) {
+			gr, err := c.translator.TranslateClusterConfig(acc)
+			if err != nil {
+				return nil, err
+			}
+			return &manifest{globalRules: []*apisixv1.GlobalRule{gr}}, nil
+		})
+	}
+	return items
+}
@@ -0,0 +1,85 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package ingress
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/apache/apisix-ingress-controller/pkg/apisix"
+	apisixv1 "github.com/apache/apisix-ingress-controller/pkg/types/apisix/v1"
+)
+
+func TestApisixStateDrift(t *testing.T) {
+	actualRoute := &apisixv1.Route{
+		Metadata: apisixv1.Metadata{
+			ID: "1",
+		},
+		Uris:       []string{"/ip"},
+		UpstreamId: "u1",
+	}
+	actualUpstream := &apisixv1.Upstream{
+		Metadata: apisixv1.Metadata{
+			ID: "u1",
+		},
+		Type: "roundrobin",
+		// Filled by APISIX.
+		Scheme: "http",
+	}
+	state := newApisixState(&apisix.Snapshot{
+		Routes:    []*apisixv1.Route{actualRoute},
+		Upstreams: []*apisixv1.Upstream{actualUpstream},
+		Consumers: []*apisixv1.Consumer{{Username: "jack"}},
+	})
+
+	m := &manifest{
+		routes: []*apisixv1.Route{
+			{
+				Metadata: apisixv1.Metadata{
+					ID: "1",
+				},
+				Uris:       []string{"/ip"},
+				UpstreamId: "u1",
+			},
+		},
+		upstreams: []*apisixv1.Upstream{
+			{
+				Metadata: apisixv1.Metadata{
+					ID: "u1",
+				},
+				Type: "roundrobin",
+			},
+		},
+		consumers: []*apisixv1.Consumer{{Username: "jack"}},
+	}
+	added, updated := state.drift(m)
+	assert.Nil(t, added)
+	assert.Nil(t, updated)
+
+	m.routes[0].Uris = []string{"/headers"}
+	m.ssls = []*apisixv1.Ssl{{ID: "s1"}}
+	added, updated = state.drift(m)
+	assert.NotNil(t, added)
+	assert.NotNil(t, updated)
+	assert.Equal(t, [][2]string{{"ssl", "s1"}}, added.resources())
+	assert.Equal(t, [][2]string{{"route", "1"}}, updated.resources())
+
+	state.apply(added)
+	state.apply(updated)
+	added, updated = state.drift(m)
+	assert.Nil(t, added)
+	assert.Nil(t, updated)
+}
@@ -0,0 +1,227 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package ingress
+
+import (
+	"context"
+	"reflect"
+	"sort"
+
+	"go.uber.org/zap"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/apache/apisix-ingress-controller/pkg/apisix"
+	"github.com/apache/apisix-ingress-controller/pkg/config"
+	"github.com/apache/apisix-ingress-controller/pkg/log"
+)
+
+// _reloadableConfigItems are the config items which can be changed at
+// runtime, they're named in the same way as config.Config.Diff.
+var _reloadableConfigItems = map[string]struct{}{
+	"log_level":                                   {},
+	"kubernetes.app_namespaces":                   {},
+	"apisix.default_cluster_base_url":             {},
+	"apisix.default_cluster_admin_key":            {},
+	"apisix.default_cluster_admin_key_secret_ref": {},
+	"apisix.default_cluster_ca_cert":              {},
+	"apisix.default_cluster_client_cert":          {},
+	"apisix.default_cluster_client_key":           {},
+	"apisix.default_cluster_server_name":          {},
+	"apisix.default_cluster_insecure_skip_verify": {},
+	"apisix.base_url":                             {},
+	"apisix.admin_key":                            {},
+}
+
+// Reload applies the reloaded configuration. The log level, the app
+// namespaces and the options of the default APISIX cluster are changed
+// at runtime, other config items require a restart, so their changes are
+// ignored with a warning. Reload should not be called concurrently.
+func (c *Controller) Reload(cfg *config.Config) {
+	var ignored []string
+	for _, item := range c.cfg.Diff(cfg) {
+		if _, ok := _reloadableConfigItems[item]; !ok {
+			ignored = append(ignored, item)
+		}
+	}
+	if len(ignored) > 0 {
+		log.Warnw("config items can't be changed at runtime, restart the controller to apply them",
+			zap.Strings("items", ignored),
+		)
+	}
+
+	if cfg.LogLevel != c.cfg.LogLevel {
+		if err := log.DefaultLogger.SetLevel(cfg.LogLevel); err != nil {
+			log.Errorw("failed to change log level",
+				zap.Error(err),
+			)
+		} else {
+			c.cfgLock.Lock()
+			c.cfg.LogLevel = cfg.LogLevel
+			c.cfgLock.Unlock()
+			log.Infow("log level changed",
+				zap.String("level", cfg.LogLevel),
+			)
+		}
+	}
+	c.reloadAppNamespaces(cfg.Kubernetes.AppNamespaces)
+	c.reloadDefaultCluster(&cfg.APISIX)
+
+	c.cfgLock.RLock()
+	hash := c.cfg.Hash()
+	c.cfgLock.RUnlock()
+	c.apiServer.SetConfigHash(hash)
+}
+
+// reloadAppNamespaces replaces the watched namespaces, objects in the newly
+// watched namespaces are synced, and the ones in namespaces which are not
+// watched anymore are deleted from APISIX.
+func (c *Controller) reloadAppNamespaces(namespaces []string) {
+	if reflect.DeepEqual(c.cfg.Kubernetes.AppNamespaces, namespaces) {
+		return
+	}
+	var known []string
+	watched := make(map[string]bool)
+	if c.isLeading() {
+		known = c.knownNamespaces()
+		for _, ns := range known {
+			watched[ns] = c.isNamespaceWatched(ns)
+		}
+	}
+
+	c.cfgLock.Lock()
+	c.cfg.Kubernetes.AppNamespaces = namespaces
+	c.watchingNamespace = newWatchingNamespace(namespaces)
+	c.cfgLock.Unlock()
+	log.Infow("app namespaces changed",
+		zap.Strings("namespaces", namespaces),
+	)
+
+	for _, ns := range known {
+		now := c.isNamespaceWatched(ns)
+		if now && !watched[ns] {
+			c.includeNamespace(ns)
+		} else if !now && watched[ns] {
+			c.excludeNamespace(ns)
+		}
+	}
+}
+
+// reloadDefaultCluster updates the default APISIX cluster if options to
+// connect its Admin API are changed. The cluster is updated in place if
+// only the admin key is changed, otherwise it's re-created.
+func (c *Controller) reloadDefaultCluster(cfg *config.APISIXConfig) {
+	c.cfgLock.Lock()
+	prev := c.cfg.APISIX
+	c.cfg.APISIX.DefaultClusterBaseURL = cfg.DefaultClusterBaseURL
+	c.cfg.APISIX.DefaultClusterAdminKey = cfg.DefaultClusterAdminKey
+	c.cfg.APISIX.DefaultClusterAdminKeySecretRef = cfg.DefaultClusterAdminKeySecretRef
+	c.cfg.APISIX.DefaultClusterCACert = cfg.DefaultClusterCACert
+	c.cfg.APISIX.DefaultClusterClientCert = cfg.DefaultClusterClientCert
+	c.cfg.APISIX.DefaultClusterClientKey = cfg.DefaultClusterClientKey
+	c.cfg.APISIX.DefaultClusterServerName = cfg.DefaultClusterServerName
+	c.cfg.APISIX.DefaultClusterInsecureSkipVerify = cfg.DefaultClusterInsecureSkipVerify
+	c.cfg.APISIX.BaseURL = cfg.BaseURL
+	c.cfg.APISIX.AdminKey = cfg.AdminKey
+	curr := c.cfg.APISIX
+	c.cfgLock.Unlock()
+	if reflect.DeepEqual(prev, curr) {
+		return
+	}
+
+	opts, err := c.defaultClusterOptions(context.Background())
+	if err != nil {
+		log.Errorw("failed to load options of default cluster",
+			zap.Error(err),
+		)
+		return
+	}
+	if err := c.apisix.UpdateCluster(opts); err != nil {
+		if err == apisix.ErrClusterNotExist {
+			// Not leading, options take effect once the cluster is added.
+			return
+		}
+		log.Errorw("failed to update default cluster",
+			zap.String("cluster", opts.Name),
+			zap.Error(err),
+		)
+		return
+	}
+	if ref := curr.DefaultClusterAdminKeySecretRef; !ref.IsEmpty() {
+		c.watchAdminKey(&ref, opts)
+	} else {
+		c.watchAdminKey(nil, opts)
+	}
+	log.Infow("default cluster updated",
+		zap.String("cluster", opts.Name),
+		zap.String("base_url", opts.BaseURL),
+	)
+}
+
+// knownNamespaces returns the sorted namespaces of the watched objects.
+func (c *Controller) knownNamespaces() []string {
+	set := make(map[string]struct{})
+	for _, informer := range []cache.SharedIndexInformer{
+		c.podInformer,
+		c.ingressInformer,
+		c.apisixRouteInformer,
+		c.apisixUpstreamInformer,
+		c.apisixTlsInformer,
+		c.apisixConsumerInformer,
+	} {
+		for _, ns := range informer.GetIndexer().ListIndexFuncValues(cache.NamespaceIndex) {
+			set[ns] = struct{}{}
+		}
+	}
+	namespaces := make([]string, 0, len(set))
+	for ns := range set {
+		namespaces = append(namespaces, ns)
+	}
+	sort.Strings(namespaces)
+	return namespaces
+}
+
+// apisixConfig returns a copy of the APISIX config items, as some of them
+// can be reloaded.
+func (c *Controller) apisixConfig() config.APISIXConfig {
+	c.cfgLock.RLock()
+	defer c.cfgLock.RUnlock()
+	return c.cfg.APISIX
+}
+
+func (c *Controller) setLeading(leading bool) {
+	c.cfgLock.Lock()
+	c.leading = leading
+	c.cfgLock.Unlock()
+}
+
+func (c *Controller) isLeading() bool {
+	c.cfgLock.RLock()
+	defer c.cfgLock.RUnlock()
+	return c.leading
+}
+
+// newWatchingNamespace returns the set of app namespaces, nil means all
+// namespaces are watched.
+func newWatchingNamespace(namespaces []string) map[string]struct{} {
+	if len(namespaces) == 0 || (len(namespaces) == 1 && namespaces[0] == v1.NamespaceAll) {
+		return nil
+	}
+	watching := make(map[string]struct{}, len(namespaces))
+	for _, ns := range namespaces {
+		watching[ns] = struct{}{}
+	}
+	return watching
+}
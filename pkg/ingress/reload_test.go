@@ -0,0 +1,87 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package ingress
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/apache/apisix-ingress-controller/pkg/api"
+	"github.com/apache/apisix-ingress-controller/pkg/apisix"
+	"github.com/apache/apisix-ingress-controller/pkg/config"
+	"github.com/apache/apisix-ingress-controller/pkg/log"
+	"github.com/apache/apisix-ingress-controller/pkg/types"
+)
+
+func TestReload(t *testing.T) {
+	c := newNamespaceController(t)
+	c.namespaceSelector = nil
+	c.namespaceInformer = nil
+	c.leading = true
+	c.cfg.Kubernetes.AppNamespaces = []string{"foo"}
+	c.cfg.APISIX.DefaultClusterBaseURL = "http://127.0.0.1:9080/apisix/admin"
+	c.watchingNamespace = newWatchingNamespace(c.cfg.Kubernetes.AppNamespaces)
+
+	srv, err := api.NewServer(&config.Config{HTTPListen: "127.0.0.1:0"})
+	assert.Nil(t, err)
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	go func() {
+		assert.Nil(t, srv.Run(stopCh))
+	}()
+	c.apiServer = srv
+	c.apisix, err = apisix.NewClient()
+	assert.Nil(t, err)
+
+	for _, ns := range []string{"foo", "bar"} {
+		assert.Nil(t, c.ingressInformer.GetStore().Add(&networkingv1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "ing",
+				Namespace:   ns,
+				Annotations: map[string]string{_ingressKey: "apisix"},
+			},
+		}))
+	}
+
+	cfg := *c.cfg
+	cfg.LogLevel = "debug"
+	cfg.Kubernetes.AppNamespaces = []string{"bar"}
+	cfg.Kubernetes.ElectionID = "another-election-id"
+	cfg.APISIX.DefaultClusterBaseURL = "http://127.0.0.2:9080/apisix/admin"
+	defer func() {
+		assert.Nil(t, log.DefaultLogger.SetLevel("warn"))
+	}()
+	c.Reload(&cfg)
+
+	assert.Equal(t, "debug", c.cfg.LogLevel)
+	assert.Equal(t, []string{"bar"}, c.cfg.Kubernetes.AppNamespaces)
+	assert.Equal(t, config.IngressAPISIXLeader, c.cfg.Kubernetes.ElectionID)
+	assert.Equal(t, "http://127.0.0.2:9080/apisix/admin", c.cfg.APISIX.DefaultClusterBaseURL)
+	assert.Equal(t, c.cfg.Hash(), srv.ConfigHash())
+	assert.True(t, c.namespaceWatching("bar/ing"))
+	assert.False(t, c.namespaceWatching("foo/ing"))
+
+	queue := c.ingressController.workqueue
+	assert.Equal(t, 2, queue.Len())
+	obj, _ := queue.Get()
+	queue.Done(obj)
+	assert.True(t, obj.(*types.Event).Type == types.EventAdd)
+	obj, _ = queue.Get()
+	queue.Done(obj)
+	assert.True(t, obj.(*types.Event).Type == types.EventDelete)
+}
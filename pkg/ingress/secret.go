@@ -17,21 +17,18 @@ package ingress
 
 import (
 	"context"
-	"fmt"
-	"sync"
+	"reflect"
 	"time"
 
 	"go.uber.org/zap"
 	corev1 "k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/util/workqueue"
 
-	"github.com/apache/apisix-ingress-controller/pkg/kube/translation"
 	"github.com/apache/apisix-ingress-controller/pkg/log"
+	"github.com/apache/apisix-ingress-controller/pkg/metrics"
 	"github.com/apache/apisix-ingress-controller/pkg/types"
-	apisixv1 "github.com/apache/apisix-ingress-controller/pkg/types/apisix/v1"
 )
 
 type secretController struct {
@@ -95,12 +92,10 @@ func (c *secretController) sync(ctx context.Context, ev *types.Event) error {
 		return err
 	}
 	sec, err := c.controller.secretLister.Secrets(namespace).Get(name)
-
-	secretMapkey := namespace + "_" + name
 	if err != nil {
 		if !k8serrors.IsNotFound(err) {
 			log.Errorw("failed to get Secret",
-				zap.String("key", secretMapkey),
+				zap.String("key", key),
 				zap.Error(err),
 			)
 			return err
@@ -108,7 +103,7 @@ func (c *secretController) sync(ctx context.Context, ev *types.Event) error {
 
 		if ev.Type != types.EventDelete {
 			log.Warnw("Secret was deleted before it can be delivered",
-				zap.String("key", secretMapkey),
+				zap.String("key", key),
 			)
 			return nil
 		}
@@ -119,118 +114,60 @@ func (c *secretController) sync(ctx context.Context, ev *types.Event) error {
 			// that means object with same namespace and name was created, discarding
 			// this stale DELETE event.
 			log.Warnw("discard the stale secret delete event since the resource still exists",
-				zap.String("key", secretMapkey),
+				zap.String("key", key),
 			)
 			return nil
 		}
 		sec = ev.Tombstone.(*corev1.Secret)
 	}
-	// sync SSL in APISIX which is store in secretSSLMap
-	// FixMe Need to update the status of CRD ApisixTls
-	ssls, ok := c.controller.secretSSLMap.Load(secretMapkey)
-	if !ok {
-		// This secret is not concerned.
-		return nil
-	}
-	sslMap := ssls.(*sync.Map)
-	sslMap.Range(func(k, v interface{}) bool {
-		ssl := v.(*apisixv1.Ssl)
-		tlsMetaKey := k.(string)
-		tlsNamespace, tlsName, err := cache.SplitMetaNamespaceKey(tlsMetaKey)
-		if err != nil {
-			log.Errorf("invalid cached ApisixTls key: %s", tlsMetaKey)
-			return true
-		}
-		tls, err := c.controller.apisixTlsLister.ApisixTlses(tlsNamespace).Get(tlsName)
-		if err != nil {
-			log.Warnw("secret related ApisixTls resource not found, skip",
-				zap.String("ApisixTls", tlsMetaKey),
-			)
-			return true
-		}
-		if tls.Spec.Secret.Namespace == sec.Namespace && tls.Spec.Secret.Name == sec.Name {
-			cert, ok := sec.Data["cert"]
-			if !ok {
-				log.Warnw("secret required by ApisixTls invalid",
-					zap.String("ApisixTls", tlsMetaKey),
-					zap.Error(translation.ErrEmptyCert),
-				)
-				return true
-			}
-			pkey, ok := sec.Data["key"]
-			if !ok {
-				log.Warnw("secret required by ApisixTls invalid",
-					zap.String("ApisixTls", tlsMetaKey),
-					zap.Error(translation.ErrEmptyPrivKey),
-				)
-				return true
-			}
-			// sync ssl
-			ssl.Cert = string(cert)
-			ssl.Key = string(pkey)
-		} else if tls.Spec.Client != nil &&
-			tls.Spec.Client.CASecret.Namespace == sec.Namespace && tls.Spec.Client.CASecret.Name == sec.Name {
-			ca, ok := sec.Data["cert"]
-			if !ok {
-				log.Warnw("secret required by ApisixTls invalid",
-					zap.String("resource", tlsMetaKey),
-					zap.Error(translation.ErrEmptyCert),
-				)
-				return true
-			}
-			ssl.Client = &apisixv1.MutualTLSClientConfig{
-				CA: string(ca),
-			}
-		} else {
-			log.Warnw("stale secret cache, ApisixTls doesn't requires target secret",
-				zap.String("ApisixTls", tlsMetaKey),
-				zap.String("secret", key),
-			)
-			return true
+	if ev.Type != types.EventDelete && c.controller.isAdminKeySecret(sec.Namespace, sec.Name) {
+		if err := c.controller.rotateAdminKey(sec); err != nil {
+			return err
 		}
-		// Use another goroutine to send requests, to avoid
-		// long time lock occupying.
-		go func(ssl *apisixv1.Ssl) {
-			err := c.controller.syncSSL(ctx, ssl, ev.Type)
-			if err != nil {
-				log.Errorw("failed to sync ssl to APISIX",
-					zap.Error(err),
-					zap.Any("ssl", ssl),
-					zap.Any("secret", sec),
-				)
-				c.controller.recorderEventS(tls, corev1.EventTypeWarning, _resourceSyncAborted,
-					fmt.Sprintf("sync from secret %s changes failed, error: %s", key, err.Error()))
-				c.controller.recordStatus(tls, _resourceSyncAborted, err, metav1.ConditionFalse)
-			} else {
-				c.controller.recorderEventS(tls, corev1.EventTypeNormal, _resourceSynced,
-					fmt.Sprintf("sync from secret %s changes", key))
-				c.controller.recordStatus(tls, _resourceSynced, nil, metav1.ConditionTrue)
-			}
-		}(ssl)
-		return true
-	})
-	return err
+	}
+	// Objects which reference the Secret are re-synced by the event
+	// handlers through the dependency index.
+	return nil
 }
 
 func (c *secretController) handleSyncErr(obj interface{}, err error) {
 	if err == nil {
 		c.workqueue.Forget(obj)
+		c.controller.metricsCollector.IncrSyncOperation("Secret", metrics.SyncResultSuccess)
 		return
 	}
-	log.Warnw("sync ApisixTls failed, will retry",
-		zap.Any("object", obj),
+	// Secrets (as tombstones) are not logged, their data are sensitive.
+	log.Warnw("sync Secret failed, will retry",
+		zap.Any("object-key", obj.(*types.Event).Object),
 		zap.Error(err),
 	)
 	c.workqueue.AddRateLimited(obj)
 }
 
+// watching returns true if the Secret is in the watching namespaces, or
+// it's referenced by watched objects, or it stores the admin key of APISIX
+// clusters.
+func (c *secretController) watching(key string) bool {
+	if c.controller.namespaceWatching(key) {
+		return true
+	}
+	if len(c.controller.dependencyIndex.dependentsOf(sourceKey(_dependencyKindSecret, key))) > 0 {
+		return true
+	}
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		return false
+	}
+	return c.controller.isAdminKeySecret(namespace, name)
+}
+
 func (c *secretController) onAdd(obj interface{}) {
 	key, err := cache.MetaNamespaceKeyFunc(obj)
 	if err != nil {
 		log.Errorf("found secret object with bad namespace/name: %s, ignore it", err)
 		return
 	}
-	if !c.controller.namespaceWatching(key) {
+	if !c.watching(key) {
 		return
 	}
 
@@ -241,6 +178,7 @@ func (c *secretController) onAdd(obj interface{}) {
 		Type:   types.EventAdd,
 		Object: key,
 	})
+	c.controller.resyncDependents(sourceKey(_dependencyKindSecret, key))
 }
 
 func (c *secretController) onUpdate(prev, curr interface{}) {
@@ -255,17 +193,21 @@ func (c *secretController) onUpdate(prev, curr interface{}) {
 		log.Errorf("found secrets object with bad namespace/name: %s, ignore it", err)
 		return
 	}
-	if !c.controller.namespaceWatching(key) {
+	if !c.watching(key) {
 		return
 	}
 	log.Debugw("secret update event arrived",
-		zap.Any("new object", curr),
-		zap.Any("old object", prev),
+		zap.String("object-key", key),
+		zap.String("old resource version", prevSec.ResourceVersion),
+		zap.String("new resource version", currSec.ResourceVersion),
 	)
 	c.workqueue.AddRateLimited(&types.Event{
 		Type:   types.EventUpdate,
 		Object: key,
 	})
+	if !reflect.DeepEqual(prevSec.Data, currSec.Data) {
+		c.controller.resyncDependents(sourceKey(_dependencyKindSecret, key))
+	}
 }
 
 func (c *secretController) onDelete(obj interface{}) {
@@ -273,7 +215,7 @@ func (c *secretController) onDelete(obj interface{}) {
 	if !ok {
 		tombstone, ok := obj.(cache.DeletedFinalStateUnknown)
 		if !ok {
-			log.Errorf("found secrets: %+v in bad tombstone state", obj)
+			log.Errorf("found secrets: %T in bad tombstone state", obj)
 			return
 		}
 		sec = tombstone.Obj.(*corev1.Secret)
@@ -287,15 +229,16 @@ func (c *secretController) onDelete(obj interface{}) {
 	// FIXME Refactor Controller.namespaceWatching to just use
 	// namespace after all controllers use the same way to fetch
 	// the object.
-	if !c.controller.namespaceWatching(key) {
+	if !c.watching(key) {
 		return
 	}
 	log.Debugw("secret delete event arrived",
-		zap.Any("final state", sec),
+		zap.String("object-key", key),
 	)
 	c.workqueue.AddRateLimited(&types.Event{
 		Type:      types.EventDelete,
 		Object:    key,
 		Tombstone: sec,
 	})
+	c.controller.resyncDependents(sourceKey(_dependencyKindSecret, key))
 }
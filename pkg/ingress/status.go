@@ -17,14 +17,25 @@ package ingress
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
 
 	"go.uber.org/zap"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/client-go/util/workqueue"
 
+	"github.com/apache/apisix-ingress-controller/pkg/kube"
 	configv1 "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/apis/config/v1"
 	configv2alpha1 "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/apis/config/v2alpha1"
+	configv2beta1 "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/apis/config/v2beta1"
 	"github.com/apache/apisix-ingress-controller/pkg/log"
 )
 
@@ -33,84 +44,321 @@ const (
 	_commonSuccessMessage = "Sync Successfully"
 )
 
+// statusKey identifies the object whose status is recorded.
+type statusKey struct {
+	kind         string
+	groupVersion string
+	namespace    string
+	name         string
+}
+
+func (k statusKey) String() string {
+	return fmt.Sprintf("%s(%s) %s/%s", k.kind, k.groupVersion, k.namespace, k.name)
+}
+
+// statusController records conditions of objects asynchronously. Objects
+// are never mutated, the conditions are merge patched with the resource
+// version as the precondition, and conflicts are retried.
+type statusController struct {
+	controller *Controller
+	workqueue  workqueue.RateLimitingInterface
+	workers    int
+
+	mu sync.Mutex
+	// status to be recorded, only the latest one of an object is kept.
+	pending map[statusKey]*statusUpdate
+}
+
+// statusUpdate is the status to be recorded.
+type statusUpdate struct {
+	condition metav1.Condition
+	// rules of ApisixRoute, nil means rules are kept as is.
+	rules *[]configv2alpha1.ApisixRouteRuleStatus
+}
+
+func (c *Controller) newStatusController() *statusController {
+	return &statusController{
+		controller: c,
+		workqueue:  workqueue.NewNamedRateLimitingQueue(workqueue.NewItemFastSlowRateLimiter(time.Second, 60*time.Second, 5), "Status"),
+		workers:    1,
+		pending:    make(map[statusKey]*statusUpdate),
+	}
+}
+
+func (c *statusController) run(ctx context.Context) {
+	log.Info("status controller started")
+	defer log.Info("status controller exited")
+	defer c.workqueue.ShutDown()
+
+	for i := 0; i < c.workers; i++ {
+		go c.runWorker(ctx)
+	}
+	<-ctx.Done()
+}
+
+func (c *statusController) runWorker(ctx context.Context) {
+	for {
+		obj, quit := c.workqueue.Get()
+		if quit {
+			return
+		}
+		err := c.sync(ctx, obj.(statusKey))
+		c.workqueue.Done(obj)
+		c.handleSyncErr(obj, err)
+	}
+}
+
+// enqueue schedules recording the status, a pending status of the same
+// object is replaced.
+func (c *statusController) enqueue(key statusKey, update *statusUpdate) {
+	c.mu.Lock()
+	c.pending[key] = update
+	c.mu.Unlock()
+	c.workqueue.Add(key)
+}
+
+func (c *statusController) sync(ctx context.Context, key statusKey) error {
+	c.mu.Lock()
+	update, ok := c.pending[key]
+	delete(c.pending, key)
+	c.mu.Unlock()
+	if !ok {
+		return nil
+	}
+
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		return c.controller.patchStatusCondition(ctx, key, update)
+	})
+	if err == nil || k8serrors.IsNotFound(err) {
+		return nil
+	}
+	c.mu.Lock()
+	if _, ok := c.pending[key]; !ok {
+		c.pending[key] = update
+	}
+	c.mu.Unlock()
+	return err
+}
+
+func (c *statusController) handleSyncErr(obj interface{}, err error) {
+	if err == nil {
+		c.workqueue.Forget(obj)
+		return
+	}
+	log.Warnw("failed to record status, will retry",
+		zap.Stringer("object", obj.(statusKey)),
+		zap.Error(err),
+	)
+	c.workqueue.AddRateLimited(obj)
+}
+
 // recordStatus record resources status
 func (c *Controller) recordStatus(at interface{}, reason string, err error, status v1.ConditionStatus) {
-	// build condition
+	c.recordStatusWithRules(at, reason, err, status, nil)
+}
+
+// recordRouteStatus records status of ApisixRoute along with the state of
+// each rule.
+func (c *Controller) recordRouteStatus(at interface{}, reason string, err error, status v1.ConditionStatus, rules []configv2alpha1.ApisixRouteRuleStatus) {
+	if rules == nil {
+		rules = []configv2alpha1.ApisixRouteRuleStatus{}
+	}
+	c.recordStatusWithRules(at, reason, err, status, &rules)
+}
+
+func (c *Controller) recordStatusWithRules(at interface{}, reason string, err error, status v1.ConditionStatus, rules *[]configv2alpha1.ApisixRouteRuleStatus) {
 	message := _commonSuccessMessage
 	if err != nil {
 		message = err.Error()
 	}
-	condition := metav1.Condition{
-		Type:    _conditionType,
-		Reason:  reason,
-		Status:  status,
-		Message: message,
+	obj, ok := at.(metav1.Object)
+	if !ok {
+		log.Errorf("unsupported resource record: %s", at)
+		return
 	}
-	client := c.kubeClient.APISIXClient
-
-	switch v := at.(type) {
+	key := statusKey{
+		namespace: obj.GetNamespace(),
+		name:      obj.GetName(),
+	}
+	switch at.(type) {
 	case *configv1.ApisixTls:
-		// set to status
-		if v.Status.Conditions == nil {
-			conditions := make([]metav1.Condition, 0)
-			v.Status.Conditions = &conditions
-		}
-		meta.SetStatusCondition(v.Status.Conditions, condition)
-		if _, errRecord := client.ApisixV1().ApisixTlses(v.Namespace).
-			UpdateStatus(context.TODO(), v, metav1.UpdateOptions{}); errRecord != nil {
-			log.Errorw("failed to record status change for ApisixTls",
-				zap.Error(errRecord),
-				zap.String("name", v.Name),
-				zap.String("namespace", v.Namespace),
-			)
-		}
+		key.kind, key.groupVersion = "ApisixTls", configv1.SchemeGroupVersion.String()
 	case *configv1.ApisixUpstream:
-		// set to status
-		if v.Status.Conditions == nil {
-			conditions := make([]metav1.Condition, 0)
-			v.Status.Conditions = &conditions
+		key.kind, key.groupVersion = "ApisixUpstream", configv1.SchemeGroupVersion.String()
+	case *configv2alpha1.ApisixRoute:
+		key.kind, key.groupVersion = "ApisixRoute", kube.ApisixRouteV2alpha1
+	case *configv2beta1.ApisixRoute:
+		key.kind, key.groupVersion = "ApisixRoute", kube.ApisixRouteV2beta1
+	case *configv2alpha1.ApisixConsumer:
+		key.kind, key.groupVersion = "ApisixConsumer", configv2alpha1.SchemeGroupVersion.String()
+	case *configv2alpha1.ApisixClusterConfig:
+		key.kind, key.groupVersion = "ApisixClusterConfig", configv2alpha1.SchemeGroupVersion.String()
+	default:
+		// This should not be executed
+		log.Errorf("unsupported resource record: %s", at)
+		return
+	}
+	c.statusController.enqueue(key, &statusUpdate{
+		condition: metav1.Condition{
+			Type:               _conditionType,
+			Reason:             reason,
+			Status:             status,
+			Message:            message,
+			ObservedGeneration: obj.GetGeneration(),
+		},
+		rules: rules,
+	})
+}
+
+// statusPatch is the merge patch of status conditions, the resource
+// version makes the patch fail with a conflict if the object is changed.
+type statusPatch struct {
+	Metadata statusPatchMetadata `json:"metadata"`
+	Status   statusPatchStatus   `json:"status"`
+}
+
+type statusPatchMetadata struct {
+	ResourceVersion string `json:"resourceVersion"`
+}
+
+type statusPatchStatus struct {
+	Conditions []metav1.Condition                      `json:"conditions"`
+	Rules      *[]configv2alpha1.ApisixRouteRuleStatus `json:"rules,omitempty"`
+}
+
+// patchStatusCondition sets the condition on the latest object. Conditions
+// observed an older generation are discarded as the newer one will be
+// recorded soon.
+func (c *Controller) patchStatusCondition(ctx context.Context, key statusKey, update *statusUpdate) error {
+	obj, conditions, rules, err := c.getStatus(ctx, key)
+	if err != nil {
+		return err
+	}
+	condition := update.condition
+	if obj.GetGeneration() > condition.ObservedGeneration {
+		log.Debugw("discard stale status condition",
+			zap.Stringer("object", key),
+			zap.Int64("generation", obj.GetGeneration()),
+			zap.Int64("observed_generation", condition.ObservedGeneration),
+		)
+		return nil
+	}
+	rulesChanged := update.rules != nil && !rulesEqual(rules, *update.rules)
+	if !conditionChanged(conditions, condition) && !rulesChanged {
+		return nil
+	}
+	meta.SetStatusCondition(&conditions, condition)
+	patch := &statusPatch{
+		Metadata: statusPatchMetadata{ResourceVersion: obj.GetResourceVersion()},
+		Status:   statusPatchStatus{Conditions: conditions},
+	}
+	if rulesChanged {
+		patch.Status.Rules = update.rules
+	}
+	data, err := json.Marshal(patch)
+	if err != nil {
+		return err
+	}
+	return c.patchStatus(ctx, key, data)
+}
+
+// conditionChanged returns true if the condition is different from the
+// existing one of the same type, the transition time is not compared.
+func conditionChanged(conditions []metav1.Condition, condition metav1.Condition) bool {
+	existing := meta.FindStatusCondition(conditions, condition.Type)
+	if existing == nil {
+		return true
+	}
+	return existing.Status != condition.Status ||
+		existing.Reason != condition.Reason ||
+		existing.Message != condition.Message ||
+		existing.ObservedGeneration != condition.ObservedGeneration
+}
+
+// getStatus fetches the object from the API server rather than informers,
+// so the status is merged with the latest conditions. The returned
+// conditions are owned by the caller, rules are only for ApisixRoute.
+func (c *Controller) getStatus(ctx context.Context, key statusKey) (metav1.Object, []metav1.Condition, []configv2alpha1.ApisixRouteRuleStatus, error) {
+	client := c.kubeClient.APISIXClient
+	opts := metav1.GetOptions{}
+	switch {
+	case key.kind == "ApisixTls":
+		v, err := client.ApisixV1().ApisixTlses(key.namespace).Get(ctx, key.name, opts)
+		if err != nil {
+			return nil, nil, nil, err
 		}
-		meta.SetStatusCondition(v.Status.Conditions, condition)
-		if _, errRecord := client.ApisixV1().ApisixUpstreams(v.Namespace).
-			UpdateStatus(context.TODO(), v, metav1.UpdateOptions{}); errRecord != nil {
-			log.Errorw("failed to record status change for ApisixUpstream",
-				zap.Error(errRecord),
-				zap.String("name", v.Name),
-				zap.String("namespace", v.Namespace),
-			)
+		return v, copyConditions(v.Status.Conditions), nil, nil
+	case key.kind == "ApisixUpstream":
+		v, err := client.ApisixV1().ApisixUpstreams(key.namespace).Get(ctx, key.name, opts)
+		if err != nil {
+			return nil, nil, nil, err
 		}
-	case *configv2alpha1.ApisixRoute:
-		// set to status
-		if v.Status.Conditions == nil {
-			conditions := make([]metav1.Condition, 0)
-			v.Status.Conditions = &conditions
+		return v, copyConditions(v.Status.Conditions), nil, nil
+	case key.kind == "ApisixRoute" && key.groupVersion == kube.ApisixRouteV2alpha1:
+		v, err := client.ApisixV2alpha1().ApisixRoutes(key.namespace).Get(ctx, key.name, opts)
+		if err != nil {
+			return nil, nil, nil, err
 		}
-		meta.SetStatusCondition(v.Status.Conditions, condition)
-		if _, errRecord := client.ApisixV2alpha1().ApisixRoutes(v.Namespace).
-			UpdateStatus(context.TODO(), v, metav1.UpdateOptions{}); errRecord != nil {
-			log.Errorw("failed to record status change for ApisixRoute",
-				zap.Error(errRecord),
-				zap.String("name", v.Name),
-				zap.String("namespace", v.Namespace),
-			)
+		return v, copyConditions(v.Status.Conditions), v.Status.Rules, nil
+	case key.kind == "ApisixRoute" && key.groupVersion == kube.ApisixRouteV2beta1:
+		v, err := client.ApisixV2beta1().ApisixRoutes(key.namespace).Get(ctx, key.name, opts)
+		if err != nil {
+			return nil, nil, nil, err
 		}
-	case *configv2alpha1.ApisixConsumer:
-		// set to status
-		if v.Status.Conditions == nil {
-			conditions := make([]metav1.Condition, 0)
-			v.Status.Conditions = &conditions
+		return v, copyConditions(&v.Status.Conditions), v.Status.Rules, nil
+	case key.kind == "ApisixConsumer":
+		v, err := client.ApisixV2alpha1().ApisixConsumers(key.namespace).Get(ctx, key.name, opts)
+		if err != nil {
+			return nil, nil, nil, err
 		}
-		meta.SetStatusCondition(v.Status.Conditions, condition)
-		if _, errRecord := client.ApisixV2alpha1().ApisixConsumers(v.Namespace).
-			UpdateStatus(context.TODO(), v, metav1.UpdateOptions{}); errRecord != nil {
-			log.Errorw("failed to record status change for ApisixConsumer",
-				zap.Error(errRecord),
-				zap.String("name", v.Name),
-				zap.String("namespace", v.Namespace),
-			)
+		return v, copyConditions(v.Status.Conditions), nil, nil
+	case key.kind == "ApisixClusterConfig":
+		v, err := client.ApisixV2alpha1().ApisixClusterConfigs().Get(ctx, key.name, opts)
+		if err != nil {
+			return nil, nil, nil, err
 		}
+		return v, copyConditions(v.Status.Conditions), nil, nil
 	default:
-		// This should not be executed
-		log.Errorf("unsupported resource record: %s", v)
+		return nil, nil, nil, fmt.Errorf("unsupported status object %s", key)
+	}
+}
+
+func (c *Controller) patchStatus(ctx context.Context, key statusKey, data []byte) error {
+	var (
+		client = c.kubeClient.APISIXClient
+		opts   = metav1.PatchOptions{}
+		pt     = k8stypes.MergePatchType
+		err    error
+	)
+	switch {
+	case key.kind == "ApisixTls":
+		_, err = client.ApisixV1().ApisixTlses(key.namespace).Patch(ctx, key.name, pt, data, opts, "status")
+	case key.kind == "ApisixUpstream":
+		_, err = client.ApisixV1().ApisixUpstreams(key.namespace).Patch(ctx, key.name, pt, data, opts, "status")
+	case key.kind == "ApisixRoute" && key.groupVersion == kube.ApisixRouteV2alpha1:
+		_, err = client.ApisixV2alpha1().ApisixRoutes(key.namespace).Patch(ctx, key.name, pt, data, opts, "status")
+	case key.kind == "ApisixRoute" && key.groupVersion == kube.ApisixRouteV2beta1:
+		_, err = client.ApisixV2beta1().ApisixRoutes(key.namespace).Patch(ctx, key.name, pt, data, opts, "status")
+	case key.kind == "ApisixConsumer":
+		_, err = client.ApisixV2alpha1().ApisixConsumers(key.namespace).Patch(ctx, key.name, pt, data, opts, "status")
+	case key.kind == "ApisixClusterConfig":
+		_, err = client.ApisixV2alpha1().ApisixClusterConfigs().Patch(ctx, key.name, pt, data, opts, "status")
+	default:
+		err = fmt.Errorf("unsupported status object %s", key)
+	}
+	return err
+}
+
+func rulesEqual(a, b []configv2alpha1.ApisixRouteRuleStatus) bool {
+	if len(a) == 0 && len(b) == 0 {
+		return true
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+func copyConditions(conditions *[]metav1.Condition) []metav1.Condition {
+	if conditions == nil {
+		return nil
 	}
+	return append([]metav1.Condition(nil), (*conditions)...)
 }
@@ -0,0 +1,178 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ingress
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/apache/apisix-ingress-controller/pkg/kube"
+	configv2alpha1 "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/apis/config/v2alpha1"
+	configv2beta1 "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/apis/config/v2beta1"
+	"github.com/apache/apisix-ingress-controller/pkg/kube/apisix/client/clientset/versioned/fake"
+)
+
+func TestRecordStatus(t *testing.T) {
+	ar := &configv2beta1.ApisixRoute{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "httpbin",
+			Namespace:  "default",
+			Generation: 2,
+		},
+	}
+	acc := &configv2alpha1.ApisixClusterConfig{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "default",
+			Generation: 1,
+		},
+	}
+	client := fake.NewSimpleClientset(ar, acc)
+	c := &Controller{
+		kubeClient: &kube.KubeClient{
+			APISIXClient: client,
+		},
+	}
+	c.statusController = c.newStatusController()
+	ctx := context.Background()
+
+	c.recordStatus(ar, _resourceSyncAborted, errors.New("failed"), metav1.ConditionFalse)
+	// The later condition replaces the pending one.
+	c.recordStatus(ar, _resourceSynced, nil, metav1.ConditionTrue)
+	c.recordStatus(acc, _resourceSynced, nil, metav1.ConditionTrue)
+	assert.Equal(t, 2, c.statusController.workqueue.Len())
+	for c.statusController.workqueue.Len() > 0 {
+		key, _ := c.statusController.workqueue.Get()
+		assert.Nil(t, c.statusController.sync(ctx, key.(statusKey)))
+		c.statusController.workqueue.Done(key)
+	}
+
+	// The object in informers is not mutated.
+	assert.Len(t, ar.Status.Conditions, 0)
+	latest, err := client.ApisixV2beta1().ApisixRoutes("default").Get(ctx, "httpbin", metav1.GetOptions{})
+	assert.Nil(t, err)
+	cond := meta.FindStatusCondition(latest.Status.Conditions, _conditionType)
+	assert.NotNil(t, cond)
+	assert.Equal(t, metav1.ConditionTrue, cond.Status)
+	assert.Equal(t, _resourceSynced, cond.Reason)
+	assert.Equal(t, int64(2), cond.ObservedGeneration)
+
+	latestACC, err := client.ApisixV2alpha1().ApisixClusterConfigs().Get(ctx, "default", metav1.GetOptions{})
+	assert.Nil(t, err)
+	assert.NotNil(t, latestACC.Status.Conditions)
+	assert.Len(t, *latestACC.Status.Conditions, 1)
+	assert.Equal(t, int64(1), (*latestACC.Status.Conditions)[0].ObservedGeneration)
+
+	c.recordRouteStatus(ar, _resourceSynced, nil, metav1.ConditionTrue, []configv2alpha1.ApisixRouteRuleStatus{
+		{Name: "rule1", Kind: "http", RouteID: "r1", State: _ruleStateSynced},
+	})
+	assert.Nil(t, c.statusController.sync(ctx, statusKey{
+		kind:         "ApisixRoute",
+		groupVersion: kube.ApisixRouteV2beta1,
+		namespace:    "default",
+		name:         "httpbin",
+	}))
+	latest, err = client.ApisixV2beta1().ApisixRoutes("default").Get(ctx, "httpbin", metav1.GetOptions{})
+	assert.Nil(t, err)
+	assert.Len(t, latest.Status.Rules, 1)
+	assert.Equal(t, "r1", latest.Status.Rules[0].RouteID)
+
+	// Conditions observed an older generation are discarded.
+	stale := ar.DeepCopy()
+	stale.Generation = 1
+	c.recordStatus(stale, _resourceSyncAborted, errors.New("failed"), metav1.ConditionFalse)
+	assert.Nil(t, c.statusController.sync(ctx, statusKey{
+		kind:         "ApisixRoute",
+		groupVersion: kube.ApisixRouteV2beta1,
+		namespace:    "default",
+		name:         "httpbin",
+	}))
+	latest, err = client.ApisixV2beta1().ApisixRoutes("default").Get(ctx, "httpbin", metav1.GetOptions{})
+	assert.Nil(t, err)
+	assert.Equal(t, metav1.ConditionTrue, latest.Status.Conditions[0].Status)
+
+	// Deleted objects are ignored.
+	assert.Nil(t, client.ApisixV2beta1().ApisixRoutes("default").Delete(ctx, "httpbin", metav1.DeleteOptions{}))
+	c.recordStatus(ar, _resourceSynced, nil, metav1.ConditionTrue)
+	assert.Nil(t, c.statusController.sync(ctx, statusKey{
+		kind:         "ApisixRoute",
+		groupVersion: kube.ApisixRouteV2beta1,
+		namespace:    "default",
+		name:         "httpbin",
+	}))
+}
+
+func TestRecordV2alpha1RouteStatusUnchanged(t *testing.T) {
+	rules := []configv2alpha1.ApisixRouteRuleStatus{
+		{Name: "rule1", Kind: "http", RouteID: "r1", State: _ruleStateSynced},
+	}
+	ar := &configv2alpha1.ApisixRoute{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "httpbin",
+			Namespace:  "default",
+			Generation: 1,
+		},
+		Status: configv2alpha1.ApisixRouteStatus{
+			Conditions: &[]metav1.Condition{
+				{
+					Type:               _conditionType,
+					Status:             metav1.ConditionTrue,
+					Reason:             _resourceSynced,
+					Message:            _commonSuccessMessage,
+					ObservedGeneration: 1,
+				},
+			},
+			Rules: rules,
+		},
+	}
+	client := fake.NewSimpleClientset(ar)
+	c := &Controller{
+		kubeClient: &kube.KubeClient{
+			APISIXClient: client,
+		},
+	}
+	c.statusController = c.newStatusController()
+
+	c.recordRouteStatus(ar, _resourceSynced, nil, metav1.ConditionTrue, rules)
+	assert.Nil(t, c.statusController.sync(context.Background(), statusKey{
+		kind:         "ApisixRoute",
+		groupVersion: kube.ApisixRouteV2alpha1,
+		namespace:    "default",
+		name:         "httpbin",
+	}))
+	for _, action := range client.Actions() {
+		assert.NotEqual(t, "patch", action.GetVerb())
+	}
+}
+
+func TestConditionChanged(t *testing.T) {
+	cond := metav1.Condition{
+		Type:               _conditionType,
+		Status:             metav1.ConditionTrue,
+		Reason:             _resourceSynced,
+		Message:            _commonSuccessMessage,
+		ObservedGeneration: 1,
+	}
+	assert.True(t, conditionChanged(nil, cond))
+	conditions := []metav1.Condition{cond}
+	assert.False(t, conditionChanged(conditions, cond))
+	cond.ObservedGeneration = 2
+	assert.True(t, conditionChanged(conditions, cond))
+}
@@ -0,0 +1,251 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package ingress
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/go-multierror"
+	"go.uber.org/zap"
+
+	"github.com/apache/apisix-ingress-controller/pkg/apisix"
+	"github.com/apache/apisix-ingress-controller/pkg/log"
+	apisixv1 "github.com/apache/apisix-ingress-controller/pkg/types/apisix/v1"
+)
+
+// upstreamIndex records which Kubernetes objects reference an upstream in
+// each APISIX cluster, an upstream can be shared by several Ingresses and
+// ApisixRoutes, so it should be deleted only when no objects reference it.
+type upstreamIndex struct {
+	sync.RWMutex
+	// cluster name -> references
+	clusters map[string]*upstreamRefs
+}
+
+type upstreamRefs struct {
+	// upstream id -> source keys
+	sources map[string]map[string]struct{}
+	// source key -> upstream ids
+	upstreams map[string]map[string]struct{}
+}
+
+func newUpstreamIndex() *upstreamIndex {
+	return &upstreamIndex{
+		clusters: make(map[string]*upstreamRefs),
+	}
+}
+
+// sourceKey returns the key of a Kubernetes object in the index.
+func sourceKey(kind, key string) string {
+	return kind + "/" + key
+}
+
+// set replaces the upstreams referenced by the source in the cluster, an
+// empty ids removes the source from the index.
+func (idx *upstreamIndex) set(cluster, source string, ids []string) {
+	idx.Lock()
+	defer idx.Unlock()
+
+	refs, ok := idx.clusters[cluster]
+	if !ok {
+		if len(ids) == 0 {
+			return
+		}
+		refs = &upstreamRefs{
+			sources:   make(map[string]map[string]struct{}),
+			upstreams: make(map[string]map[string]struct{}),
+		}
+		idx.clusters[cluster] = refs
+	}
+	for id := range refs.upstreams[source] {
+		delete(refs.sources[id], source)
+		if len(refs.sources[id]) == 0 {
+			delete(refs.sources, id)
+		}
+	}
+	delete(refs.upstreams, source)
+	if len(ids) == 0 {
+		if len(refs.upstreams) == 0 {
+			delete(idx.clusters, cluster)
+		}
+		return
+	}
+
+	owned := make(map[string]struct{}, len(ids))
+	for _, id := range ids {
+		owned[id] = struct{}{}
+		if refs.sources[id] == nil {
+			refs.sources[id] = make(map[string]struct{})
+		}
+		refs.sources[id][source] = struct{}{}
+	}
+	refs.upstreams[source] = owned
+}
+
+// referencedBy returns the sorted source keys which reference the upstream
+// in the cluster.
+func (idx *upstreamIndex) referencedBy(cluster, id string) []string {
+	idx.RLock()
+	defer idx.RUnlock()
+
+	refs, ok := idx.clusters[cluster]
+	if !ok {
+		return nil
+	}
+	var sources []string
+	for source := range refs.sources[id] {
+		sources = append(sources, source)
+	}
+	sort.Strings(sources)
+	return sources
+}
+
+// deleteCluster removes all references in the cluster.
+func (idx *upstreamIndex) deleteCluster(cluster string) {
+	idx.Lock()
+	defer idx.Unlock()
+	delete(idx.clusters, cluster)
+}
+
+// upstreamRefs returns IDs of upstreams which are created or referenced by
+// the manifest, including the ones in plugins like traffic-split.
+func (m *manifest) upstreamRefs() []string {
+	if m == nil {
+		return nil
+	}
+	var ids []string
+	for _, u := range m.upstreams {
+		ids = append(ids, u.ID)
+	}
+	for _, r := range m.routes {
+		ids = append(ids, r.ReferencedUpstreams()...)
+	}
+	for _, sr := range m.streamRoutes {
+		if sr.UpstreamId != "" {
+			ids = append(ids, sr.UpstreamId)
+		}
+	}
+	return ids
+}
+
+// syncSourceManifests pushes resources of a source object to the selected
+// APISIX clusters. The om is the old manifest (nil if the object is added)
+// and m is the desired one (nil if the object is deleted). Resources are
+// diffed in clusters which were selected before, and created or deleted
+// in clusters which are selected or unselected newly. Resources of the
+// object which are still in the cache of a cluster (e.g. pushed before a
+// restart) but not desired anymore are deleted as well. The upstream index
+// is updated first, so upstreams still referenced by other objects won't
+// be deleted.
+func (c *Controller) syncSourceManifests(ctx context.Context, source string, clusters []string, om, m *manifest) error {
+	prev := c.placedClusters(source)
+	var merr *multierror.Error
+	for _, cluster := range unionClusters(unionClusters(prev, clusters), c.clusterNames()) {
+		var (
+			added   *manifest
+			updated *manifest
+			deleted *manifest
+			desired *manifest
+		)
+		if m != nil && containsCluster(clusters, cluster) {
+			desired = m
+			if om != nil && containsCluster(prev, cluster) {
+				added, updated, deleted = m.diff(om)
+			} else {
+				added = m
+			}
+		} else if containsCluster(prev, cluster) || containsCluster(clusters, cluster) {
+			deleted = om
+			if deleted == nil {
+				deleted = m
+			}
+		}
+		pushed, err := c.pushedManifest(ctx, cluster, source)
+		if err != nil {
+			merr = multierror.Append(merr, err)
+			continue
+		}
+		deleted = deleted.merge(pushed.subtract(desired))
+		c.upstreamIndex.set(cluster, source, desired.upstreamRefs())
+		deleted = c.filterReferencedUpstreams(cluster, deleted)
+		if err := c.syncManifests(ctx, cluster, added, updated, deleted); err != nil {
+			merr = multierror.Append(merr, err)
+		}
+	}
+	if merr != nil {
+		return merr
+	}
+	if m == nil {
+		clusters = nil
+	}
+	c.placeSource(source, clusters)
+	return nil
+}
+
+// pushedManifest returns resources of the source object in the cache of
+// the cluster, i.e. the ones pushed to the cluster and not deleted yet.
+func (c *Controller) pushedManifest(ctx context.Context, cluster, source string) (*manifest, error) {
+	snap, err := c.apisix.Cluster(cluster).ListBySource(ctx, labelSourceKey(source))
+	if err != nil {
+		if err == apisix.ErrClusterNotExist {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &manifest{
+		routes:       snap.Routes,
+		upstreams:    snap.Upstreams,
+		streamRoutes: snap.StreamRoutes,
+		ssls:         snap.SSLs,
+		consumers:    snap.Consumers,
+	}, nil
+}
+
+// labelSourceKey converts the key of a source object in the index to the
+// one composed by its source labels, see apisixv1.SourceKey.
+func labelSourceKey(source string) string {
+	parts := strings.SplitN(source, "/", 3)
+	if len(parts) != 3 {
+		return source
+	}
+	return parts[0] + "/" + parts[1] + "/" + apisixv1.ComposeSourceNameLabel(parts[2])
+}
+
+// filterReferencedUpstreams removes upstreams which are still referenced
+// by other objects in the cluster from the deleted manifest.
+func (c *Controller) filterReferencedUpstreams(cluster string, deleted *manifest) *manifest {
+	if deleted == nil || len(deleted.upstreams) == 0 {
+		return deleted
+	}
+	var upstreams []*apisixv1.Upstream
+	for _, u := range deleted.upstreams {
+		if sources := c.upstreamIndex.referencedBy(cluster, u.ID); len(sources) > 0 {
+			log.Infow("upstream is still referenced by other objects, skip deleting it",
+				zap.String("cluster", cluster),
+				zap.String("upstream_id", u.ID),
+				zap.String("upstream_name", u.Name),
+				zap.Strings("sources", sources),
+			)
+			continue
+		}
+		upstreams = append(upstreams, u)
+	}
+	m := *deleted
+	m.upstreams = upstreams
+	return &m
+}
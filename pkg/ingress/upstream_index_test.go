@@ -0,0 +1,295 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package ingress
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/apache/apisix-ingress-controller/pkg/apisix"
+	"github.com/apache/apisix-ingress-controller/pkg/apisix/cache"
+	apisixv1 "github.com/apache/apisix-ingress-controller/pkg/types/apisix/v1"
+)
+
+// fakeCluster reflects pushed resources to its cache directly.
+type fakeCluster struct {
+	apisix.Cluster
+	cache cache.Cache
+}
+
+func newFakeCluster(t *testing.T) *fakeCluster {
+	db, err := cache.NewMemDBCache()
+	assert.Nil(t, err)
+	return &fakeCluster{cache: db}
+}
+
+func (f *fakeCluster) Route() apisix.Route       { return &fakeRouteClient{cache: f.cache} }
+func (f *fakeCluster) Upstream() apisix.Upstream { return &fakeUpstreamClient{cache: f.cache} }
+func (f *fakeCluster) SSL() apisix.SSL           { return &fakeSSLClient{cache: f.cache} }
+
+func (f *fakeCluster) ListBySource(_ context.Context, source string) (*apisix.Snapshot, error) {
+	routes, err := f.cache.ListRoutesBySource(source)
+	if err != nil {
+		return nil, err
+	}
+	ssls, err := f.cache.ListSSLBySource(source)
+	if err != nil {
+		return nil, err
+	}
+	snap := &apisix.Snapshot{Routes: routes, SSLs: ssls}
+	seen := make(map[string]struct{})
+	for _, r := range routes {
+		for _, id := range r.ReferencedUpstreams() {
+			if _, ok := seen[id]; ok {
+				continue
+			}
+			seen[id] = struct{}{}
+			if u, err := f.cache.GetUpstream(id); err == nil {
+				snap.Upstreams = append(snap.Upstreams, u)
+			}
+		}
+	}
+	return snap, nil
+}
+
+type fakeRouteClient struct {
+	apisix.Route
+	cache cache.Cache
+}
+
+func (f *fakeRouteClient) Create(_ context.Context, r *apisixv1.Route) (*apisixv1.Route, error) {
+	return r, f.cache.InsertRoute(r)
+}
+
+func (f *fakeRouteClient) Update(_ context.Context, r *apisixv1.Route) (*apisixv1.Route, error) {
+	return r, f.cache.InsertRoute(r)
+}
+
+func (f *fakeRouteClient) Delete(_ context.Context, r *apisixv1.Route) error {
+	return f.cache.DeleteRoute(r)
+}
+
+type fakeUpstreamClient struct {
+	apisix.Upstream
+	cache cache.Cache
+}
+
+func (f *fakeUpstreamClient) Create(_ context.Context, u *apisixv1.Upstream) (*apisixv1.Upstream, error) {
+	return u, f.cache.InsertUpstream(u)
+}
+
+func (f *fakeUpstreamClient) Update(_ context.Context, u *apisixv1.Upstream) (*apisixv1.Upstream, error) {
+	return u, f.cache.InsertUpstream(u)
+}
+
+func (f *fakeUpstreamClient) Delete(_ context.Context, u *apisixv1.Upstream) error {
+	return f.cache.DeleteUpstream(u)
+}
+
+type fakeSSLClient struct {
+	apisix.SSL
+	cache cache.Cache
+}
+
+func (f *fakeSSLClient) Create(_ context.Context, ssl *apisixv1.Ssl) (*apisixv1.Ssl, error) {
+	return ssl, f.cache.InsertSSL(ssl)
+}
+
+func (f *fakeSSLClient) Update(_ context.Context, ssl *apisixv1.Ssl) (*apisixv1.Ssl, error) {
+	return ssl, f.cache.InsertSSL(ssl)
+}
+
+func (f *fakeSSLClient) Delete(_ context.Context, ssl *apisixv1.Ssl) error {
+	return f.cache.DeleteSSL(ssl)
+}
+
+func TestUpstreamIndex(t *testing.T) {
+	idx := newUpstreamIndex()
+	ing := sourceKey("Ingress", "default/foo")
+	ar := sourceKey("ApisixRoute", "default/bar")
+
+	idx.set("default", ing, []string{"u1"})
+	idx.set("default", ar, []string{"u1", "u2"})
+	assert.Equal(t, []string{"ApisixRoute/default/bar", "Ingress/default/foo"}, idx.referencedBy("default", "u1"))
+	assert.Equal(t, []string{"ApisixRoute/default/bar"}, idx.referencedBy("default", "u2"))
+
+	// References are isolated by clusters.
+	idx.set("external", ing, []string{"u2"})
+	assert.Equal(t, []string{"Ingress/default/foo"}, idx.referencedBy("external", "u2"))
+	assert.Nil(t, idx.referencedBy("external", "u1"))
+	idx.deleteCluster("external")
+	assert.Nil(t, idx.referencedBy("external", "u2"))
+
+	// The ApisixRoute no longer references u2.
+	idx.set("default", ar, []string{"u1"})
+	assert.Nil(t, idx.referencedBy("default", "u2"))
+
+	idx.set("default", ing, nil)
+	assert.Equal(t, []string{"ApisixRoute/default/bar"}, idx.referencedBy("default", "u1"))
+	idx.set("default", ar, nil)
+	assert.Nil(t, idx.referencedBy("default", "u1"))
+	assert.Len(t, idx.clusters, 0)
+}
+
+func TestManifestUpstreamRefs(t *testing.T) {
+	var m *manifest
+	assert.Nil(t, m.upstreamRefs())
+
+	m = &manifest{
+		routes: []*apisixv1.Route{
+			{
+				UpstreamId: "u1",
+				Plugins: apisixv1.Plugins{
+					"traffic-split": &apisixv1.TrafficSplitConfig{
+						Rules: []apisixv1.TrafficSplitConfigRule{
+							{
+								WeightedUpstreams: []apisixv1.TrafficSplitConfigRuleWeightedUpstream{
+									{UpstreamID: "u2", Weight: 10},
+									{Weight: 90},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		upstreams: []*apisixv1.Upstream{
+			{Metadata: apisixv1.Metadata{ID: "u1"}},
+			{Metadata: apisixv1.Metadata{ID: "u2"}},
+		},
+		streamRoutes: []*apisixv1.StreamRoute{
+			{UpstreamId: "u3"},
+		},
+	}
+	assert.ElementsMatch(t, []string{"u1", "u2", "u1", "u2", "u3"}, m.upstreamRefs())
+}
+
+func TestSyncSourceManifestsAfterRestart(t *testing.T) {
+	c := newClusterSelectorController(t)
+	cli := &fakeAPISIX{
+		clusters: map[string]*fakeCluster{
+			"default":  newFakeCluster(t),
+			"external": newFakeCluster(t),
+		},
+	}
+	c.apisix = cli
+
+	labels := map[string]string{
+		apisixv1.LabelSourceKind:      "Ingress",
+		apisixv1.LabelSourceNamespace: "default",
+		apisixv1.LabelSourceName:      "foo",
+	}
+	ups := &apisixv1.Upstream{Metadata: apisixv1.Metadata{ID: "u1", Labels: labels}}
+	route := &apisixv1.Route{Metadata: apisixv1.Metadata{ID: "r1", Labels: labels}, UpstreamId: "u1"}
+	// Pushed to the external cluster before the restart, the placement is
+	// lost while the selector is changed to the default cluster.
+	external := cli.clusters["external"].cache
+	assert.Nil(t, external.InsertUpstream(ups))
+	assert.Nil(t, external.InsertRoute(route))
+
+	m := &manifest{
+		routes:    []*apisixv1.Route{route},
+		upstreams: []*apisixv1.Upstream{ups},
+	}
+	source := sourceKey("Ingress", "default/foo")
+	assert.Nil(t, c.syncSourceManifests(context.Background(), source, []string{"default"}, nil, m))
+
+	_, err := external.GetRoute("r1")
+	assert.Equal(t, cache.ErrNotFound, err)
+	_, err = external.GetUpstream("u1")
+	assert.Equal(t, cache.ErrNotFound, err)
+	_, err = cli.clusters["default"].cache.GetRoute("r1")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"default"}, c.placedClusters(source))
+}
+
+func TestSyncSourceManifestsDeletesStaleUpstream(t *testing.T) {
+	c := newClusterSelectorController(t)
+	cli := &fakeAPISIX{
+		clusters: map[string]*fakeCluster{
+			"default":  newFakeCluster(t),
+			"external": newFakeCluster(t),
+		},
+	}
+	c.apisix = cli
+	labels := map[string]string{
+		apisixv1.LabelSourceKind:      "Ingress",
+		apisixv1.LabelSourceNamespace: "default",
+		apisixv1.LabelSourceName:      "foo",
+	}
+	source := sourceKey("Ingress", "default/foo")
+	ctx := context.Background()
+
+	oldUps := &apisixv1.Upstream{Metadata: apisixv1.Metadata{ID: "u80", Labels: labels}}
+	m := &manifest{
+		routes: []*apisixv1.Route{
+			{Metadata: apisixv1.Metadata{ID: "r1", Labels: labels}, UpstreamId: "u80"},
+			{Metadata: apisixv1.Metadata{ID: "r2", Labels: labels}, UpstreamId: "u80"},
+		},
+		upstreams: []*apisixv1.Upstream{oldUps},
+	}
+	assert.Nil(t, c.syncSourceManifests(ctx, source, []string{"default"}, nil, m))
+
+	// The Service port is changed and a rule is removed, the Ingress is
+	// resynced as an ADD event.
+	newUps := &apisixv1.Upstream{Metadata: apisixv1.Metadata{ID: "u8080", Labels: labels}}
+	m = &manifest{
+		routes:    []*apisixv1.Route{{Metadata: apisixv1.Metadata{ID: "r1", Labels: labels}, UpstreamId: "u8080"}},
+		upstreams: []*apisixv1.Upstream{newUps},
+	}
+	assert.Nil(t, c.syncSourceManifests(ctx, source, []string{"default"}, nil, m))
+
+	db := cli.clusters["default"].cache
+	_, err := db.GetUpstream("u80")
+	assert.Equal(t, cache.ErrNotFound, err)
+	_, err = db.GetUpstream("u8080")
+	assert.Nil(t, err)
+	route, err := db.GetRoute("r1")
+	assert.Nil(t, err)
+	assert.Equal(t, "u8080", route.UpstreamId)
+	_, err = db.GetRoute("r2")
+	assert.Equal(t, cache.ErrNotFound, err)
+}
+
+func TestManifestSubtractAndMerge(t *testing.T) {
+	var m *manifest
+	assert.Nil(t, m.subtract(&manifest{}))
+	assert.Nil(t, m.merge(nil))
+
+	m = &manifest{
+		routes: []*apisixv1.Route{
+			{Metadata: apisixv1.Metadata{ID: "r1"}},
+			{Metadata: apisixv1.Metadata{ID: "r2"}},
+		},
+		consumers: []*apisixv1.Consumer{{Username: "jack"}},
+	}
+	o := &manifest{
+		routes:    []*apisixv1.Route{{Metadata: apisixv1.Metadata{ID: "r2"}}},
+		consumers: []*apisixv1.Consumer{{Username: "jack"}},
+	}
+	left := m.subtract(o)
+	assert.Len(t, left.routes, 1)
+	assert.Equal(t, "r1", left.routes[0].ID)
+	assert.Len(t, left.consumers, 0)
+	assert.Nil(t, o.subtract(m))
+	assert.Equal(t, m, m.subtract(nil))
+
+	merged := o.merge(m)
+	assert.Len(t, merged.routes, 2)
+	assert.Len(t, merged.consumers, 1)
+	assert.Equal(t, o, o.merge(o))
+}
@@ -67,8 +67,8 @@ const (
 type ApisixRoute struct {
 	metav1.TypeMeta   `json:",inline" yaml:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty" yaml:"metadata,omitempty"`
-	Spec              *ApisixRouteSpec `json:"spec,omitempty" yaml:"spec,omitempty"`
-	Status            ApisixStatus     `json:"status,omitempty" yaml:"status,omitempty"`
+	Spec              *ApisixRouteSpec  `json:"spec,omitempty" yaml:"spec,omitempty"`
+	Status            ApisixRouteStatus `json:"status,omitempty" yaml:"status,omitempty"`
 }
 
 // ApisixStatus is the status report for Apisix ingress Resources
@@ -76,6 +76,33 @@ type ApisixStatus struct {
 	Conditions *[]metav1.Condition `json:"conditions,omitempty" yaml:"conditions,omitempty"`
 }
 
+// ApisixRouteStatus is the status report for ApisixRoute.
+type ApisixRouteStatus struct {
+	Conditions *[]metav1.Condition `json:"conditions,omitempty" yaml:"conditions,omitempty"`
+	// Rules reports the sync state of each rule.
+	Rules []ApisixRouteRuleStatus `json:"rules,omitempty" yaml:"rules,omitempty"`
+}
+
+// ApisixRouteRuleStatus is the sync state of a rule in ApisixRoute.
+type ApisixRouteRuleStatus struct {
+	// Name is the name of the rule.
+	Name string `json:"name" yaml:"name"`
+	// Kind is the section which the rule belongs to, http, or tcp
+	// (stream in v2beta1).
+	Kind string `json:"kind" yaml:"kind"`
+	// RouteID is the ID of the route or stream route in APISIX.
+	RouteID string `json:"routeID,omitempty" yaml:"routeID,omitempty"`
+	// UpstreamIDs are IDs of upstreams referenced by the route.
+	UpstreamIDs []string `json:"upstreamIDs,omitempty" yaml:"upstreamIDs,omitempty"`
+	// State is Synced, Failed or Aborted, rules are aborted if they
+	// can't be pushed as other rules failed.
+	State string `json:"state" yaml:"state"`
+	// FieldPath is the path of the invalid field, e.g. spec.http[0].match.exprs.
+	FieldPath string `json:"fieldPath,omitempty" yaml:"fieldPath,omitempty"`
+	// Message describes why the rule failed.
+	Message string `json:"message,omitempty" yaml:"message,omitempty"`
+}
+
 // ApisixRouteSpec is the spec definition for ApisixRouteSpec.
 type ApisixRouteSpec struct {
 	HTTP []*ApisixRouteHTTP `json:"http,omitempty" yaml:"http,omitempty"`
@@ -323,8 +350,43 @@ type ApisixClusterAdminConfig struct {
 	BaseURL string `json:"baseURL" yaml:"baseURL"`
 	// AdminKey is used to verify the admin API user.
 	AdminKey string `json:"adminKey" yaml:"adminKey"`
+	// AdminKeySecretRef references the Secret key which stores the admin
+	// key, it takes precedence over AdminKey.
+	// +optional
+	AdminKeySecretRef *ApisixSecretKeyReference `json:"adminKeySecretRef,omitempty" yaml:"adminKeySecretRef,omitempty"`
 	// ClientTimeout is request timeout for the APISIX Admin API client
 	ClientTimeout types.TimeDuration `json:"clientTimeout" yaml:"clientTimeout"`
+	// TLS contains the TLS configurations to access the Admin API.
+	// +optional
+	TLS *ApisixClusterAdminTLSConfig `json:"tls,omitempty" yaml:"tls,omitempty"`
+}
+
+// ApisixSecretKeyReference references a key in a Secret.
+type ApisixSecretKeyReference struct {
+	// Namespace is the namespace of the Secret.
+	Namespace string `json:"namespace" yaml:"namespace"`
+	// Name is the name of the Secret.
+	Name string `json:"name" yaml:"name"`
+	// Key is the key in the Secret data.
+	Key string `json:"key" yaml:"key"`
+}
+
+// ApisixClusterAdminTLSConfig is the TLS config to access the APISIX Admin API.
+type ApisixClusterAdminTLSConfig struct {
+	// CASecretRef references a Secret which contains the CA bundle (in the
+	// "ca.crt" key) to verify the Admin API server certificate.
+	// +optional
+	CASecretRef *corev1.SecretReference `json:"caSecretRef,omitempty" yaml:"caSecretRef,omitempty"`
+	// ClientCertSecretRef references a kubernetes.io/tls Secret, the "tls.crt"
+	// and "tls.key" in it are used as the client certificate.
+	// +optional
+	ClientCertSecretRef *corev1.SecretReference `json:"clientCertSecretRef,omitempty" yaml:"clientCertSecretRef,omitempty"`
+	// ServerName is used to verify the hostname of the server certificate.
+	// +optional
+	ServerName string `json:"serverName,omitempty" yaml:"serverName,omitempty"`
+	// InsecureSkipVerify disables the server certificate verification.
+	// +optional
+	InsecureSkipVerify bool `json:"insecureSkipVerify,omitempty" yaml:"insecureSkipVerify,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
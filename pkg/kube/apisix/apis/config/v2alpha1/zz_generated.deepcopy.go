@@ -28,7 +28,17 @@ import (
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ApisixClusterAdminConfig) DeepCopyInto(out *ApisixClusterAdminConfig) {
 	*out = *in
+	if in.AdminKeySecretRef != nil {
+		in, out := &in.AdminKeySecretRef, &out.AdminKeySecretRef
+		*out = new(ApisixSecretKeyReference)
+		**out = **in
+	}
 	out.ClientTimeout = in.ClientTimeout
+	if in.TLS != nil {
+		in, out := &in.TLS, &out.TLS
+		*out = new(ApisixClusterAdminTLSConfig)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -42,6 +52,32 @@ func (in *ApisixClusterAdminConfig) DeepCopy() *ApisixClusterAdminConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApisixClusterAdminTLSConfig) DeepCopyInto(out *ApisixClusterAdminTLSConfig) {
+	*out = *in
+	if in.CASecretRef != nil {
+		in, out := &in.CASecretRef, &out.CASecretRef
+		*out = new(v1.SecretReference)
+		**out = **in
+	}
+	if in.ClientCertSecretRef != nil {
+		in, out := &in.ClientCertSecretRef, &out.ClientCertSecretRef
+		*out = new(v1.SecretReference)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApisixClusterAdminTLSConfig.
+func (in *ApisixClusterAdminTLSConfig) DeepCopy() *ApisixClusterAdminTLSConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ApisixClusterAdminTLSConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ApisixClusterConfig) DeepCopyInto(out *ApisixClusterConfig) {
 	*out = *in
@@ -114,7 +150,7 @@ func (in *ApisixClusterConfigSpec) DeepCopyInto(out *ApisixClusterConfigSpec) {
 	if in.Admin != nil {
 		in, out := &in.Admin, &out.Admin
 		*out = new(ApisixClusterAdminConfig)
-		**out = **in
+		(*in).DeepCopyInto(*out)
 	}
 	return
 }
@@ -643,6 +679,27 @@ func (in *ApisixRouteList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApisixRouteRuleStatus) DeepCopyInto(out *ApisixRouteRuleStatus) {
+	*out = *in
+	if in.UpstreamIDs != nil {
+		in, out := &in.UpstreamIDs, &out.UpstreamIDs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApisixRouteRuleStatus.
+func (in *ApisixRouteRuleStatus) DeepCopy() *ApisixRouteRuleStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ApisixRouteRuleStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ApisixRouteSpec) DeepCopyInto(out *ApisixRouteSpec) {
 	*out = *in
@@ -681,6 +738,40 @@ func (in *ApisixRouteSpec) DeepCopy() *ApisixRouteSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApisixRouteStatus) DeepCopyInto(out *ApisixRouteStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = new([]metav1.Condition)
+		if **in != nil {
+			in, out := *in, *out
+			*out = make([]metav1.Condition, len(*in))
+			for i := range *in {
+				(*in)[i].DeepCopyInto(&(*out)[i])
+			}
+		}
+	}
+	if in.Rules != nil {
+		in, out := &in.Rules, &out.Rules
+		*out = make([]ApisixRouteRuleStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApisixRouteStatus.
+func (in *ApisixRouteStatus) DeepCopy() *ApisixRouteStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ApisixRouteStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ApisixRouteTCP) DeepCopyInto(out *ApisixRouteTCP) {
 	*out = *in
@@ -732,6 +823,22 @@ func (in *ApisixRouteTCPMatch) DeepCopy() *ApisixRouteTCPMatch {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApisixSecretKeyReference) DeepCopyInto(out *ApisixSecretKeyReference) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApisixSecretKeyReference.
+func (in *ApisixSecretKeyReference) DeepCopy() *ApisixSecretKeyReference {
+	if in == nil {
+		return nil
+	}
+	out := new(ApisixSecretKeyReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ApisixStatus) DeepCopyInto(out *ApisixStatus) {
 	*out = *in
@@ -37,6 +37,8 @@ type ApisixRoute struct {
 // ApisixStatus is the status report for Apisix ingress Resources
 type ApisixStatus struct {
 	Conditions []metav1.Condition `json:"conditions,omitempty" yaml:"conditions,omitempty"`
+	// Rules reports the sync state of each rule.
+	Rules []v2alpha1.ApisixRouteRuleStatus `json:"rules,omitempty" yaml:"rules,omitempty"`
 }
 
 // ApisixRouteSpec is the spec definition for ApisixRouteSpec.
@@ -43,5 +43,6 @@ func (t *translator) TranslateApisixConsumer(ac *configv2alpha1.ApisixConsumer)
 	consumer := apisixv1.NewDefaultConsumer()
 	consumer.Username = apisixv1.ComposeConsumerName(ac.Namespace, ac.Name)
 	consumer.Plugins = plugins
+	setSourceLabels(consumer.Labels, SourceKindApisixConsumer, ac)
 	return consumer, nil
 }
@@ -16,6 +16,7 @@ package translation
 
 import (
 	"errors"
+	"fmt"
 	"strings"
 
 	"go.uber.org/zap"
@@ -76,6 +77,7 @@ func (t *translator) TranslateRouteV1(ar *configv1.ApisixRoute) (*TranslateConte
 			ctx.addRoute(route)
 		}
 	}
+	ctx.setSourceLabels(SourceKindApisixRoute, ar)
 	return ctx, nil
 }
 
@@ -91,6 +93,7 @@ func (t *translator) TranslateRouteV2alpha1NotStrictly(ar *configv2alpha1.Apisix
 	if err := t.translateTCPRouteNotStrictly(ctx, ar); err != nil {
 		return nil, err
 	}
+	ctx.setSourceLabels(SourceKindApisixRoute, ar)
 	return ctx, nil
 }
 
@@ -105,6 +108,7 @@ func (t *translator) TranslateRouteV2alpha1(ar *configv2alpha1.ApisixRoute) (*Tr
 	if err := t.translateTCPRoute(ctx, ar); err != nil {
 		return nil, err
 	}
+	ctx.setSourceLabels(SourceKindApisixRoute, ar)
 	return ctx, nil
 }
 
@@ -145,6 +149,7 @@ func (t *translator) TranslateRouteV2beta1(ar *configv2beta1.ApisixRoute) (*Tran
 	if err := t.translateStreamRoute(ctx, ar); err != nil {
 		return nil, err
 	}
+	ctx.setSourceLabels(SourceKindApisixRoute, ar)
 	return ctx, nil
 }
 
@@ -159,14 +164,18 @@ func (t *translator) TranslateRouteV2beta1NotStrictly(ar *configv2beta1.ApisixRo
 	if err := t.translateStreamRouteNotStrictly(ctx, ar); err != nil {
 		return nil, err
 	}
+	ctx.setSourceLabels(SourceKindApisixRoute, ar)
 	return ctx, nil
 }
 
 func (t *translator) translateHTTPRouteV2beta1(ctx *TranslateContext, ar *configv2beta1.ApisixRoute) error {
 	ruleNameMap := make(map[string]struct{})
-	for _, part := range ar.Spec.HTTP {
+	for i, part := range ar.Spec.HTTP {
 		if _, ok := ruleNameMap[part.Name]; ok {
-			return errors.New("duplicated route rule name")
+			return &translateError{
+				field:  fmt.Sprintf("spec.http[%d].name", i),
+				reason: "duplicated route rule name",
+			}
 		}
 		ruleNameMap[part.Name] = struct{}{}
 		backends := part.Backends
@@ -185,7 +194,7 @@ func (t *translator) translateHTTPRouteV2beta1(ctx *TranslateContext, ar *config
 				zap.Any("apisix_route", ar),
 				zap.Error(err),
 			)
-			return err
+			return fieldError(backendField(i, len(part.Backends) > 0), err)
 		}
 
 		pluginMap := make(apisixv1.Plugins)
@@ -221,7 +230,7 @@ func (t *translator) translateHTTPRouteV2beta1(ctx *TranslateContext, ar *config
 					zap.Error(err),
 					zap.Any("ApisixRoute", ar),
 				)
-				return err
+				return fieldError(fmt.Sprintf("spec.http[%d].match.exprs", i), err)
 			}
 		}
 		if err := validateRemoteAddrs(part.Match.RemoteAddrs); err != nil {
@@ -230,7 +239,7 @@ func (t *translator) translateHTTPRouteV2beta1(ctx *TranslateContext, ar *config
 				zap.Strings("remote_addrs", part.Match.RemoteAddrs),
 				zap.Any("ApisixRoute", ar),
 			)
-			return err
+			return fieldError(fmt.Sprintf("spec.http[%d].match.remoteAddrs", i), err)
 		}
 
 		upstreamName := apisixv1.ComposeUpstreamName(ar.Namespace, backend.ServiceName, backend.Subset, svcPort)
@@ -262,7 +271,7 @@ func (t *translator) translateHTTPRouteV2beta1(ctx *TranslateContext, ar *config
 					zap.Error(err),
 					zap.Any("ApisixRoute", ar),
 				)
-				return err
+				return fieldError(fmt.Sprintf("spec.http[%d].backends", i), err)
 			}
 			route.Plugins["traffic-split"] = plugin
 		}
@@ -270,7 +279,7 @@ func (t *translator) translateHTTPRouteV2beta1(ctx *TranslateContext, ar *config
 		if !ctx.checkUpstreamExist(upstreamName) {
 			ups, err := t.translateUpstream(ar.Namespace, backend.ServiceName, backend.Subset, backend.ResolveGranularity, svcClusterIP, svcPort)
 			if err != nil {
-				return err
+				return fieldError(backendField(i, len(part.Backends) > 0), err)
 			}
 			ctx.addUpstream(ups)
 		}
@@ -280,9 +289,12 @@ func (t *translator) translateHTTPRouteV2beta1(ctx *TranslateContext, ar *config
 
 func (t *translator) translateHTTPRoute(ctx *TranslateContext, ar *configv2alpha1.ApisixRoute) error {
 	ruleNameMap := make(map[string]struct{})
-	for _, part := range ar.Spec.HTTP {
+	for i, part := range ar.Spec.HTTP {
 		if _, ok := ruleNameMap[part.Name]; ok {
-			return errors.New("duplicated route rule name")
+			return &translateError{
+				field:  fmt.Sprintf("spec.http[%d].name", i),
+				reason: "duplicated route rule name",
+			}
 		}
 		ruleNameMap[part.Name] = struct{}{}
 		backends := part.Backends
@@ -301,7 +313,7 @@ func (t *translator) translateHTTPRoute(ctx *TranslateContext, ar *configv2alpha
 				zap.Any("apisix_route", ar),
 				zap.Error(err),
 			)
-			return err
+			return fieldError(backendField(i, len(part.Backends) > 0), err)
 		}
 
 		pluginMap := make(apisixv1.Plugins)
@@ -337,7 +349,7 @@ func (t *translator) translateHTTPRoute(ctx *TranslateContext, ar *configv2alpha
 					zap.Error(err),
 					zap.Any("ApisixRoute", ar),
 				)
-				return err
+				return fieldError(fmt.Sprintf("spec.http[%d].match.exprs", i), err)
 			}
 		}
 		if err := validateRemoteAddrs(part.Match.RemoteAddrs); err != nil {
@@ -346,7 +358,7 @@ func (t *translator) translateHTTPRoute(ctx *TranslateContext, ar *configv2alpha
 				zap.Strings("remote_addrs", part.Match.RemoteAddrs),
 				zap.Any("ApisixRoute", ar),
 			)
-			return err
+			return fieldError(fmt.Sprintf("spec.http[%d].match.remoteAddrs", i), err)
 		}
 
 		upstreamName := apisixv1.ComposeUpstreamName(ar.Namespace, backend.ServiceName, backend.Subset, svcPort)
@@ -374,7 +386,7 @@ func (t *translator) translateHTTPRoute(ctx *TranslateContext, ar *configv2alpha
 					zap.Error(err),
 					zap.Any("ApisixRoute", ar),
 				)
-				return err
+				return fieldError(fmt.Sprintf("spec.http[%d].backends", i), err)
 			}
 			route.Plugins["traffic-split"] = plugin
 		}
@@ -382,7 +394,7 @@ func (t *translator) translateHTTPRoute(ctx *TranslateContext, ar *configv2alpha
 		if !ctx.checkUpstreamExist(upstreamName) {
 			ups, err := t.translateUpstream(ar.Namespace, backend.ServiceName, backend.Subset, backend.ResolveGranularity, svcClusterIP, svcPort)
 			if err != nil {
-				return err
+				return fieldError(backendField(i, len(part.Backends) > 0), err)
 			}
 			ctx.addUpstream(ups)
 		}
@@ -490,6 +502,16 @@ func (t *translator) translateRouteMatchExprs(nginxVars []configv2alpha1.ApisixR
 	return vars, nil
 }
 
+// backendField returns the path of the backend which is used as the
+// upstream of the http route, the deprecated backend is used if backends
+// are not specified.
+func backendField(index int, hasBackends bool) string {
+	if hasBackends {
+		return fmt.Sprintf("spec.http[%d].backends[0]", index)
+	}
+	return fmt.Sprintf("spec.http[%d].backend", index)
+}
+
 // translateTCPRouteNotStrictly translates tcp route with a loose way, only generate ID and Name for delete Event.
 func (t *translator) translateTCPRouteNotStrictly(ctx *TranslateContext, ar *configv2alpha1.ApisixRoute) error {
 	for _, part := range ar.Spec.TCP {
@@ -513,9 +535,12 @@ func (t *translator) translateTCPRouteNotStrictly(ctx *TranslateContext, ar *con
 
 func (t *translator) translateStreamRoute(ctx *TranslateContext, ar *configv2beta1.ApisixRoute) error {
 	ruleNameMap := make(map[string]struct{})
-	for _, part := range ar.Spec.Stream {
+	for i, part := range ar.Spec.Stream {
 		if _, ok := ruleNameMap[part.Name]; ok {
-			return errors.New("duplicated route rule name")
+			return &translateError{
+				field:  fmt.Sprintf("spec.stream[%d].name", i),
+				reason: "duplicated route rule name",
+			}
 		}
 		ruleNameMap[part.Name] = struct{}{}
 		backend := part.Backend
@@ -526,7 +551,7 @@ func (t *translator) translateStreamRoute(ctx *TranslateContext, ar *configv2bet
 				zap.Any("apisix_route", ar),
 				zap.Error(err),
 			)
-			return err
+			return fieldError(fmt.Sprintf("spec.stream[%d].backend", i), err)
 		}
 		sr := apisixv1.NewDefaultStreamRoute()
 		name := apisixv1.ComposeStreamRouteName(ar.Namespace, ar.Name, part.Name)
@@ -534,7 +559,7 @@ func (t *translator) translateStreamRoute(ctx *TranslateContext, ar *configv2bet
 		sr.ServerPort = part.Match.IngressPort
 		ups, err := t.translateUpstream(ar.Namespace, backend.ServiceName, backend.Subset, backend.ResolveGranularity, svcClusterIP, svcPort)
 		if err != nil {
-			return err
+			return fieldError(fmt.Sprintf("spec.stream[%d].backend", i), err)
 		}
 		sr.UpstreamId = ups.ID
 		ctx.addStreamRoute(sr)
@@ -548,9 +573,12 @@ func (t *translator) translateStreamRoute(ctx *TranslateContext, ar *configv2bet
 
 func (t *translator) translateTCPRoute(ctx *TranslateContext, ar *configv2alpha1.ApisixRoute) error {
 	ruleNameMap := make(map[string]struct{})
-	for _, part := range ar.Spec.TCP {
+	for i, part := range ar.Spec.TCP {
 		if _, ok := ruleNameMap[part.Name]; ok {
-			return errors.New("duplicated route rule name")
+			return &translateError{
+				field:  fmt.Sprintf("spec.tcp[%d].name", i),
+				reason: "duplicated route rule name",
+			}
 		}
 		ruleNameMap[part.Name] = struct{}{}
 		backend := &part.Backend
@@ -561,7 +589,7 @@ func (t *translator) translateTCPRoute(ctx *TranslateContext, ar *configv2alpha1
 				zap.Any("apisix_route", ar),
 				zap.Error(err),
 			)
-			return err
+			return fieldError(fmt.Sprintf("spec.tcp[%d].backend", i), err)
 		}
 		sr := apisixv1.NewDefaultStreamRoute()
 		name := apisixv1.ComposeStreamRouteName(ar.Namespace, ar.Name, part.Name)
@@ -569,7 +597,7 @@ func (t *translator) translateTCPRoute(ctx *TranslateContext, ar *configv2alpha1
 		sr.ServerPort = part.Match.IngressPort
 		ups, err := t.translateUpstream(ar.Namespace, backend.ServiceName, backend.Subset, backend.ResolveGranularity, svcClusterIP, svcPort)
 		if err != nil {
-			return err
+			return fieldError(fmt.Sprintf("spec.tcp[%d].backend", i), err)
 		}
 		sr.UpstreamId = ups.ID
 		ctx.addStreamRoute(sr)
@@ -320,7 +320,8 @@ func TestTranslateApisixRouteV2alpha1WithDuplicatedName(t *testing.T) {
 	}
 
 	_, err = tr.TranslateRouteV2alpha1(ar)
-	assert.Equal(t, err.Error(), "duplicated route rule name")
+	assert.Equal(t, err.Error(), "spec.http[1].name: duplicated route rule name")
+	assert.Equal(t, "spec.http[1].name", FieldPath(err))
 }
 
 func TestTranslateApisixRouteV2alpha1NotStrictly(t *testing.T) {
@@ -15,7 +15,13 @@
 package translation
 
 import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
 	"errors"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
 
 	"github.com/apache/apisix-ingress-controller/pkg/id"
 	configv1 "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/apis/config/v1"
@@ -23,24 +29,104 @@ import (
 )
 
 var (
-	// ErrEmptyCert means the cert field in Kubernetes Secret is not found.
+	// ErrEmptyCert means neither the standard (tls.crt or ca.crt) nor the
+	// legacy cert field in Kubernetes Secret is found.
 	ErrEmptyCert = errors.New("missing cert field")
-	// ErrEmptyPrivKey means the key field in Kubernetes Secret is not found.
+	// ErrEmptyPrivKey means neither the tls.key nor the legacy key field
+	// in Kubernetes Secret is found.
 	ErrEmptyPrivKey = errors.New("missing key field")
 )
 
+const (
+	// The legacy keys in Secrets, they're used only if the standard keys
+	// of kubernetes.io/tls Secrets are absent.
+	_legacyCertKey = "cert"
+	_legacyKeyKey  = "key"
+	// _caCertKey is the key of CA certificates in Secrets.
+	_caCertKey = "ca.crt"
+)
+
+// secretData returns the value of the first present key in the Secret.
+func secretData(sec *corev1.Secret, keys ...string) ([]byte, bool) {
+	for _, key := range keys {
+		if data, ok := sec.Data[key]; ok && len(data) > 0 {
+			return data, true
+		}
+	}
+	return nil, false
+}
+
+// extractKeyPair reads the certificate chain and the private key from the
+// Secret, and validates that they match each other. The standard keys are
+// used as a whole if any of them is present, otherwise the legacy ones, so
+// that a certificate and a private key are never picked from different key
+// sets.
+func extractKeyPair(sec *corev1.Secret) ([]byte, []byte, error) {
+	certKey, keyKey := corev1.TLSCertKey, corev1.TLSPrivateKeyKey
+	if _, ok := secretData(sec, certKey, keyKey); !ok {
+		certKey, keyKey = _legacyCertKey, _legacyKeyKey
+	}
+	cert, ok := secretData(sec, certKey)
+	if !ok {
+		return nil, nil, ErrEmptyCert
+	}
+	key, ok := secretData(sec, keyKey)
+	if !ok {
+		return nil, nil, ErrEmptyPrivKey
+	}
+	if _, err := parseCertificates(cert); err != nil {
+		return nil, nil, fmt.Errorf("secret %s/%s: %s", sec.Namespace, sec.Name, err)
+	}
+	if _, err := tls.X509KeyPair(cert, key); err != nil {
+		return nil, nil, fmt.Errorf("secret %s/%s: invalid key pair: %s", sec.Namespace, sec.Name, err)
+	}
+	return cert, key, nil
+}
+
+// extractCA reads the CA certificates from the Secret.
+func extractCA(sec *corev1.Secret) ([]byte, error) {
+	ca, ok := secretData(sec, _caCertKey, _legacyCertKey)
+	if !ok {
+		return nil, ErrEmptyCert
+	}
+	if _, err := parseCertificates(ca); err != nil {
+		return nil, fmt.Errorf("secret %s/%s: %s", sec.Namespace, sec.Name, err)
+	}
+	return ca, nil
+}
+
+// parseCertificates parses all PEM encoded certificates in the data.
+func parseCertificates(data []byte) ([]*x509.Certificate, error) {
+	var certs []*x509.Certificate
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("invalid certificate: %s", err)
+		}
+		certs = append(certs, cert)
+	}
+	if len(certs) == 0 {
+		return nil, errors.New("invalid certificate: no PEM encoded certificate found")
+	}
+	return certs, nil
+}
+
 func (t *translator) TranslateSSL(tls *configv1.ApisixTls) (*apisixv1.Ssl, error) {
 	s, err := t.SecretLister.Secrets(tls.Spec.Secret.Namespace).Get(tls.Spec.Secret.Name)
 	if err != nil {
 		return nil, err
 	}
-	cert, ok := s.Data["cert"]
-	if !ok {
-		return nil, ErrEmptyCert
-	}
-	key, ok := s.Data["key"]
-	if !ok {
-		return nil, ErrEmptyPrivKey
+	cert, key, err := extractKeyPair(s)
+	if err != nil {
+		return nil, err
 	}
 	var snis []string
 	for _, host := range tls.Spec.Hosts {
@@ -53,7 +139,7 @@ func (t *translator) TranslateSSL(tls *configv1.ApisixTls) (*apisixv1.Ssl, error
 		Key:    string(key),
 		Status: 1,
 		Labels: map[string]string{
-			"managed-by": "apisix-ingress-controller",
+			apisixv1.LabelManagedBy: apisixv1.LabelManagedByValue,
 		},
 	}
 	if tls.Spec.Client != nil {
@@ -61,9 +147,9 @@ func (t *translator) TranslateSSL(tls *configv1.ApisixTls) (*apisixv1.Ssl, error
 		if err != nil {
 			return nil, err
 		}
-		ca, ok := caSecret.Data["cert"]
-		if !ok {
-			return nil, ErrEmptyCert
+		ca, err := extractCA(caSecret)
+		if err != nil {
+			return nil, err
 		}
 		ssl.Client = &apisixv1.MutualTLSClientConfig{
 			CA:    string(ca),
@@ -71,5 +157,6 @@ func (t *translator) TranslateSSL(tls *configv1.ApisixTls) (*apisixv1.Ssl, error
 		}
 	}
 
+	setSourceLabels(ssl.Labels, SourceKindApisixTls, tls)
 	return ssl, nil
 }
@@ -0,0 +1,153 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package translation
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	listerscorev1 "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+
+	configv1 "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/apis/config/v1"
+)
+
+func genKeyPair(t *testing.T, cn string) ([]byte, []byte) {
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.Nil(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: cn},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+		DNSNames:     []string{cn},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &priv.PublicKey, priv)
+	assert.Nil(t, err)
+	keyDer, err := x509.MarshalECPrivateKey(priv)
+	assert.Nil(t, err)
+	cert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	key := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer})
+	return cert, key
+}
+
+func TestTranslateSSL(t *testing.T) {
+	cert, key := genKeyPair(t, "api.foo.com")
+	otherCert, _ := genKeyPair(t, "api.bar.com")
+
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	secrets := []*corev1.Secret{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "standard", Namespace: "default"},
+			Type:       corev1.SecretTypeTLS,
+			Data: map[string][]byte{
+				corev1.TLSCertKey:       cert,
+				corev1.TLSPrivateKeyKey: key,
+				"ca.crt":                otherCert,
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "legacy", Namespace: "default"},
+			Data: map[string][]byte{
+				"cert": cert,
+				"key":  key,
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "mismatched", Namespace: "default"},
+			Data: map[string][]byte{
+				corev1.TLSCertKey:       otherCert,
+				corev1.TLSPrivateKeyKey: key,
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "malformed", Namespace: "default"},
+			Data: map[string][]byte{
+				corev1.TLSCertKey:       []byte("not a certificate"),
+				corev1.TLSPrivateKeyKey: key,
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "nokey", Namespace: "default"},
+			Data: map[string][]byte{
+				corev1.TLSCertKey: cert,
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "mixed", Namespace: "default"},
+			Data: map[string][]byte{
+				corev1.TLSCertKey: cert,
+				"key":             key,
+			},
+		},
+	}
+	for _, sec := range secrets {
+		assert.Nil(t, indexer.Add(sec))
+	}
+	tr := &translator{
+		TranslatorOptions: &TranslatorOptions{
+			SecretLister: listerscorev1.NewSecretLister(indexer),
+		},
+	}
+	newTls := func(secret, caSecret string) *configv1.ApisixTls {
+		tls := &configv1.ApisixTls{
+			ObjectMeta: metav1.ObjectMeta{Name: "tls", Namespace: "default"},
+			Spec: &configv1.ApisixTlsSpec{
+				Hosts:  []configv1.HostType{"api.foo.com"},
+				Secret: configv1.ApisixSecret{Name: secret, Namespace: "default"},
+			},
+		}
+		if caSecret != "" {
+			tls.Spec.Client = &configv1.ApisixMutualTlsClientConfig{
+				CASecret: configv1.ApisixSecret{Name: caSecret, Namespace: "default"},
+			}
+		}
+		return tls
+	}
+
+	ssl, err := tr.TranslateSSL(newTls("standard", "standard"))
+	assert.Nil(t, err)
+	assert.Equal(t, string(cert), ssl.Cert)
+	assert.Equal(t, string(key), ssl.Key)
+	assert.Equal(t, string(otherCert), ssl.Client.CA)
+
+	// Legacy keys are used as a fallback.
+	ssl, err = tr.TranslateSSL(newTls("legacy", "legacy"))
+	assert.Nil(t, err)
+	assert.Equal(t, string(cert), ssl.Cert)
+	assert.Equal(t, string(cert), ssl.Client.CA)
+
+	_, err = tr.TranslateSSL(newTls("mismatched", ""))
+	assert.Contains(t, err.Error(), "invalid key pair")
+	_, err = tr.TranslateSSL(newTls("malformed", ""))
+	assert.Contains(t, err.Error(), "no PEM encoded certificate found")
+	_, err = tr.TranslateSSL(newTls("nokey", ""))
+	assert.Equal(t, ErrEmptyPrivKey, err)
+	// The key sets are not mixed.
+	_, err = tr.TranslateSSL(newTls("mixed", ""))
+	assert.Equal(t, ErrEmptyPrivKey, err)
+	_, err = tr.TranslateSSL(newTls("standard", "nokey"))
+	assert.Equal(t, ErrEmptyCert, err)
+}
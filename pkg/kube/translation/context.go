@@ -14,13 +14,29 @@
 // limitations under the License.
 package translation
 
-import apisix "github.com/apache/apisix-ingress-controller/pkg/types/apisix/v1"
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apisix "github.com/apache/apisix-ingress-controller/pkg/types/apisix/v1"
+)
+
+const (
+	// SourceKindIngress is the source kind of resources translated from Ingresses.
+	SourceKindIngress = "Ingress"
+	// SourceKindApisixRoute is the source kind of resources translated from ApisixRoutes.
+	SourceKindApisixRoute = "ApisixRoute"
+	// SourceKindApisixTls is the source kind of resources translated from ApisixTlses.
+	SourceKindApisixTls = "ApisixTls"
+	// SourceKindApisixConsumer is the source kind of resources translated from ApisixConsumers.
+	SourceKindApisixConsumer = "ApisixConsumer"
+)
 
 // TranslateContext contains APISIX resources generated by the translator.
 type TranslateContext struct {
 	Routes       []*apisix.Route
 	StreamRoutes []*apisix.StreamRoute
 	Upstreams    []*apisix.Upstream
+	SSLs         []*apisix.Ssl
 
 	upstreamMap map[string]struct{}
 }
@@ -41,7 +57,72 @@ func (tc *TranslateContext) addUpstream(u *apisix.Upstream) {
 	tc.Upstreams = append(tc.Upstreams, u)
 }
 
+// addSSL adds the SSL, SNIs are merged if an SSL with the same ID exists.
+func (tc *TranslateContext) addSSL(ssl *apisix.Ssl) {
+	for _, exist := range tc.SSLs {
+		if exist.ID != ssl.ID {
+			continue
+		}
+	next:
+		for _, sni := range ssl.Snis {
+			for _, s := range exist.Snis {
+				if s == sni {
+					continue next
+				}
+			}
+			exist.Snis = append(exist.Snis, sni)
+		}
+		return
+	}
+	tc.SSLs = append(tc.SSLs, ssl)
+}
+
 func (tc *TranslateContext) checkUpstreamExist(name string) (ok bool) {
 	_, ok = tc.upstreamMap[name]
 	return
 }
+
+// setSourceLabels records the kind, namespace, name and UID of the source
+// Kubernetes object to all resources, so that orphaned resources can be
+// found after the object is deleted. Note upstreams might be shared by
+// several objects, their source labels are the ones of the object which
+// pushed them lastly, so they are not reliable to find the objects which
+// reference an upstream.
+func (tc *TranslateContext) setSourceLabels(kind string, obj metav1.Object) {
+	for _, r := range tc.Routes {
+		if r.Labels == nil {
+			r.Labels = make(map[string]string)
+		}
+		setSourceLabels(r.Labels, kind, obj)
+	}
+	for _, sr := range tc.StreamRoutes {
+		if sr.Labels == nil {
+			sr.Labels = make(map[string]string)
+		}
+		setSourceLabels(sr.Labels, kind, obj)
+	}
+	for _, u := range tc.Upstreams {
+		if u.Labels == nil {
+			u.Labels = make(map[string]string)
+		}
+		setSourceLabels(u.Labels, kind, obj)
+	}
+	for _, ssl := range tc.SSLs {
+		if ssl.Labels == nil {
+			ssl.Labels = make(map[string]string)
+		}
+		setSourceLabels(ssl.Labels, kind, obj)
+	}
+}
+
+func setSourceLabels(labels map[string]string, kind string, obj metav1.Object) {
+	labels[apisix.LabelSourceKind] = kind
+	labels[apisix.LabelSourceName] = apisix.ComposeSourceNameLabel(obj.GetName())
+	// APISIX doesn't accept empty label values.
+	if obj.GetNamespace() != "" {
+		labels[apisix.LabelSourceNamespace] = obj.GetNamespace()
+	}
+	if obj.GetUID() != "" {
+		labels[apisix.LabelSourceUID] = string(obj.GetUID())
+	}
+}
@@ -15,10 +15,13 @@
 package translation
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	configv2beta1 "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/apis/config/v2beta1"
 	apisix "github.com/apache/apisix-ingress-controller/pkg/types/apisix/v1"
 )
 
@@ -74,3 +77,60 @@ func TestTranslateContext(t *testing.T) {
 	assert.Equal(t, ctx.checkUpstreamExist("aaa"), true)
 	assert.Equal(t, ctx.checkUpstreamExist("bbb"), false)
 }
+
+func TestTranslateContextSetSourceLabels(t *testing.T) {
+	ctx := &TranslateContext{
+		upstreamMap: make(map[string]struct{}),
+	}
+	ctx.addRoute(apisix.NewDefaultRoute())
+	ctx.addStreamRoute(&apisix.StreamRoute{})
+	ctx.addUpstream(apisix.NewDefaultUpstream())
+
+	ar := &configv2beta1.ApisixRoute{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "httpbin",
+			Namespace: "test",
+			UID:       "6a7e2c39-2d8c-4a5e-9f0c-5e3c1b0a3c4f",
+		},
+	}
+	ctx.setSourceLabels(SourceKindApisixRoute, ar)
+
+	expected := map[string]string{
+		apisix.LabelManagedBy:       apisix.LabelManagedByValue,
+		apisix.LabelSourceKind:      "ApisixRoute",
+		apisix.LabelSourceNamespace: "test",
+		apisix.LabelSourceName:      "httpbin",
+		apisix.LabelSourceUID:       "6a7e2c39-2d8c-4a5e-9f0c-5e3c1b0a3c4f",
+	}
+	assert.Equal(t, expected, ctx.Routes[0].Labels)
+	assert.Equal(t, expected, ctx.Upstreams[0].Labels)
+	delete(expected, apisix.LabelManagedBy)
+	assert.Equal(t, expected, ctx.StreamRoutes[0].Labels)
+
+	// Empty values are not set.
+	ar.UID = ""
+	ctx.setSourceLabels(SourceKindApisixRoute, ar)
+	assert.Equal(t, "6a7e2c39-2d8c-4a5e-9f0c-5e3c1b0a3c4f", ctx.StreamRoutes[0].Labels[apisix.LabelSourceUID])
+	ctx.StreamRoutes[0].Labels = nil
+	ctx.setSourceLabels(SourceKindApisixRoute, ar)
+	_, ok := ctx.StreamRoutes[0].Labels[apisix.LabelSourceUID]
+	assert.False(t, ok)
+}
+
+func TestSetSourceLabelsWithLongName(t *testing.T) {
+	name := strings.Repeat("a", 253)
+	labels := make(map[string]string)
+	setSourceLabels(labels, SourceKindIngress, &metav1.ObjectMeta{Name: name, Namespace: "test"})
+	value := labels[apisix.LabelSourceName]
+	assert.Len(t, value, apisix.MaxLabelValueLength)
+	assert.True(t, strings.HasPrefix(value, strings.Repeat("a", 47)+"-"))
+
+	// Names with the same prefix are distinguished by the digest.
+	setSourceLabels(labels, SourceKindIngress, &metav1.ObjectMeta{Name: name[:252] + "b", Namespace: "test"})
+	assert.Len(t, labels[apisix.LabelSourceName], apisix.MaxLabelValueLength)
+	assert.NotEqual(t, value, labels[apisix.LabelSourceName])
+
+	// Short names are kept as is.
+	setSourceLabels(labels, SourceKindIngress, &metav1.ObjectMeta{Name: name[:64], Namespace: "test"})
+	assert.Equal(t, name[:64], labels[apisix.LabelSourceName])
+}
@@ -16,6 +16,7 @@ package translation
 
 import (
 	"bytes"
+	"sort"
 	"strings"
 
 	"go.uber.org/zap"
@@ -25,10 +26,18 @@ import (
 	"k8s.io/apimachinery/pkg/util/intstr"
 
 	"github.com/apache/apisix-ingress-controller/pkg/id"
+	"github.com/apache/apisix-ingress-controller/pkg/kube"
 	"github.com/apache/apisix-ingress-controller/pkg/log"
 	apisixv1 "github.com/apache/apisix-ingress-controller/pkg/types/apisix/v1"
 )
 
+const (
+	// Priorities of catch-all routes translated from Ingress default
+	// backends, a host scoped one takes precedence over a global one.
+	_defaultBackendHostPriority   = -1
+	_defaultBackendGlobalPriority = -2
+)
+
 func (t *translator) translateIngressV1(ing *networkingv1.Ingress) (*TranslateContext, error) {
 	ctx := &TranslateContext{
 		upstreamMap: make(map[string]struct{}),
@@ -36,6 +45,9 @@ func (t *translator) translateIngressV1(ing *networkingv1.Ingress) (*TranslateCo
 	plugins := t.translateAnnotations(ing.Annotations)
 
 	for _, rule := range ing.Spec.Rules {
+		if rule.HTTP == nil {
+			continue
+		}
 		for _, pathRule := range rule.HTTP.Paths {
 			var (
 				ups *apisixv1.Upstream
@@ -85,6 +97,23 @@ func (t *translator) translateIngressV1(ing *networkingv1.Ingress) (*TranslateCo
 			ctx.addRoute(route)
 		}
 	}
+	if backend := ing.Spec.DefaultBackend; backend != nil && backend.Service != nil {
+		ups, err := t.translateUpstreamFromIngressV1(ing.Namespace, backend.Service)
+		if err != nil {
+			log.Errorw("failed to translate ingress default backend to upstream",
+				zap.Error(err),
+				zap.Any("ingress", ing),
+			)
+			return nil, err
+		}
+		ctx.addUpstream(ups)
+		hosts, _ := IngressDefaultBackendScope(kube.MustNewIngress(ing))
+		ctx.addRoute(newIngressDefaultBackendRoute(ing.Namespace, ing.Name, hosts, plugins, ups))
+	}
+	for _, tls := range ing.Spec.TLS {
+		t.translateIngressTLS(ctx, ing.Namespace, ing.Name, tls.Hosts, tls.SecretName)
+	}
+	ctx.setSourceLabels(SourceKindIngress, ing)
 	return ctx, nil
 }
 
@@ -95,6 +124,9 @@ func (t *translator) translateIngressV1beta1(ing *networkingv1beta1.Ingress) (*T
 	plugins := t.translateAnnotations(ing.Annotations)
 
 	for _, rule := range ing.Spec.Rules {
+		if rule.HTTP == nil {
+			continue
+		}
 		for _, pathRule := range rule.HTTP.Paths {
 			var (
 				ups *apisixv1.Upstream
@@ -144,6 +176,23 @@ func (t *translator) translateIngressV1beta1(ing *networkingv1beta1.Ingress) (*T
 			ctx.addRoute(route)
 		}
 	}
+	if backend := ing.Spec.Backend; backend != nil {
+		ups, err := t.translateUpstreamFromIngressV1beta1(ing.Namespace, backend.ServiceName, backend.ServicePort)
+		if err != nil {
+			log.Errorw("failed to translate ingress default backend to upstream",
+				zap.Error(err),
+				zap.Any("ingress", ing),
+			)
+			return nil, err
+		}
+		ctx.addUpstream(ups)
+		hosts, _ := IngressDefaultBackendScope(kube.MustNewIngress(ing))
+		ctx.addRoute(newIngressDefaultBackendRoute(ing.Namespace, ing.Name, hosts, plugins, ups))
+	}
+	for _, tls := range ing.Spec.TLS {
+		t.translateIngressTLS(ctx, ing.Namespace, ing.Name, tls.Hosts, tls.SecretName)
+	}
+	ctx.setSourceLabels(SourceKindIngress, ing)
 	return ctx, nil
 }
 
@@ -185,6 +234,9 @@ func (t *translator) translateIngressExtensionsV1beta1(ing *extensionsv1beta1.In
 	plugins := t.translateAnnotations(ing.Annotations)
 
 	for _, rule := range ing.Spec.Rules {
+		if rule.HTTP == nil {
+			continue
+		}
 		for _, pathRule := range rule.HTTP.Paths {
 			var (
 				ups *apisixv1.Upstream
@@ -235,6 +287,23 @@ func (t *translator) translateIngressExtensionsV1beta1(ing *extensionsv1beta1.In
 			ctx.addRoute(route)
 		}
 	}
+	if backend := ing.Spec.Backend; backend != nil {
+		ups, err := t.translateUpstreamFromIngressV1beta1(ing.Namespace, backend.ServiceName, backend.ServicePort)
+		if err != nil {
+			log.Errorw("failed to translate ingress default backend to upstream",
+				zap.Error(err),
+				zap.Any("ingress", ing),
+			)
+			return nil, err
+		}
+		ctx.addUpstream(ups)
+		hosts, _ := IngressDefaultBackendScope(kube.MustNewIngress(ing))
+		ctx.addRoute(newIngressDefaultBackendRoute(ing.Namespace, ing.Name, hosts, plugins, ups))
+	}
+	for _, tls := range ing.Spec.TLS {
+		t.translateIngressTLS(ctx, ing.Namespace, ing.Name, tls.Hosts, tls.SecretName)
+	}
+	ctx.setSourceLabels(SourceKindIngress, ing)
 	return ctx, nil
 }
 
@@ -269,6 +338,114 @@ func (t *translator) translateUpstreamFromIngressV1beta1(namespace string, svcNa
 	return ups, nil
 }
 
+// ComposeIngressDefaultBackendRouteName returns the name of the catch-all
+// route translated from the default backend of the Ingress.
+func ComposeIngressDefaultBackendRouteName(namespace, name string) string {
+	return "ingress_default_backend_" + namespace + "_" + name
+}
+
+// IngressDefaultBackendScope returns the sorted hosts of rules in the
+// Ingress, the catch-all route of its default backend is scoped to these
+// hosts, or global if there are none. The ok is false if the Ingress doesn't
+// have a default backend.
+func IngressDefaultBackendScope(ing kube.Ingress) (hosts []string, ok bool) {
+	var ruleHosts []string
+	switch ing.GroupVersion() {
+	case kube.IngressV1:
+		obj := ing.V1()
+		ok = obj.Spec.DefaultBackend != nil && obj.Spec.DefaultBackend.Service != nil
+		for _, rule := range obj.Spec.Rules {
+			ruleHosts = append(ruleHosts, rule.Host)
+		}
+	case kube.IngressV1beta1:
+		obj := ing.V1beta1()
+		ok = obj.Spec.Backend != nil
+		for _, rule := range obj.Spec.Rules {
+			ruleHosts = append(ruleHosts, rule.Host)
+		}
+	default:
+		obj := ing.ExtensionsV1beta1()
+		ok = obj.Spec.Backend != nil
+		for _, rule := range obj.Spec.Rules {
+			ruleHosts = append(ruleHosts, rule.Host)
+		}
+	}
+	seen := make(map[string]struct{}, len(ruleHosts))
+	for _, host := range ruleHosts {
+		if _, dup := seen[host]; dup || host == "" {
+			continue
+		}
+		seen[host] = struct{}{}
+		hosts = append(hosts, host)
+	}
+	sort.Strings(hosts)
+	return hosts, ok
+}
+
+// newIngressDefaultBackendRoute returns the catch-all route of the Ingress
+// default backend, its priority is lower than any other routes so it's
+// matched only if no rules are matched.
+func newIngressDefaultBackendRoute(namespace, name string, hosts []string, plugins apisixv1.Plugins, ups *apisixv1.Upstream) *apisixv1.Route {
+	route := apisixv1.NewDefaultRoute()
+	route.Name = ComposeIngressDefaultBackendRouteName(namespace, name)
+	route.ID = id.GenID(route.Name)
+	route.Uri = "/*"
+	if len(hosts) > 0 {
+		route.Hosts = hosts
+		route.Priority = _defaultBackendHostPriority
+	} else {
+		route.Priority = _defaultBackendGlobalPriority
+	}
+	if len(plugins) > 0 {
+		route.Plugins = *(plugins.DeepCopy())
+	}
+	route.UpstreamId = ups.ID
+	return route
+}
+
+// translateIngressTLS translates a TLS entry of the Ingress to an SSL
+// object, entries which reference the same Secret share one SSL object.
+// Entries with a missing or invalid Secret are dropped, so that they don't
+// block the routes of the Ingress.
+func (t *translator) translateIngressTLS(ctx *TranslateContext, namespace, name string, hosts []string, secretName string) {
+	if len(hosts) == 0 {
+		log.Warnw("ingress tls entry without hosts is ignored",
+			zap.String("ingress", namespace+"/"+name),
+			zap.String("secret", secretName),
+		)
+		return
+	}
+	sec, err := t.SecretLister.Secrets(namespace).Get(secretName)
+	if err != nil {
+		log.Warnw("failed to get secret of ingress tls entry, the entry is ignored",
+			zap.String("ingress", namespace+"/"+name),
+			zap.String("secret", secretName),
+			zap.Error(err),
+		)
+		return
+	}
+	cert, key, err := extractKeyPair(sec)
+	if err != nil {
+		log.Warnw("invalid secret of ingress tls entry, the entry is ignored",
+			zap.String("ingress", namespace+"/"+name),
+			zap.String("secret", secretName),
+			zap.Error(err),
+		)
+		return
+	}
+	ssl := &apisixv1.Ssl{
+		ID:     id.GenID(namespace + "_" + name + "_" + secretName),
+		Snis:   append([]string(nil), hosts...),
+		Cert:   string(cert),
+		Key:    string(key),
+		Status: 1,
+		Labels: map[string]string{
+			apisixv1.LabelManagedBy: apisixv1.LabelManagedByValue,
+		},
+	}
+	ctx.addSSL(ssl)
+}
+
 func composeIngressRouteName(host, path string) string {
 	p := make([]byte, 0, len(host)+len(path)+len("ingress")+2)
 	buf := bytes.NewBuffer(p)
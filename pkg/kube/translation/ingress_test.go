@@ -29,6 +29,7 @@ import (
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes/fake"
+	listerscorev1 "k8s.io/client-go/listers/core/v1"
 	"k8s.io/client-go/tools/cache"
 
 	fakeapisix "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/client/clientset/versioned/fake"
@@ -311,6 +312,33 @@ func TestTranslateIngressV1(t *testing.T) {
 	assert.Equal(t, ctx.Upstreams[1].Nodes[0].Host, "192.168.1.1")
 	assert.Equal(t, ctx.Upstreams[1].Nodes[1].Port, 9443)
 	assert.Equal(t, ctx.Upstreams[1].Nodes[1].Host, "192.168.1.2")
+
+	// The default backend shares the upstream with the second path.
+	ing.Spec.DefaultBackend = &networkingv1.IngressBackend{
+		Service: &networkingv1.IngressServiceBackend{
+			Name: "test-service",
+			Port: networkingv1.ServiceBackendPort{
+				Number: 443,
+			},
+		},
+	}
+	ctx, err = tr.translateIngressV1(ing)
+	assert.Nil(t, err)
+	assert.Len(t, ctx.Routes, 3)
+	assert.Len(t, ctx.Upstreams, 2)
+	assert.Equal(t, "ingress_default_backend_default_test", ctx.Routes[2].Name)
+	assert.Equal(t, "/*", ctx.Routes[2].Uri)
+	assert.Equal(t, []string{"apisix.apache.org"}, ctx.Routes[2].Hosts)
+	assert.Equal(t, -1, ctx.Routes[2].Priority)
+	assert.Equal(t, ctx.Upstreams[1].ID, ctx.Routes[2].UpstreamId)
+
+	// The catch-all route is global if there are no hosts.
+	ing.Spec.Rules = nil
+	ctx, err = tr.translateIngressV1(ing)
+	assert.Nil(t, err)
+	assert.Len(t, ctx.Routes, 1)
+	assert.Nil(t, ctx.Routes[0].Hosts)
+	assert.Equal(t, -2, ctx.Routes[0].Priority)
 }
 
 func TestTranslateIngressV1beta1NoBackend(t *testing.T) {
@@ -717,3 +745,63 @@ func TestTranslateIngressExtensionsV1beta1BackendWithInvalidService(t *testing.T
 		reason: "port not found",
 	})
 }
+
+func TestTranslateIngressV1TLS(t *testing.T) {
+	cert, key := genKeyPair(t, "apisix.apache.org")
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	err := indexer.Add(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-cert",
+			Namespace: "default",
+		},
+		Type: corev1.SecretTypeTLS,
+		Data: map[string][]byte{
+			corev1.TLSCertKey:       cert,
+			corev1.TLSPrivateKeyKey: key,
+		},
+	})
+	assert.Nil(t, err)
+	tr := &translator{
+		TranslatorOptions: &TranslatorOptions{
+			SecretLister: listerscorev1.NewSecretLister(indexer),
+		},
+	}
+
+	ing := &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "default",
+		},
+		Spec: networkingv1.IngressSpec{
+			TLS: []networkingv1.IngressTLS{
+				{
+					Hosts:      []string{"apisix.apache.org"},
+					SecretName: "test-cert",
+				},
+				{
+					Hosts:      []string{"apisix.apache.org", "www.apisix.apache.org"},
+					SecretName: "test-cert",
+				},
+				{
+					// Entries without hosts are ignored.
+					SecretName: "test-cert",
+				},
+			},
+		},
+	}
+	ctx, err := tr.translateIngressV1(ing)
+	assert.Nil(t, err)
+	assert.Len(t, ctx.SSLs, 1)
+	assert.Equal(t, []string{"apisix.apache.org", "www.apisix.apache.org"}, ctx.SSLs[0].Snis)
+	assert.Equal(t, string(cert), ctx.SSLs[0].Cert)
+	assert.Equal(t, string(key), ctx.SSLs[0].Key)
+	assert.Equal(t, "Ingress", ctx.SSLs[0].Labels["source-kind"])
+
+	// Only the entry with a missing Secret is dropped.
+	ing.Spec.TLS[0].SecretName = "missing"
+	ing.Spec.TLS[0].Hosts = []string{"missing.apisix.apache.org"}
+	ctx, err = tr.translateIngressV1(ing)
+	assert.Nil(t, err)
+	assert.Len(t, ctx.SSLs, 1)
+	assert.Equal(t, []string{"apisix.apache.org", "www.apisix.apache.org"}, ctx.SSLs[0].Snis)
+}
@@ -37,12 +37,36 @@ const (
 type translateError struct {
 	field  string
 	reason string
+	err    error
 }
 
 func (te *translateError) Error() string {
 	return fmt.Sprintf("%s: %s", te.field, te.reason)
 }
 
+// Unwrap returns the error wrapped by fieldError.
+func (te *translateError) Unwrap() error {
+	return te.err
+}
+
+// fieldError wraps the error with the path of the field which causes it.
+func fieldError(field string, err error) error {
+	return &translateError{
+		field:  field,
+		reason: err.Error(),
+		err:    err,
+	}
+}
+
+// FieldPath returns the path of the invalid field if the error is caused
+// by it, e.g. spec.http[0].match.exprs, or an empty string.
+func FieldPath(err error) string {
+	if te, ok := err.(*translateError); ok {
+		return te.field
+	}
+	return ""
+}
+
 // Translator translates Apisix* CRD resources to the description in APISIX.
 type Translator interface {
 	// TranslateUpstreamNodes translate Endpoints resources to APISIX Upstream nodes
@@ -100,6 +124,9 @@ type TranslatorOptions struct {
 	ApisixUpstreamLister listersv1.ApisixUpstreamLister
 	SecretLister         listerscorev1.SecretLister
 	UseEndpointSlices    bool
+	// ApisixUpstreamFilter reports whether the ApisixUpstream should decorate
+	// upstreams, nil means all ApisixUpstreams are used.
+	ApisixUpstreamFilter func(*configv1.ApisixUpstream) bool
 }
 
 type translator struct {
@@ -147,6 +174,9 @@ func (t *translator) TranslateUpstream(namespace, name, subset string, port int3
 		}
 	}
 	au, err := t.ApisixUpstreamLister.ApisixUpstreams(namespace).Get(name)
+	if err == nil && t.ApisixUpstreamFilter != nil && !t.ApisixUpstreamFilter(au) {
+		au, err = nil, k8serrors.NewNotFound(configv1.Resource("apisixupstreams"), name)
+	}
 	ups := apisixv1.NewDefaultUpstream()
 	if err != nil {
 		if k8serrors.IsNotFound(err) {
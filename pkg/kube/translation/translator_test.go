@@ -16,6 +16,7 @@ package translation
 
 import (
 	"context"
+	"errors"
 	"testing"
 
 	"github.com/apache/apisix-ingress-controller/pkg/kube"
@@ -344,3 +345,11 @@ func TestTranslateUpstreamNodesWithEndpointSlices(t *testing.T) {
 		},
 	})
 }
+
+func TestFieldError(t *testing.T) {
+	cause := errors.New("service not found")
+	err := fieldError("spec.http[0].backends", cause)
+	assert.Equal(t, "spec.http[0].backends: service not found", err.Error())
+	assert.Equal(t, "spec.http[0].backends", FieldPath(err))
+	assert.True(t, errors.Is(err, cause))
+}
@@ -114,3 +114,17 @@ func TestLogLevel(t *testing.T) {
 	p := fws.bytes()
 	assert.Len(t, p, 0, "saw a message which should be dropped")
 }
+
+func TestSetLogLevel(t *testing.T) {
+	fws := &fakeWriteSyncer{}
+	logger, err := NewLogger(WithLogLevel("error"), WithWriteSyncer(fws))
+	assert.Nil(t, err, "failed to new logger: ", err)
+	defer logger.Close()
+
+	assert.NotNil(t, logger.SetLevel("verbose"))
+
+	assert.Nil(t, logger.SetLevel("warn"))
+	logger.Warn("this message should be kept")
+	assert.Nil(t, logger.Sync(), "failed to sync logger")
+	assert.NotEqual(t, len(fws.bytes()), 0, "message was dropped")
+}
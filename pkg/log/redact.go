@@ -0,0 +1,90 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package log
+
+import (
+	"encoding/json"
+	"regexp"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Redacted replaces sensitive values in logs.
+const Redacted = "******"
+
+var (
+	_privateKeyPattern = regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----[\s\S]*?-----END [A-Z ]*PRIVATE KEY-----`)
+	// _sensitiveMemberPattern matches JSON members whose names end with
+	// "key", "password" or "token", like admin keys, key-auth and
+	// basic-auth credentials and SSL private keys.
+	_sensitiveMemberPattern = regexp.MustCompile(`("[^"]*(?i:key|password|token)"\s*:\s*)"(?:[^"\\]|\\.)*"`)
+)
+
+// Redact masks the sensitive values in the text, which are PEM encoded
+// private keys, and string members with sensitive names in JSON.
+func Redact(s string) string {
+	s = _privateKeyPattern.ReplaceAllString(s, Redacted)
+	return _sensitiveMemberPattern.ReplaceAllString(s, `${1}"`+Redacted+`"`)
+}
+
+// redactFields masks the sensitive values in fields, fields are copied
+// if any of them is changed. Objects which implement the
+// zapcore.ObjectMarshaler interface are responsible for their own
+// redaction.
+func redactFields(fields []zapcore.Field) []zapcore.Field {
+	var redacted []zapcore.Field
+	for i, f := range fields {
+		rf, ok := redactField(f)
+		if !ok {
+			continue
+		}
+		if redacted == nil {
+			redacted = make([]zapcore.Field, len(fields))
+			copy(redacted, fields)
+		}
+		redacted[i] = rf
+	}
+	if redacted == nil {
+		return fields
+	}
+	return redacted
+}
+
+func redactField(f zapcore.Field) (zapcore.Field, bool) {
+	switch f.Type {
+	case zapcore.StringType:
+		if s := Redact(f.String); s != f.String {
+			return zap.String(f.Key, s), true
+		}
+	case zapcore.ByteStringType:
+		if b := string(f.Interface.([]byte)); Redact(b) != b {
+			return zap.ByteString(f.Key, []byte(Redact(b))), true
+		}
+	case zapcore.ErrorType:
+		if msg := f.Interface.(error).Error(); Redact(msg) != msg {
+			return zap.String(f.Key, Redact(msg)), true
+		}
+	case zapcore.ReflectType:
+		data, err := json.Marshal(f.Interface)
+		if err != nil {
+			return f, false
+		}
+		if s := Redact(string(data)); s != string(data) {
+			return zap.Reflect(f.Key, json.RawMessage(s)), true
+		}
+	}
+	return f, false
+}
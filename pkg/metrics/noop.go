@@ -0,0 +1,34 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package metrics
+
+import (
+	"time"
+)
+
+type noopCollector struct{}
+
+// NewNoopCollector creates a Collector which drops all metrics, it's
+// useful when metrics are not needed, e.g. in command line tools.
+func NewNoopCollector() Collector {
+	return noopCollector{}
+}
+
+func (noopCollector) ResetLeader(bool)                                          {}
+func (noopCollector) RecordAPISIXCode(int, string, string, string)              {}
+func (noopCollector) RecordAPISIXLatency(time.Duration, string, string, string) {}
+func (noopCollector) IncrAPISIXRequest(string, string, string)                  {}
+func (noopCollector) IncrDriftRepair(string, bool)                              {}
+func (noopCollector) IncrSyncOperation(string, string)                          {}
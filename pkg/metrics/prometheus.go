@@ -23,28 +23,50 @@ import (
 
 const (
 	_namespace = "apisix_ingress_controller"
+
+	// SyncResultSuccess means a resource was synced successfully.
+	SyncResultSuccess = "success"
+	// SyncResultTranslationFailure means a resource failed to be translated.
+	SyncResultTranslationFailure = "translation_failure"
+	// SyncResultPushFailure means the translated resources failed to be
+	// pushed to APISIX.
+	SyncResultPushFailure = "push_failure"
 )
 
 // Collector defines all metrics for ingress apisix.
 type Collector interface {
 	// ResetLeader changes the role of ingress apisix instance (leader, follower).
 	ResetLeader(bool)
-	// RecordAPISIXCode records a status code returned by APISIX with the resource
-	// type label.
-	RecordAPISIXCode(int, string)
+	// RecordAPISIXCode records a status code returned by APISIX with the cluster
+	// name, resource type and HTTP method labels.
+	RecordAPISIXCode(int, string, string, string)
 	// RecordAPISIXLatency records the latency for a round trip from ingress apisix
-	// to apisix.
-	RecordAPISIXLatency(time.Duration)
-	// IncrAPISIXRequest increases the number of requests to apisix.
-	IncrAPISIXRequest(string)
+	// to apisix with the cluster name, resource type and HTTP method labels.
+	RecordAPISIXLatency(time.Duration, string, string, string)
+	// IncrAPISIXRequest increases the number of requests to apisix with the
+	// cluster name, resource type and HTTP method labels.
+	IncrAPISIXRequest(string, string, string)
+	// IncrDriftRepair increases the number of drifts repaired by the reconciler
+	// with the resource type label, the bool indicates whether the repair succeeded.
+	IncrDriftRepair(string, bool)
+	// IncrSyncOperation increases the number of sync operations with the
+	// resource kind and result (one of SyncResult*) labels.
+	IncrSyncOperation(string, string)
 }
 
 // collector contains necessary messages to collect Prometheus metrics.
 type collector struct {
 	isLeader       prometheus.Gauge
-	apisixLatency  prometheus.Summary
+	apisixLatency  *prometheus.HistogramVec
 	apisixRequests *prometheus.CounterVec
-	apisixCodes    *prometheus.GaugeVec
+	apisixCodes    *prometheus.CounterVec
+	driftRepairs   *prometheus.CounterVec
+	syncOperations *prometheus.CounterVec
+
+	// Deprecated: superseded by apisixCodes and apisixLatency, kept until
+	// dashboards and alerts are migrated.
+	apisixBadCodes  *prometheus.GaugeVec
+	apisixLatencies prometheus.Summary
 }
 
 // NewPrometheusCollectors creates the Prometheus metrics collector.
@@ -65,22 +87,24 @@ func NewPrometheusCollector(podName, podNamespace string) Collector {
 				ConstLabels: constLabels,
 			},
 		),
-		apisixCodes: prometheus.NewGaugeVec(
-			prometheus.GaugeOpts{
-				Name:        "apisix_bad_status_codes",
+		apisixCodes: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name:        "apisix_status_codes",
 				Namespace:   _namespace,
-				Help:        "Whether the role of controller instance is leader",
+				Help:        "Number of status codes returned by APISIX",
 				ConstLabels: constLabels,
 			},
-			[]string{"resource", "status_code"},
+			[]string{"cluster", "resource", "method", "status_code"},
 		),
-		apisixLatency: prometheus.NewSummary(
-			prometheus.SummaryOpts{
+		apisixLatency: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
 				Namespace:   _namespace,
-				Name:        "apisix_request_latencies",
-				Help:        "Request latencies with APISIX",
+				Name:        "apisix_request_duration_seconds",
+				Help:        "Request latencies with APISIX in seconds",
 				ConstLabels: constLabels,
+				Buckets:     prometheus.DefBuckets,
 			},
+			[]string{"cluster", "resource", "method"},
 		),
 		apisixRequests: prometheus.NewCounterVec(
 			prometheus.CounterOpts{
@@ -89,7 +113,42 @@ func NewPrometheusCollector(podName, podNamespace string) Collector {
 				Help:        "Number of requests to APISIX",
 				ConstLabels: constLabels,
 			},
-			[]string{"resource"},
+			[]string{"cluster", "resource", "method"},
+		),
+		driftRepairs: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace:   _namespace,
+				Name:        "drift_repairs",
+				Help:        "Number of drifts in APISIX repaired by the reconciler",
+				ConstLabels: constLabels,
+			},
+			[]string{"resource", "result"},
+		),
+		syncOperations: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace:   _namespace,
+				Name:        "sync_operations",
+				Help:        "Number of sync operations of resources",
+				ConstLabels: constLabels,
+			},
+			[]string{"kind", "result"},
+		),
+		apisixBadCodes: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name:        "apisix_bad_status_codes",
+				Namespace:   _namespace,
+				Help:        "Number of status codes returned by APISIX (deprecated, use apisix_status_codes)",
+				ConstLabels: constLabels,
+			},
+			[]string{"resource", "status_code"},
+		),
+		apisixLatencies: prometheus.NewSummary(
+			prometheus.SummaryOpts{
+				Namespace:   _namespace,
+				Name:        "apisix_request_latencies",
+				Help:        "Request latencies with APISIX in nanoseconds (deprecated, use apisix_request_duration_seconds)",
+				ConstLabels: constLabels,
+			},
 		),
 	}
 
@@ -99,13 +158,23 @@ func NewPrometheusCollector(podName, podNamespace string) Collector {
 	prometheus.Unregister(collector.apisixCodes)
 	prometheus.Unregister(collector.apisixLatency)
 	prometheus.Unregister(collector.apisixRequests)
+	prometheus.Unregister(collector.driftRepairs)
+	prometheus.Unregister(collector.syncOperations)
+	prometheus.Unregister(collector.apisixBadCodes)
+	prometheus.Unregister(collector.apisixLatencies)
 
 	prometheus.MustRegister(
 		collector.isLeader,
 		collector.apisixCodes,
 		collector.apisixLatency,
 		collector.apisixRequests,
+		collector.driftRepairs,
+		collector.syncOperations,
+		collector.apisixBadCodes,
+		collector.apisixLatencies,
 	)
+	// Workqueues report their metrics through the global provider.
+	registerWorkqueueMetrics(constLabels)
 
 	return collector
 }
@@ -121,8 +190,14 @@ func (c *collector) ResetLeader(leader bool) {
 
 // RecordAPISIXCode records the status code (returned by APISIX)
 // for the specific resource (e.g. Route, Upstream and etc).
-func (c *collector) RecordAPISIXCode(code int, resource string) {
+func (c *collector) RecordAPISIXCode(code int, cluster, resource, method string) {
 	c.apisixCodes.With(prometheus.Labels{
+		"cluster":     cluster,
+		"resource":    resource,
+		"method":      method,
+		"status_code": strconv.Itoa(code),
+	}).Inc()
+	c.apisixBadCodes.With(prometheus.Labels{
 		"resource":    resource,
 		"status_code": strconv.Itoa(code),
 	}).Inc()
@@ -130,14 +205,45 @@ func (c *collector) RecordAPISIXCode(code int, resource string) {
 
 // RecordAPISIXLatency records the latency for a complete round trip
 // from controller to APISIX.
-func (c *collector) RecordAPISIXLatency(latency time.Duration) {
-	c.apisixLatency.Observe(float64(latency.Nanoseconds()))
+func (c *collector) RecordAPISIXLatency(latency time.Duration, cluster, resource, method string) {
+	c.apisixLatency.With(prometheus.Labels{
+		"cluster":  cluster,
+		"resource": resource,
+		"method":   method,
+	}).Observe(latency.Seconds())
+	c.apisixLatencies.Observe(float64(latency.Nanoseconds()))
 }
 
 // IncrAPISIXRequest increases the number of requests for specific
 // resource to APISIX.
-func (c *collector) IncrAPISIXRequest(resource string) {
-	c.apisixRequests.WithLabelValues(resource).Inc()
+func (c *collector) IncrAPISIXRequest(cluster, resource, method string) {
+	c.apisixRequests.With(prometheus.Labels{
+		"cluster":  cluster,
+		"resource": resource,
+		"method":   method,
+	}).Inc()
+}
+
+// IncrDriftRepair increases the number of drifts repaired by the reconciler
+// for specific resource.
+func (c *collector) IncrDriftRepair(resource string, success bool) {
+	result := "success"
+	if !success {
+		result = "failure"
+	}
+	c.driftRepairs.With(prometheus.Labels{
+		"resource": resource,
+		"result":   result,
+	}).Inc()
+}
+
+// IncrSyncOperation increases the number of sync operations for specific
+// resource kind.
+func (c *collector) IncrSyncOperation(kind, result string) {
+	c.syncOperations.With(prometheus.Labels{
+		"kind":   kind,
+		"result": result,
+	}).Inc()
 }
 
 // Collect collects the prometheus.Collect.
@@ -145,8 +251,11 @@ func (c *collector) Collect(ch chan<- prometheus.Metric) {
 	c.isLeader.Collect(ch)
 	c.apisixLatency.Collect(ch)
 	c.apisixRequests.Collect(ch)
-	c.apisixLatency.Collect(ch)
 	c.apisixCodes.Collect(ch)
+	c.driftRepairs.Collect(ch)
+	c.syncOperations.Collect(ch)
+	c.apisixBadCodes.Collect(ch)
+	c.apisixLatencies.Collect(ch)
 }
 
 // Describe describes the prometheus.Describe.
@@ -154,6 +263,9 @@ func (c *collector) Describe(ch chan<- *prometheus.Desc) {
 	c.isLeader.Describe(ch)
 	c.apisixLatency.Describe(ch)
 	c.apisixRequests.Describe(ch)
-	c.apisixLatency.Describe(ch)
 	c.apisixCodes.Describe(ch)
+	c.driftRepairs.Describe(ch)
+	c.syncOperations.Describe(ch)
+	c.apisixBadCodes.Describe(ch)
+	c.apisixLatencies.Describe(ch)
 }
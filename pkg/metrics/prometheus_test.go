@@ -21,8 +21,40 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 	io_prometheus_client "github.com/prometheus/client_model/go"
 	"github.com/stretchr/testify/assert"
+	"k8s.io/client-go/util/workqueue"
 )
 
+func apisixStatusCodesTestHandler(t *testing.T, metrics []*io_prometheus_client.MetricFamily) func(*testing.T) {
+	return func(t *testing.T) {
+		metric := findMetric("apisix_ingress_controller_apisix_status_codes", metrics)
+		assert.NotNil(t, metric)
+		assert.Equal(t, metric.Type.String(), "COUNTER")
+		m := metric.GetMetric()
+		assert.Len(t, m, 2)
+		assert.Equal(t, *m[0].Counter.Value, float64(1))
+		assert.Equal(t, *m[0].Label[0].Name, "cluster")
+		assert.Equal(t, *m[0].Label[0].Value, "default")
+		assert.Equal(t, *m[0].Label[1].Name, "controller_namespace")
+		assert.Equal(t, *m[0].Label[1].Value, "default")
+		assert.Equal(t, *m[0].Label[2].Name, "controller_pod")
+		assert.Equal(t, *m[0].Label[2].Value, "test")
+		assert.Equal(t, *m[0].Label[3].Name, "method")
+		assert.Equal(t, *m[0].Label[3].Value, "GET")
+		assert.Equal(t, *m[0].Label[4].Name, "resource")
+		assert.Equal(t, *m[0].Label[4].Value, "route")
+		assert.Equal(t, *m[0].Label[5].Name, "status_code")
+		assert.Equal(t, *m[0].Label[5].Value, "404")
+
+		assert.Equal(t, *m[1].Counter.Value, float64(1))
+		assert.Equal(t, *m[1].Label[3].Name, "method")
+		assert.Equal(t, *m[1].Label[3].Value, "PUT")
+		assert.Equal(t, *m[1].Label[4].Name, "resource")
+		assert.Equal(t, *m[1].Label[4].Value, "upstream")
+		assert.Equal(t, *m[1].Label[5].Name, "status_code")
+		assert.Equal(t, *m[1].Label[5].Value, "500")
+	}
+}
+
 func apisixBadStatusCodesTestHandler(t *testing.T, metrics []*io_prometheus_client.MetricFamily) func(*testing.T) {
 	return func(t *testing.T) {
 		metric := findMetric("apisix_ingress_controller_apisix_bad_status_codes", metrics)
@@ -41,10 +73,6 @@ func apisixBadStatusCodesTestHandler(t *testing.T, metrics []*io_prometheus_clie
 		assert.Equal(t, *m[0].Label[3].Value, "404")
 
 		assert.Equal(t, *m[1].Gauge.Value, float64(1))
-		assert.Equal(t, *m[1].Label[0].Name, "controller_namespace")
-		assert.Equal(t, *m[1].Label[0].Value, "default")
-		assert.Equal(t, *m[1].Label[1].Name, "controller_pod")
-		assert.Equal(t, *m[1].Label[1].Value, "test")
 		assert.Equal(t, *m[1].Label[2].Name, "resource")
 		assert.Equal(t, *m[1].Label[2].Value, "upstream")
 		assert.Equal(t, *m[1].Label[3].Name, "status_code")
@@ -69,6 +97,29 @@ func isLeaderTestHandler(t *testing.T, metrics []*io_prometheus_client.MetricFam
 }
 
 func apisixLatencyTestHandler(t *testing.T, metrics []*io_prometheus_client.MetricFamily) func(t *testing.T) {
+	return func(t *testing.T) {
+		metric := findMetric("apisix_ingress_controller_apisix_request_duration_seconds", metrics)
+		assert.NotNil(t, metric)
+		assert.Equal(t, metric.Type.String(), "HISTOGRAM")
+		m := metric.GetMetric()
+		assert.Len(t, m, 1)
+
+		assert.Equal(t, *m[0].Histogram.SampleCount, uint64(1))
+		assert.Equal(t, *m[0].Histogram.SampleSum, 0.5)
+		assert.Equal(t, *m[0].Label[0].Name, "cluster")
+		assert.Equal(t, *m[0].Label[0].Value, "default")
+		assert.Equal(t, *m[0].Label[1].Name, "controller_namespace")
+		assert.Equal(t, *m[0].Label[1].Value, "default")
+		assert.Equal(t, *m[0].Label[2].Name, "controller_pod")
+		assert.Equal(t, *m[0].Label[2].Value, "test")
+		assert.Equal(t, *m[0].Label[3].Name, "method")
+		assert.Equal(t, *m[0].Label[3].Value, "GET")
+		assert.Equal(t, *m[0].Label[4].Name, "resource")
+		assert.Equal(t, *m[0].Label[4].Value, "route")
+	}
+}
+
+func apisixLatenciesTestHandler(t *testing.T, metrics []*io_prometheus_client.MetricFamily) func(t *testing.T) {
 	return func(t *testing.T) {
 		metric := findMetric("apisix_ingress_controller_apisix_request_latencies", metrics)
 		assert.NotNil(t, metric)
@@ -94,40 +145,129 @@ func apisixRequestTestHandler(t *testing.T, metrics []*io_prometheus_client.Metr
 		assert.Len(t, m, 2)
 
 		assert.Equal(t, *m[0].Counter.Value, float64(2))
-		assert.Equal(t, *m[0].Label[0].Name, "controller_namespace")
+		assert.Equal(t, *m[0].Label[0].Name, "cluster")
 		assert.Equal(t, *m[0].Label[0].Value, "default")
-		assert.Equal(t, *m[0].Label[1].Name, "controller_pod")
-		assert.Equal(t, *m[0].Label[1].Value, "test")
+		assert.Equal(t, *m[0].Label[1].Name, "controller_namespace")
+		assert.Equal(t, *m[0].Label[1].Value, "default")
+		assert.Equal(t, *m[0].Label[2].Name, "controller_pod")
+		assert.Equal(t, *m[0].Label[2].Value, "test")
+		assert.Equal(t, *m[0].Label[3].Name, "method")
+		assert.Equal(t, *m[0].Label[3].Value, "GET")
+		assert.Equal(t, *m[0].Label[4].Name, "resource")
+		assert.Equal(t, *m[0].Label[4].Value, "route")
+
+		assert.Equal(t, *m[1].Counter.Value, float64(1))
+		assert.Equal(t, *m[1].Label[3].Name, "method")
+		assert.Equal(t, *m[1].Label[3].Value, "PUT")
+		assert.Equal(t, *m[1].Label[4].Name, "resource")
+		assert.Equal(t, *m[1].Label[4].Value, "upstream")
+	}
+}
+
+func driftRepairsTestHandler(t *testing.T, metrics []*io_prometheus_client.MetricFamily) func(t *testing.T) {
+	return func(t *testing.T) {
+		metric := findMetric("apisix_ingress_controller_drift_repairs", metrics)
+		assert.NotNil(t, metric)
+		assert.Equal(t, metric.Type.String(), "COUNTER")
+		m := metric.GetMetric()
+		assert.Len(t, m, 2)
+
+		assert.Equal(t, *m[0].Counter.Value, float64(1))
 		assert.Equal(t, *m[0].Label[2].Name, "resource")
 		assert.Equal(t, *m[0].Label[2].Value, "route")
+		assert.Equal(t, *m[0].Label[3].Name, "result")
+		assert.Equal(t, *m[0].Label[3].Value, "failure")
 
-		assert.Equal(t, *m[1].Counter.Value, float64(1))
-		assert.Equal(t, *m[1].Label[0].Name, "controller_namespace")
-		assert.Equal(t, *m[1].Label[0].Value, "default")
-		assert.Equal(t, *m[1].Label[1].Name, "controller_pod")
-		assert.Equal(t, *m[1].Label[1].Value, "test")
+		assert.Equal(t, *m[1].Counter.Value, float64(2))
 		assert.Equal(t, *m[1].Label[2].Name, "resource")
-		assert.Equal(t, *m[1].Label[2].Value, "upstream")
+		assert.Equal(t, *m[1].Label[2].Value, "route")
+		assert.Equal(t, *m[1].Label[3].Name, "result")
+		assert.Equal(t, *m[1].Label[3].Value, "success")
+	}
+}
+
+func syncOperationsTestHandler(t *testing.T, metrics []*io_prometheus_client.MetricFamily) func(t *testing.T) {
+	return func(t *testing.T) {
+		metric := findMetric("apisix_ingress_controller_sync_operations", metrics)
+		assert.NotNil(t, metric)
+		assert.Equal(t, metric.Type.String(), "COUNTER")
+		m := metric.GetMetric()
+		assert.Len(t, m, 2)
+
+		assert.Equal(t, *m[0].Counter.Value, float64(1))
+		assert.Equal(t, *m[0].Label[2].Name, "kind")
+		assert.Equal(t, *m[0].Label[2].Value, "ApisixRoute")
+		assert.Equal(t, *m[0].Label[3].Name, "result")
+		assert.Equal(t, *m[0].Label[3].Value, "push_failure")
+
+		assert.Equal(t, *m[1].Counter.Value, float64(1))
+		assert.Equal(t, *m[1].Label[2].Name, "kind")
+		assert.Equal(t, *m[1].Label[2].Value, "Ingress")
+		assert.Equal(t, *m[1].Label[3].Name, "result")
+		assert.Equal(t, *m[1].Label[3].Value, "translation_failure")
+	}
+}
+
+func workqueueTestHandler(t *testing.T, metrics []*io_prometheus_client.MetricFamily) func(t *testing.T) {
+	return func(t *testing.T) {
+		metric := findMetric("apisix_ingress_controller_workqueue_adds", metrics)
+		assert.NotNil(t, metric)
+		assert.Equal(t, metric.Type.String(), "COUNTER")
+		m := metric.GetMetric()
+		assert.Len(t, m, 1)
+		assert.Equal(t, *m[0].Counter.Value, float64(2))
+		assert.Equal(t, *m[0].Label[2].Name, "name")
+		assert.Equal(t, *m[0].Label[2].Value, "test")
+
+		metric = findMetric("apisix_ingress_controller_workqueue_depth", metrics)
+		assert.NotNil(t, metric)
+		m = metric.GetMetric()
+		assert.Len(t, m, 1)
+		assert.Equal(t, *m[0].Gauge.Value, float64(1))
+
+		metric = findMetric("apisix_ingress_controller_workqueue_retries", metrics)
+		assert.NotNil(t, metric)
+		m = metric.GetMetric()
+		assert.Len(t, m, 1)
+		assert.Equal(t, *m[0].Counter.Value, float64(1))
 	}
 }
 
 func TestPrometheusCollector(t *testing.T) {
 	c := NewPrometheusCollector("test", "default")
 	c.ResetLeader(true)
-	c.RecordAPISIXCode(404, "route")
-	c.RecordAPISIXCode(500, "upstream")
-	c.RecordAPISIXLatency(500 * time.Millisecond)
-	c.IncrAPISIXRequest("route")
-	c.IncrAPISIXRequest("route")
-	c.IncrAPISIXRequest("upstream")
+	c.RecordAPISIXCode(404, "default", "route", "GET")
+	c.RecordAPISIXCode(500, "default", "upstream", "PUT")
+	c.RecordAPISIXLatency(500*time.Millisecond, "default", "route", "GET")
+	c.IncrAPISIXRequest("default", "route", "GET")
+	c.IncrAPISIXRequest("default", "route", "GET")
+	c.IncrAPISIXRequest("default", "upstream", "PUT")
+	c.IncrDriftRepair("route", true)
+	c.IncrDriftRepair("route", true)
+	c.IncrDriftRepair("route", false)
+	c.IncrSyncOperation("Ingress", SyncResultTranslationFailure)
+	c.IncrSyncOperation("ApisixRoute", SyncResultPushFailure)
+
+	q := workqueue.NewNamedRateLimitingQueue(workqueue.NewItemExponentialFailureRateLimiter(time.Hour, time.Hour), "test")
+	defer q.ShutDown()
+	q.Add("a")
+	q.Add("b")
+	q.AddRateLimited("c")
+	item, _ := q.Get()
+	q.Done(item)
 
 	metrics, err := prometheus.DefaultGatherer.Gather()
 	assert.Nil(t, err)
 
+	t.Run("apisix_status_codes", apisixStatusCodesTestHandler(t, metrics))
 	t.Run("apisix_bad_status_codes", apisixBadStatusCodesTestHandler(t, metrics))
 	t.Run("is_leader", isLeaderTestHandler(t, metrics))
-	t.Run("apisix_request_latencies", apisixLatencyTestHandler(t, metrics))
+	t.Run("apisix_request_duration_seconds", apisixLatencyTestHandler(t, metrics))
+	t.Run("apisix_request_latencies", apisixLatenciesTestHandler(t, metrics))
 	t.Run("apisix_requests", apisixRequestTestHandler(t, metrics))
+	t.Run("drift_repairs", driftRepairsTestHandler(t, metrics))
+	t.Run("sync_operations", syncOperationsTestHandler(t, metrics))
+	t.Run("workqueue", workqueueTestHandler(t, metrics))
 }
 
 func findMetric(name string, metrics []*io_prometheus_client.MetricFamily) *io_prometheus_client.MetricFamily {
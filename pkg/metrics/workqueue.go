@@ -0,0 +1,152 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package metrics
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/client-go/util/workqueue"
+)
+
+var _workqueueMetricsOnce sync.Once
+
+// workqueueMetricsProvider implements the workqueue.MetricsProvider
+// interface, metrics of all workqueues are distinguished by the name label.
+type workqueueMetricsProvider struct {
+	depth          *prometheus.GaugeVec
+	adds           *prometheus.CounterVec
+	latency        *prometheus.HistogramVec
+	workDuration   *prometheus.HistogramVec
+	unfinished     *prometheus.GaugeVec
+	longestRunning *prometheus.GaugeVec
+	retries        *prometheus.CounterVec
+}
+
+// registerWorkqueueMetrics registers the workqueue metrics provider, the
+// provider can only be set once, so subsequent calls are no-op.
+func registerWorkqueueMetrics(constLabels prometheus.Labels) {
+	_workqueueMetricsOnce.Do(func() {
+		p := newWorkqueueMetricsProvider(constLabels)
+		prometheus.MustRegister(
+			p.depth,
+			p.adds,
+			p.latency,
+			p.workDuration,
+			p.unfinished,
+			p.longestRunning,
+			p.retries,
+		)
+		workqueue.SetProvider(p)
+	})
+}
+
+func newWorkqueueMetricsProvider(constLabels prometheus.Labels) *workqueueMetricsProvider {
+	return &workqueueMetricsProvider{
+		depth: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace:   _namespace,
+				Name:        "workqueue_depth",
+				Help:        "Current depth of workqueue",
+				ConstLabels: constLabels,
+			},
+			[]string{"name"},
+		),
+		adds: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace:   _namespace,
+				Name:        "workqueue_adds",
+				Help:        "Number of adds handled by workqueue",
+				ConstLabels: constLabels,
+			},
+			[]string{"name"},
+		),
+		latency: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace:   _namespace,
+				Name:        "workqueue_queue_duration_seconds",
+				Help:        "How long in seconds an item stays in workqueue before being requested",
+				ConstLabels: constLabels,
+				Buckets:     prometheus.ExponentialBuckets(10e-9, 10, 10),
+			},
+			[]string{"name"},
+		),
+		workDuration: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace:   _namespace,
+				Name:        "workqueue_work_duration_seconds",
+				Help:        "How long in seconds processing an item from workqueue takes",
+				ConstLabels: constLabels,
+				Buckets:     prometheus.ExponentialBuckets(10e-9, 10, 10),
+			},
+			[]string{"name"},
+		),
+		unfinished: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace:   _namespace,
+				Name:        "workqueue_unfinished_work_seconds",
+				Help:        "How many seconds of work has been done that is in progress and hasn't been observed by work_duration",
+				ConstLabels: constLabels,
+			},
+			[]string{"name"},
+		),
+		longestRunning: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace:   _namespace,
+				Name:        "workqueue_longest_running_processor_seconds",
+				Help:        "How many seconds has the longest running processor for workqueue been running",
+				ConstLabels: constLabels,
+			},
+			[]string{"name"},
+		),
+		retries: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace:   _namespace,
+				Name:        "workqueue_retries",
+				Help:        "Number of retries handled by workqueue",
+				ConstLabels: constLabels,
+			},
+			[]string{"name"},
+		),
+	}
+}
+
+func (p *workqueueMetricsProvider) NewDepthMetric(name string) workqueue.GaugeMetric {
+	return p.depth.WithLabelValues(name)
+}
+
+func (p *workqueueMetricsProvider) NewAddsMetric(name string) workqueue.CounterMetric {
+	return p.adds.WithLabelValues(name)
+}
+
+func (p *workqueueMetricsProvider) NewLatencyMetric(name string) workqueue.HistogramMetric {
+	return p.latency.WithLabelValues(name)
+}
+
+func (p *workqueueMetricsProvider) NewWorkDurationMetric(name string) workqueue.HistogramMetric {
+	return p.workDuration.WithLabelValues(name)
+}
+
+func (p *workqueueMetricsProvider) NewUnfinishedWorkSecondsMetric(name string) workqueue.SettableGaugeMetric {
+	return p.unfinished.WithLabelValues(name)
+}
+
+func (p *workqueueMetricsProvider) NewLongestRunningProcessorSecondsMetric(name string) workqueue.SettableGaugeMetric {
+	return p.longestRunning.WithLabelValues(name)
+}
+
+func (p *workqueueMetricsProvider) NewRetriesMetric(name string) workqueue.CounterMetric {
+	return p.retries.WithLabelValues(name)
+}
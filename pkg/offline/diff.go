@@ -0,0 +1,238 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package offline
+
+import (
+	"context"
+
+	"github.com/apache/apisix-ingress-controller/pkg/apisix"
+	apisixv1 "github.com/apache/apisix-ingress-controller/pkg/types/apisix/v1"
+)
+
+// Fetch lists routes, upstreams, ssls, stream routes and consumers which are
+// managed by apisix-ingress-controller from the APISIX cluster.
+func Fetch(ctx context.Context, cluster apisix.Cluster) (*Result, error) {
+	result := &Result{}
+
+	routes, err := cluster.Route().List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range routes {
+		if isManaged(r.Labels) {
+			result.Routes = append(result.Routes, r)
+		}
+	}
+	upstreams, err := cluster.Upstream().List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, u := range upstreams {
+		if isManaged(u.Labels) {
+			result.Upstreams = append(result.Upstreams, u)
+		}
+	}
+	ssls, err := cluster.SSL().List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, ssl := range ssls {
+		if isManaged(ssl.Labels) {
+			result.SSLs = append(result.SSLs, ssl)
+		}
+	}
+	streamRoutes, err := cluster.StreamRoute().List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, sr := range streamRoutes {
+		if isManaged(sr.Labels) {
+			result.StreamRoutes = append(result.StreamRoutes, sr)
+		}
+	}
+	consumers, err := cluster.Consumer().List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range consumers {
+		if isManaged(c.Labels) {
+			result.Consumers = append(result.Consumers, c)
+		}
+	}
+
+	result.sort()
+	return result, nil
+}
+
+func isManaged(labels map[string]string) bool {
+	return labels[apisixv1.LabelManagedBy] == apisixv1.LabelManagedByValue
+}
+
+// Diff compares the current APISIX resources with the desired ones, and
+// returns the resources to be added, changed and deleted. Fields filled by
+// APISIX with default values are not treated as changes (see apisixv1.Covers).
+// Global rules are not compared.
+func Diff(current, desired *Result) (added, changed, deleted *Result) {
+	added = &Result{}
+	changed = &Result{}
+	deleted = &Result{}
+
+	added.Routes, changed.Routes, deleted.Routes = diffRoutes(current.Routes, desired.Routes)
+	added.Upstreams, changed.Upstreams, deleted.Upstreams = diffUpstreams(current.Upstreams, desired.Upstreams)
+	added.SSLs, changed.SSLs, deleted.SSLs = diffSSLs(current.SSLs, desired.SSLs)
+	added.StreamRoutes, changed.StreamRoutes, deleted.StreamRoutes = diffStreamRoutes(current.StreamRoutes, desired.StreamRoutes)
+	added.Consumers, changed.Consumers, deleted.Consumers = diffConsumers(current.Consumers, desired.Consumers)
+	return
+}
+
+// Empty returns true if the Result contains nothing.
+func (r *Result) Empty() bool {
+	return len(r.Routes) == 0 && len(r.Upstreams) == 0 && len(r.SSLs) == 0 &&
+		len(r.StreamRoutes) == 0 && len(r.Consumers) == 0 && len(r.GlobalRules) == 0
+}
+
+func diffRoutes(olds, news []*apisixv1.Route) (added, updated, deleted []*apisixv1.Route) {
+	oldMap := make(map[string]*apisixv1.Route, len(olds))
+	newMap := make(map[string]*apisixv1.Route, len(news))
+	for _, r := range olds {
+		oldMap[r.ID] = r
+	}
+	for _, r := range news {
+		newMap[r.ID] = r
+	}
+
+	for _, r := range news {
+		if or, ok := oldMap[r.ID]; !ok {
+			added = append(added, r)
+		} else if !apisixv1.Covers(or, r) {
+			updated = append(updated, r)
+		}
+	}
+	for _, r := range olds {
+		if _, ok := newMap[r.ID]; !ok {
+			deleted = append(deleted, r)
+		}
+	}
+	return
+}
+
+func diffUpstreams(olds, news []*apisixv1.Upstream) (added, updated, deleted []*apisixv1.Upstream) {
+	oldMap := make(map[string]*apisixv1.Upstream, len(olds))
+	newMap := make(map[string]*apisixv1.Upstream, len(news))
+	for _, u := range olds {
+		oldMap[u.ID] = u
+	}
+	for _, u := range news {
+		newMap[u.ID] = u
+	}
+
+	for _, u := range news {
+		ou, ok := oldMap[u.ID]
+		if !ok {
+			added = append(added, u)
+			continue
+		}
+		// Upstreams might be shared by multiple objects, so the
+		// source labels are not compared.
+		desired := *u
+		desired.Labels = apisixv1.TrimSourceLabels(u.Labels)
+		pushed := *ou
+		pushed.Labels = apisixv1.TrimSourceLabels(ou.Labels)
+		if !apisixv1.Covers(&pushed, &desired) {
+			updated = append(updated, u)
+		}
+	}
+	for _, u := range olds {
+		if _, ok := newMap[u.ID]; !ok {
+			deleted = append(deleted, u)
+		}
+	}
+	return
+}
+
+func diffSSLs(olds, news []*apisixv1.Ssl) (added, updated, deleted []*apisixv1.Ssl) {
+	oldMap := make(map[string]*apisixv1.Ssl, len(olds))
+	newMap := make(map[string]*apisixv1.Ssl, len(news))
+	for _, ssl := range olds {
+		oldMap[ssl.ID] = ssl
+	}
+	for _, ssl := range news {
+		newMap[ssl.ID] = ssl
+	}
+
+	for _, ssl := range news {
+		if ossl, ok := oldMap[ssl.ID]; !ok {
+			added = append(added, ssl)
+		} else if !apisixv1.Covers(ossl, ssl) {
+			updated = append(updated, ssl)
+		}
+	}
+	for _, ssl := range olds {
+		if _, ok := newMap[ssl.ID]; !ok {
+			deleted = append(deleted, ssl)
+		}
+	}
+	return
+}
+
+func diffStreamRoutes(olds, news []*apisixv1.StreamRoute) (added, updated, deleted []*apisixv1.StreamRoute) {
+	oldMap := make(map[string]*apisixv1.StreamRoute, len(olds))
+	newMap := make(map[string]*apisixv1.StreamRoute, len(news))
+	for _, sr := range olds {
+		oldMap[sr.ID] = sr
+	}
+	for _, sr := range news {
+		newMap[sr.ID] = sr
+	}
+
+	for _, sr := range news {
+		if osr, ok := oldMap[sr.ID]; !ok {
+			added = append(added, sr)
+		} else if !apisixv1.Covers(osr, sr) {
+			updated = append(updated, sr)
+		}
+	}
+	for _, sr := range olds {
+		if _, ok := newMap[sr.ID]; !ok {
+			deleted = append(deleted, sr)
+		}
+	}
+	return
+}
+
+func diffConsumers(olds, news []*apisixv1.Consumer) (added, updated, deleted []*apisixv1.Consumer) {
+	oldMap := make(map[string]*apisixv1.Consumer, len(olds))
+	newMap := make(map[string]*apisixv1.Consumer, len(news))
+	for _, c := range olds {
+		oldMap[c.Username] = c
+	}
+	for _, c := range news {
+		newMap[c.Username] = c
+	}
+
+	for _, c := range news {
+		if oc, ok := oldMap[c.Username]; !ok {
+			added = append(added, c)
+		} else if !apisixv1.Covers(oc, c) {
+			updated = append(updated, c)
+		}
+	}
+	for _, c := range olds {
+		if _, ok := newMap[c.Username]; !ok {
+			deleted = append(deleted, c)
+		}
+	}
+	return
+}
@@ -0,0 +1,73 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package offline
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	apisixv1 "github.com/apache/apisix-ingress-controller/pkg/types/apisix/v1"
+)
+
+func TestDiff(t *testing.T) {
+	// Plugins decoded from the Admin API are generic maps.
+	current := &Result{
+		Consumers: []*apisixv1.Consumer{
+			{
+				Username: "default_jack",
+				Plugins: apisixv1.Plugins{
+					"key-auth": map[string]interface{}{
+						"key": "abc",
+					},
+				},
+			},
+			{
+				Username: "default_rose",
+			},
+		},
+	}
+	desired := &Result{
+		Consumers: []*apisixv1.Consumer{
+			{
+				Username: "default_jack",
+				Plugins: apisixv1.Plugins{
+					"key-auth": &apisixv1.KeyAuthConsumerConfig{
+						Key: "abc",
+					},
+				},
+			},
+		},
+		SSLs: []*apisixv1.Ssl{
+			{
+				ID:   "1",
+				Snis: []string{"httpbin.org"},
+			},
+		},
+	}
+
+	added, changed, deleted := Diff(current, desired)
+	assert.Len(t, added.SSLs, 1)
+	assert.Len(t, added.Consumers, 0)
+	assert.True(t, changed.Empty())
+	assert.Len(t, deleted.Consumers, 1)
+	assert.Equal(t, "default_rose", deleted.Consumers[0].Username)
+
+	desired.Consumers[0].Plugins["key-auth"] = &apisixv1.KeyAuthConsumerConfig{
+		Key: "def",
+	}
+	_, changed, _ = Diff(current, desired)
+	assert.Len(t, changed.Consumers, 1)
+}
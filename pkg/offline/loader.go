@@ -0,0 +1,235 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package offline translates Kubernetes objects to APISIX resources outside
+// of the controller, it's used by the command line tools.
+package offline
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	extensionsv1beta1 "k8s.io/api/extensions/v1beta1"
+	networkingv1 "k8s.io/api/networking/v1"
+	networkingv1beta1 "k8s.io/api/networking/v1beta1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/kubernetes"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	kubescheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/yaml"
+
+	configv1 "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/apis/config/v1"
+	configv2alpha1 "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/apis/config/v2alpha1"
+	configv2beta1 "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/apis/config/v2beta1"
+	clientset "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/client/clientset/versioned"
+	apisixfake "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/client/clientset/versioned/fake"
+	apisixscheme "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/client/clientset/versioned/scheme"
+	"github.com/apache/apisix-ingress-controller/pkg/log"
+)
+
+const _defaultNamespace = "default"
+
+var _scheme = runtime.NewScheme()
+
+func init() {
+	utilruntime.Must(kubescheme.AddToScheme(_scheme))
+	utilruntime.Must(apisixscheme.AddToScheme(_scheme))
+}
+
+// LoadObjects reads Kubernetes objects from the given paths, a path can be
+// either a file or a directory (files with suffix .yaml, .yml and .json
+// inside it will be read, not recursively). Multiple documents in one file
+// are supported. Objects which are not used by the translation are ignored.
+func LoadObjects(paths ...string) ([]runtime.Object, error) {
+	var objs []runtime.Object
+	for _, path := range paths {
+		files, err := listFiles(path)
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range files {
+			fileObjs, err := loadFile(file)
+			if err != nil {
+				return nil, err
+			}
+			objs = append(objs, fileObjs...)
+		}
+	}
+	return objs, nil
+}
+
+func listFiles(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+	entries, err := ioutil.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		switch strings.ToLower(filepath.Ext(entry.Name())) {
+		case ".yaml", ".yml", ".json":
+			files = append(files, filepath.Join(path, entry.Name()))
+		}
+	}
+	return files, nil
+}
+
+func loadFile(file string) ([]runtime.Object, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var (
+		objs    []runtime.Object
+		decoder = serializer.NewCodecFactory(_scheme).UniversalDeserializer()
+		reader  = utilyaml.NewYAMLReader(bufio.NewReader(f))
+	)
+	for {
+		doc, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", file, err)
+		}
+		data, err := yaml.YAMLToJSON(doc)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", file, err)
+		}
+		// Documents which only have comments.
+		if len(data) == 0 || bytes.Equal(data, []byte("null")) {
+			continue
+		}
+		obj, gvk, err := decoder.Decode(data, nil, nil)
+		if err != nil {
+			if runtime.IsNotRegisteredError(err) {
+				log.Warnw("ignore unknown object",
+					zap.String("file", file),
+					zap.Error(err),
+				)
+				continue
+			}
+			return nil, fmt.Errorf("%s: %s", file, err)
+		}
+		if !isSupported(obj) {
+			log.Warnw("ignore object which doesn't participate in translation",
+				zap.String("file", file),
+				zap.String("kind", gvk.String()),
+			)
+			continue
+		}
+		if err := defaultNamespace(obj); err != nil {
+			return nil, fmt.Errorf("%s: %s", file, err)
+		}
+		objs = append(objs, obj)
+	}
+	return objs, nil
+}
+
+func isSupported(obj runtime.Object) bool {
+	switch obj.(type) {
+	case *networkingv1.Ingress, *networkingv1beta1.Ingress, *extensionsv1beta1.Ingress,
+		*corev1.Service, *corev1.Endpoints, *corev1.Secret,
+		*configv1.ApisixRoute, *configv2alpha1.ApisixRoute, *configv2beta1.ApisixRoute,
+		*configv1.ApisixUpstream, *configv1.ApisixTls, *configv2alpha1.ApisixConsumer,
+		*configv2alpha1.ApisixClusterConfig:
+		return true
+	default:
+		return false
+	}
+}
+
+func defaultNamespace(obj runtime.Object) error {
+	// ApisixClusterConfig is the only cluster scoped resource.
+	if _, ok := obj.(*configv2alpha1.ApisixClusterConfig); ok {
+		return nil
+	}
+	m, err := meta.Accessor(obj)
+	if err != nil {
+		return err
+	}
+	if m.GetNamespace() == "" {
+		m.SetNamespace(_defaultNamespace)
+	}
+	return nil
+}
+
+// NewFakeClients creates clients backed by an in-memory object tracker,
+// which contain the given objects. When an object appears more than once,
+// the last one wins.
+func NewFakeClients(objs []runtime.Object) (kubernetes.Interface, clientset.Interface) {
+	var (
+		kubeObjs   []runtime.Object
+		apisixObjs []runtime.Object
+	)
+	for _, obj := range dedupObjects(objs) {
+		switch obj.(type) {
+		case *configv1.ApisixRoute, *configv2alpha1.ApisixRoute, *configv2beta1.ApisixRoute,
+			*configv1.ApisixUpstream, *configv1.ApisixTls, *configv2alpha1.ApisixConsumer,
+			*configv2alpha1.ApisixClusterConfig:
+			apisixObjs = append(apisixObjs, obj)
+		default:
+			kubeObjs = append(kubeObjs, obj)
+		}
+	}
+	return kubefake.NewSimpleClientset(kubeObjs...), apisixfake.NewSimpleClientset(apisixObjs...)
+}
+
+func dedupObjects(objs []runtime.Object) []runtime.Object {
+	var (
+		deduped []runtime.Object
+		indices = make(map[string]int)
+	)
+	for _, obj := range objs {
+		m, err := meta.Accessor(obj)
+		if err != nil {
+			continue
+		}
+		key := fmt.Sprintf("%T/%s/%s", obj, m.GetNamespace(), m.GetName())
+		if idx, ok := indices[key]; ok {
+			log.Warnw("object defined more than once, the last one wins",
+				zap.String("object", key),
+			)
+			deduped[idx] = obj
+			continue
+		}
+		indices[key] = len(deduped)
+		deduped = append(deduped, obj)
+	}
+	return deduped
+}
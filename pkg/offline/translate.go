@@ -0,0 +1,328 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package offline
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/hashicorp/go-multierror"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	listersextensionsv1beta1 "k8s.io/client-go/listers/extensions/v1beta1"
+	listersnetworkingv1 "k8s.io/client-go/listers/networking/v1"
+	listersnetworkingv1beta1 "k8s.io/client-go/listers/networking/v1beta1"
+
+	"github.com/apache/apisix-ingress-controller/pkg/config"
+	"github.com/apache/apisix-ingress-controller/pkg/kube"
+	clientset "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/client/clientset/versioned"
+	"github.com/apache/apisix-ingress-controller/pkg/kube/apisix/client/informers/externalversions"
+	listersv1 "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/client/listers/config/v1"
+	listersv2alpha1 "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/client/listers/config/v2alpha1"
+	listersv2beta1 "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/client/listers/config/v2beta1"
+	"github.com/apache/apisix-ingress-controller/pkg/kube/translation"
+	"github.com/apache/apisix-ingress-controller/pkg/types"
+	apisixv1 "github.com/apache/apisix-ingress-controller/pkg/types/apisix/v1"
+)
+
+const _ingressClassAnnotation = "kubernetes.io/ingress.class"
+
+// Options contains options to control the translation.
+type Options struct {
+	// IngressClass filters Ingresses by the ingress class, all Ingresses
+	// will be translated if it's empty.
+	IngressClass string
+	// IngressVersion specifies the group version of Ingresses to be translated,
+	// all versions will be translated if it's empty.
+	IngressVersion string
+	// ApisixRouteVersion specifies the version of ApisixRoutes to be translated,
+	// all versions will be translated if it's empty.
+	ApisixRouteVersion string
+}
+
+// Result contains the APISIX resources generated by the translation.
+type Result struct {
+	Routes       []*apisixv1.Route       `json:"routes,omitempty" yaml:"routes,omitempty"`
+	Upstreams    []*apisixv1.Upstream    `json:"upstreams,omitempty" yaml:"upstreams,omitempty"`
+	SSLs         []*apisixv1.Ssl         `json:"ssls,omitempty" yaml:"ssls,omitempty"`
+	StreamRoutes []*apisixv1.StreamRoute `json:"stream_routes,omitempty" yaml:"stream_routes,omitempty"`
+	Consumers    []*apisixv1.Consumer    `json:"consumers,omitempty" yaml:"consumers,omitempty"`
+	GlobalRules  []*apisixv1.GlobalRule  `json:"global_rules,omitempty" yaml:"global_rules,omitempty"`
+
+	upstreamMap map[string]struct{}
+}
+
+func (r *Result) addTranslateContext(tctx *translation.TranslateContext) {
+	r.Routes = append(r.Routes, tctx.Routes...)
+	r.StreamRoutes = append(r.StreamRoutes, tctx.StreamRoutes...)
+	r.SSLs = append(r.SSLs, tctx.SSLs...)
+	for _, ups := range tctx.Upstreams {
+		// Upstreams can be shared by different objects.
+		if _, ok := r.upstreamMap[ups.ID]; ok {
+			continue
+		}
+		r.upstreamMap[ups.ID] = struct{}{}
+		r.Upstreams = append(r.Upstreams, ups)
+	}
+}
+
+func (r *Result) sort() {
+	sort.Slice(r.Routes, func(i, j int) bool { return r.Routes[i].ID < r.Routes[j].ID })
+	sort.Slice(r.Upstreams, func(i, j int) bool { return r.Upstreams[i].ID < r.Upstreams[j].ID })
+	sort.Slice(r.SSLs, func(i, j int) bool { return r.SSLs[i].ID < r.SSLs[j].ID })
+	sort.Slice(r.StreamRoutes, func(i, j int) bool { return r.StreamRoutes[i].ID < r.StreamRoutes[j].ID })
+	sort.Slice(r.Consumers, func(i, j int) bool { return r.Consumers[i].Username < r.Consumers[j].Username })
+	sort.Slice(r.GlobalRules, func(i, j int) bool { return r.GlobalRules[i].ID < r.GlobalRules[j].ID })
+}
+
+// Translate lists Ingresses, ApisixRoutes, ApisixTlses, ApisixConsumers and
+// ApisixClusterConfigs through the given clients and translates them to APISIX
+// resources. Objects which fail to be translated are skipped, and the reasons
+// are returned as the error, with the partial Result.
+func Translate(ctx context.Context, kubeClient kubernetes.Interface, apisixClient clientset.Interface, opts *Options) (*Result, error) {
+	kubeFactory := informers.NewSharedInformerFactory(kubeClient, 0)
+	apisixFactory := externalversions.NewSharedInformerFactory(apisixClient, 0)
+
+	epLister, _ := kube.NewEndpointListerAndInformer(kubeFactory, false)
+	tr := translation.NewTranslator(&translation.TranslatorOptions{
+		PodCache:             types.NewPodCache(),
+		PodLister:            kubeFactory.Core().V1().Pods().Lister(),
+		EndpointLister:       epLister,
+		ServiceLister:        kubeFactory.Core().V1().Services().Lister(),
+		ApisixUpstreamLister: apisixFactory.Apisix().V1().ApisixUpstreams().Lister(),
+		SecretLister:         kubeFactory.Core().V1().Secrets().Lister(),
+	})
+
+	var (
+		ingV1Lister        listersnetworkingv1.IngressLister
+		ingV1beta1Lister   listersnetworkingv1beta1.IngressLister
+		ingExtensionLister listersextensionsv1beta1.IngressLister
+		arV1Lister         listersv1.ApisixRouteLister
+		arV2alpha1Lister   listersv2alpha1.ApisixRouteLister
+		arV2beta1Lister    listersv2beta1.ApisixRouteLister
+
+		tlsLister        = apisixFactory.Apisix().V1().ApisixTlses().Lister()
+		consumerLister   = apisixFactory.Apisix().V2alpha1().ApisixConsumers().Lister()
+		clusterCfgLister = apisixFactory.Apisix().V2alpha1().ApisixClusterConfigs().Lister()
+	)
+
+	// Only create informers for the versions that will be translated, as
+	// some of them may not be served by the API Server.
+	if opts.IngressVersion == "" || opts.IngressVersion == config.IngressNetworkingV1 {
+		ingV1Lister = kubeFactory.Networking().V1().Ingresses().Lister()
+	}
+	if opts.IngressVersion == "" || opts.IngressVersion == config.IngressNetworkingV1beta1 {
+		ingV1beta1Lister = kubeFactory.Networking().V1beta1().Ingresses().Lister()
+	}
+	if opts.IngressVersion == "" || opts.IngressVersion == config.IngressExtensionsV1beta1 {
+		ingExtensionLister = kubeFactory.Extensions().V1beta1().Ingresses().Lister()
+	}
+	if opts.ApisixRouteVersion == "" || opts.ApisixRouteVersion == config.ApisixRouteV1 {
+		arV1Lister = apisixFactory.Apisix().V1().ApisixRoutes().Lister()
+	}
+	if opts.ApisixRouteVersion == "" || opts.ApisixRouteVersion == config.ApisixRouteV2alpha1 {
+		arV2alpha1Lister = apisixFactory.Apisix().V2alpha1().ApisixRoutes().Lister()
+	}
+	if opts.ApisixRouteVersion == "" || opts.ApisixRouteVersion == config.ApisixRouteV2beta1 {
+		arV2beta1Lister = apisixFactory.Apisix().V2beta1().ApisixRoutes().Lister()
+	}
+
+	kubeFactory.Start(ctx.Done())
+	apisixFactory.Start(ctx.Done())
+	for typ, ok := range kubeFactory.WaitForCacheSync(ctx.Done()) {
+		if !ok {
+			return nil, fmt.Errorf("failed to sync cache for %s", typ)
+		}
+	}
+	for typ, ok := range apisixFactory.WaitForCacheSync(ctx.Done()) {
+		if !ok {
+			return nil, fmt.Errorf("failed to sync cache for %s", typ)
+		}
+	}
+
+	var (
+		merr   *multierror.Error
+		result = &Result{
+			upstreamMap: make(map[string]struct{}),
+		}
+	)
+	appendErr := func(kind, namespace, name string, err error) {
+		merr = multierror.Append(merr, fmt.Errorf("%s %s/%s: %s", kind, namespace, name, err))
+	}
+
+	var ings []kube.Ingress
+	if ingV1Lister != nil {
+		objs, err := ingV1Lister.List(labels.Everything())
+		if err != nil {
+			return nil, err
+		}
+		for _, obj := range objs {
+			ings = append(ings, kube.MustNewIngress(obj))
+		}
+	}
+	if ingV1beta1Lister != nil {
+		objs, err := ingV1beta1Lister.List(labels.Everything())
+		if err != nil {
+			return nil, err
+		}
+		for _, obj := range objs {
+			ings = append(ings, kube.MustNewIngress(obj))
+		}
+	}
+	if ingExtensionLister != nil {
+		objs, err := ingExtensionLister.List(labels.Everything())
+		if err != nil {
+			return nil, err
+		}
+		for _, obj := range objs {
+			ings = append(ings, kube.MustNewIngress(obj))
+		}
+	}
+	for _, ing := range ings {
+		namespace, name, ok := ingressMeta(ing, opts.IngressClass)
+		if !ok {
+			continue
+		}
+		tctx, err := tr.TranslateIngress(ing)
+		if err != nil {
+			appendErr("Ingress", namespace, name, err)
+			continue
+		}
+		result.addTranslateContext(tctx)
+	}
+
+	if arV1Lister != nil {
+		ars, err := arV1Lister.List(labels.Everything())
+		if err != nil {
+			return nil, err
+		}
+		for _, ar := range ars {
+			tctx, err := tr.TranslateRouteV1(ar)
+			if err != nil {
+				appendErr("ApisixRoute", ar.Namespace, ar.Name, err)
+				continue
+			}
+			result.addTranslateContext(tctx)
+		}
+	}
+	if arV2alpha1Lister != nil {
+		ars, err := arV2alpha1Lister.List(labels.Everything())
+		if err != nil {
+			return nil, err
+		}
+		for _, ar := range ars {
+			tctx, err := tr.TranslateRouteV2alpha1(ar)
+			if err != nil {
+				appendErr("ApisixRoute", ar.Namespace, ar.Name, err)
+				continue
+			}
+			result.addTranslateContext(tctx)
+		}
+	}
+	if arV2beta1Lister != nil {
+		ars, err := arV2beta1Lister.List(labels.Everything())
+		if err != nil {
+			return nil, err
+		}
+		for _, ar := range ars {
+			tctx, err := tr.TranslateRouteV2beta1(ar)
+			if err != nil {
+				appendErr("ApisixRoute", ar.Namespace, ar.Name, err)
+				continue
+			}
+			result.addTranslateContext(tctx)
+		}
+	}
+
+	tlses, err := tlsLister.List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	for _, tls := range tlses {
+		ssl, err := tr.TranslateSSL(tls)
+		if err != nil {
+			appendErr("ApisixTls", tls.Namespace, tls.Name, err)
+			continue
+		}
+		result.SSLs = append(result.SSLs, ssl)
+	}
+
+	consumers, err := consumerLister.List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	for _, ac := range consumers {
+		consumer, err := tr.TranslateApisixConsumer(ac)
+		if err != nil {
+			appendErr("ApisixConsumer", ac.Namespace, ac.Name, err)
+			continue
+		}
+		result.Consumers = append(result.Consumers, consumer)
+	}
+
+	clusterCfgs, err := clusterCfgLister.List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	for _, acc := range clusterCfgs {
+		gr, err := tr.TranslateClusterConfig(acc)
+		if err != nil {
+			appendErr("ApisixClusterConfig", acc.Namespace, acc.Name, err)
+			continue
+		}
+		result.GlobalRules = append(result.GlobalRules, gr)
+	}
+
+	result.sort()
+	return result, merr.ErrorOrNil()
+}
+
+// ingressMeta returns the namespace and name of the Ingress, and whether
+// it belongs to the given ingress class.
+func ingressMeta(ing kube.Ingress, ingressClass string) (string, string, bool) {
+	var (
+		namespace string
+		name      string
+		ic        *string
+		ica       string
+	)
+	switch ing.GroupVersion() {
+	case kube.IngressV1:
+		namespace, name = ing.V1().Namespace, ing.V1().Name
+		ic = ing.V1().Spec.IngressClassName
+		ica = ing.V1().GetAnnotations()[_ingressClassAnnotation]
+	case kube.IngressV1beta1:
+		namespace, name = ing.V1beta1().Namespace, ing.V1beta1().Name
+		ic = ing.V1beta1().Spec.IngressClassName
+		ica = ing.V1beta1().GetAnnotations()[_ingressClassAnnotation]
+	default:
+		namespace, name = ing.ExtensionsV1beta1().Namespace, ing.ExtensionsV1beta1().Name
+		ic = ing.ExtensionsV1beta1().Spec.IngressClassName
+		ica = ing.ExtensionsV1beta1().GetAnnotations()[_ingressClassAnnotation]
+	}
+	if ingressClass == "" {
+		return namespace, name, true
+	}
+	// kubernetes.io/ingress.class takes the precedence.
+	if ica != "" {
+		return namespace, name, ica == ingressClass
+	}
+	if ic != nil {
+		return namespace, name, *ic == ingressClass
+	}
+	return namespace, name, false
+}
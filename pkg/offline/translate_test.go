@@ -0,0 +1,130 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package offline
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/apache/apisix-ingress-controller/pkg/config"
+	configv2alpha1 "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/apis/config/v2alpha1"
+)
+
+func TestLoadObjects(t *testing.T) {
+	objs, err := LoadObjects("testdata")
+	assert.Nil(t, err)
+	// The Deployment is ignored.
+	assert.Len(t, objs, 5)
+	for _, obj := range objs {
+		svc, ok := obj.(*corev1.Service)
+		if ok {
+			assert.Equal(t, "default", svc.Namespace)
+		}
+	}
+
+	_, err = LoadObjects("testdata/nonexistent.yaml")
+	assert.NotNil(t, err)
+}
+
+func TestTranslate(t *testing.T) {
+	objs, err := LoadObjects("testdata/manifests.yaml")
+	assert.Nil(t, err)
+
+	kubeClient, apisixClient := NewFakeClients(objs)
+	result, err := Translate(context.Background(), kubeClient, apisixClient, &Options{
+		IngressClass: config.IngressClass,
+	})
+	assert.Nil(t, err)
+	assert.Len(t, result.Routes, 2)
+	// Both the Ingress and the ApisixRoute reference the same upstream.
+	assert.Len(t, result.Upstreams, 1)
+	assert.Equal(t, "default_httpbin_80", result.Upstreams[0].Name)
+	assert.Equal(t, "10.0.5.12", result.Upstreams[0].Nodes[0].Host)
+	assert.Equal(t, 8080, result.Upstreams[0].Nodes[0].Port)
+	for _, r := range result.Routes {
+		assert.Equal(t, result.Upstreams[0].ID, r.UpstreamId)
+	}
+
+	// The Ingress with class nginx is also translated.
+	kubeClient, apisixClient = NewFakeClients(objs)
+	result, err = Translate(context.Background(), kubeClient, apisixClient, &Options{})
+	assert.Nil(t, err)
+	assert.Len(t, result.Routes, 3)
+
+	// Only ApisixRoute apisix.apache.org/v2alpha1 is translated.
+	kubeClient, apisixClient = NewFakeClients(objs)
+	result, err = Translate(context.Background(), kubeClient, apisixClient, &Options{
+		IngressClass:       config.IngressClass,
+		ApisixRouteVersion: config.ApisixRouteV2alpha1,
+	})
+	assert.Nil(t, err)
+	assert.Len(t, result.Routes, 1)
+}
+
+func TestTranslateWithError(t *testing.T) {
+	objs, err := LoadObjects("testdata/manifests.yaml")
+	assert.Nil(t, err)
+	objs = append(objs, &configv2alpha1.ApisixConsumer{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "jack",
+			Namespace: "default",
+		},
+		Spec: configv2alpha1.ApisixConsumerSpec{
+			AuthParameter: configv2alpha1.ApisixConsumerAuthParameter{
+				KeyAuth: &configv2alpha1.ApisixConsumerKeyAuth{
+					SecretRef: &corev1.LocalObjectReference{
+						Name: "jack-key-auth",
+					},
+				},
+			},
+		},
+	})
+
+	kubeClient, apisixClient := NewFakeClients(objs)
+	result, err := Translate(context.Background(), kubeClient, apisixClient, &Options{})
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "ApisixConsumer default/jack")
+	assert.Len(t, result.Routes, 3)
+	assert.Len(t, result.Consumers, 0)
+}
+
+func TestNewFakeClientsWithDuplicatedObjects(t *testing.T) {
+	objs := []runtime.Object{
+		&corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "httpbin",
+				Namespace: "default",
+			},
+		},
+		&corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "httpbin",
+				Namespace: "default",
+				Labels: map[string]string{
+					"app": "httpbin",
+				},
+			},
+		},
+	}
+	kubeClient, _ := NewFakeClients(objs)
+	svc, err := kubeClient.CoreV1().Services("default").Get(context.Background(), "httpbin", metav1.GetOptions{})
+	assert.Nil(t, err)
+	assert.Equal(t, "httpbin", svc.Labels["app"])
+}
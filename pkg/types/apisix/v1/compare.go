@@ -0,0 +1,163 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package v1
+
+import (
+	"encoding/json"
+	"reflect"
+)
+
+// _defaultValues are the values which APISIX fills for absent fields, they
+// are ignored if the desired resource doesn't have the field.
+var _defaultValues = map[string]interface{}{
+	"pass_host": "pass",
+	"scheme":    SchemeHTTP,
+	"hash_on":   "vars",
+	"status":    float64(1),
+	"priority":  float64(0),
+}
+
+// _generatedFields are the fields which APISIX generates, they are ignored
+// if the desired resource doesn't have the field.
+var _generatedFields = map[string]struct{}{
+	"create_time":    {},
+	"update_time":    {},
+	"validity_start": {},
+	"validity_end":   {},
+}
+
+// Covers reports whether the actual resource (usually fetched from APISIX)
+// satisfies the desired one. Resources are compared through their JSON
+// representations, so that typed plugin configurations can be compared with
+// the generic maps decoded from the Admin API. Fields are compared exactly,
+// except the ones filled by APISIX (see _defaultValues and _generatedFields)
+// and the configurations of plugins and health checks, where APISIX fills
+// the defaults of their schemas, so only the fields in the desired ones are
+// compared. The plugin names should be exactly the same.
+func Covers(actual, desired interface{}) bool {
+	var a, d interface{}
+	data, err := json.Marshal(actual)
+	if err != nil {
+		return false
+	}
+	if err := json.Unmarshal(data, &a); err != nil {
+		return false
+	}
+	data, err = json.Marshal(desired)
+	if err != nil {
+		return false
+	}
+	if err := json.Unmarshal(data, &d); err != nil {
+		return false
+	}
+	return equalResource(a, d)
+}
+
+// equalResource compares the resources (or the upstreams embedded in them)
+// in their generic JSON forms.
+func equalResource(actual, desired interface{}) bool {
+	a, ok := actual.(map[string]interface{})
+	if !ok {
+		return reflect.DeepEqual(actual, desired)
+	}
+	d, ok := desired.(map[string]interface{})
+	if !ok {
+		return false
+	}
+	for k, av := range a {
+		if _, ok := d[k]; ok {
+			continue
+		}
+		if _, ok := _generatedFields[k]; ok {
+			continue
+		}
+		if dv, ok := _defaultValues[k]; ok && reflect.DeepEqual(av, dv) {
+			continue
+		}
+		return false
+	}
+	for k, dv := range d {
+		av, ok := a[k]
+		if !ok {
+			return false
+		}
+		switch k {
+		case "plugins":
+			if !equalPlugins(av, dv) {
+				return false
+			}
+		case "checks":
+			if !covers(av, dv) {
+				return false
+			}
+		case "upstream":
+			if !equalResource(av, dv) {
+				return false
+			}
+		default:
+			if !reflect.DeepEqual(av, dv) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// equalPlugins compares the plugins by their names, the configuration of
+// each plugin should cover the desired one.
+func equalPlugins(actual, desired interface{}) bool {
+	ap, _ := actual.(map[string]interface{})
+	dp, _ := desired.(map[string]interface{})
+	if len(ap) != len(dp) {
+		return false
+	}
+	for name, dv := range dp {
+		av, ok := ap[name]
+		if !ok || !covers(av, dv) {
+			return false
+		}
+	}
+	return true
+}
+
+func covers(actual, desired interface{}) bool {
+	switch d := desired.(type) {
+	case map[string]interface{}:
+		a, ok := actual.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		for k, dv := range d {
+			av, ok := a[k]
+			if !ok || !covers(av, dv) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		a, ok := actual.([]interface{})
+		if !ok || len(a) != len(d) {
+			return false
+		}
+		for i := range d {
+			if !covers(a[i], d[i]) {
+				return false
+			}
+		}
+		return true
+	default:
+		return reflect.DeepEqual(actual, desired)
+	}
+}
@@ -0,0 +1,88 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package v1
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCovers(t *testing.T) {
+	desired := NewDefaultUpstream()
+	desired.ID = "1"
+	desired.Nodes = UpstreamNodes{
+		{Host: "10.0.0.1", Port: 80, Weight: 100},
+	}
+
+	// APISIX fills the pass_host field.
+	data := `{"id":"1","type":"roundrobin","pass_host":"pass","scheme":"http","nodes":[{"host":"10.0.0.1","port":80,"weight":100}],"desc":"Created by apisix-ingress-controller, DO NOT modify it manually","labels":{"managed-by":"apisix-ingress-controller"}}`
+	var actualUps map[string]interface{}
+	assert.Nil(t, json.Unmarshal([]byte(data), &actualUps))
+	assert.True(t, Covers(actualUps, desired))
+
+	desired.Nodes[0].Weight = 50
+	assert.False(t, Covers(actualUps, desired))
+
+	var actual Route
+	route := NewDefaultRoute()
+	route.ID = "2"
+	route.Plugins = Plugins{
+		"key-auth": &KeyAuthRouteConfig{},
+	}
+	assert.Nil(t, json.Unmarshal([]byte(`{"id":"2","desc":"Created by apisix-ingress-controller, DO NOT modify it manually","labels":{"managed-by":"apisix-ingress-controller"},"plugins":{"key-auth":{"header":"apikey"}}}`), &actual))
+	assert.True(t, Covers(&actual, route))
+
+	// Plugins added manually.
+	assert.Nil(t, json.Unmarshal([]byte(`{"id":"2","desc":"Created by apisix-ingress-controller, DO NOT modify it manually","labels":{"managed-by":"apisix-ingress-controller"},"plugins":{"key-auth":{},"cors":{}}}`), &actual))
+	assert.False(t, Covers(&actual, route))
+}
+
+func TestCoversExtraFields(t *testing.T) {
+	route := NewDefaultRoute()
+	route.ID = "1"
+	route.Uri = "/ip"
+	route.UpstreamId = "2"
+	base := `"id":"1","uri":"/ip","upstream_id":"2","desc":"Created by apisix-ingress-controller, DO NOT modify it manually","labels":{"managed-by":"apisix-ingress-controller"}`
+
+	var actual map[string]interface{}
+	// Fields generated or filled with the default values by APISIX.
+	assert.Nil(t, json.Unmarshal([]byte(`{`+base+`,"status":1,"priority":0,"create_time":1634000000,"update_time":1634000000}`), &actual))
+	assert.True(t, Covers(actual, route))
+
+	// Hosts added manually.
+	actual = nil
+	assert.Nil(t, json.Unmarshal([]byte(`{`+base+`,"hosts":["httpbin.org"]}`), &actual))
+	assert.False(t, Covers(actual, route))
+
+	// Vars added manually.
+	actual = nil
+	assert.Nil(t, json.Unmarshal([]byte(`{`+base+`,"vars":[["arg_name","==","json"]]}`), &actual))
+	assert.False(t, Covers(actual, route))
+
+	// Fields dropped from the desired route.
+	route.Hosts = []string{"httpbin.org"}
+	actual = nil
+	assert.Nil(t, json.Unmarshal([]byte(`{`+base+`,"hosts":["httpbin.org"]}`), &actual))
+	assert.True(t, Covers(actual, route))
+	route.Hosts = nil
+	assert.False(t, Covers(actual, route))
+
+	// Non default values filled manually.
+	actual = nil
+	assert.Nil(t, json.Unmarshal([]byte(`{`+base+`,"status":0}`), &actual))
+	assert.False(t, Covers(actual, route))
+}
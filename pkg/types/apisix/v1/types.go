@@ -16,11 +16,19 @@ package v1
 
 import (
 	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/apache/apisix-ingress-controller/pkg/log"
 )
 
 const (
@@ -68,6 +76,27 @@ const (
 	// DefaultUpstreamTimeout represents the default connect,
 	// read and send timeout (in seconds) with upstreams.
 	DefaultUpstreamTimeout = 60
+
+	// LabelManagedBy is the label key to mark resources which are
+	// created by apisix-ingress-controller.
+	LabelManagedBy = "managed-by"
+	// LabelManagedByValue is the value of LabelManagedBy.
+	LabelManagedByValue = "apisix-ingress-controller"
+	// LabelSourceKind is the label key to record the kind of the
+	// Kubernetes object that the resource is generated from.
+	LabelSourceKind = "source-kind"
+	// LabelSourceNamespace is the label key to record the namespace
+	// of the source Kubernetes object.
+	LabelSourceNamespace = "source-namespace"
+	// LabelSourceName is the label key to record the name of the
+	// source Kubernetes object.
+	LabelSourceName = "source-name"
+	// LabelSourceUID is the label key to record the UID of the
+	// source Kubernetes object.
+	LabelSourceUID = "source-uid"
+	// MaxLabelValueLength is the maximum length of label values accepted
+	// by APISIX.
+	MaxLabelValueLength = 64
 )
 
 // Metadata contains all meta information about resources.
@@ -97,6 +126,57 @@ type Route struct {
 	Plugins         Plugins  `json:"plugins,omitempty" yaml:"plugins,omitempty"`
 }
 
+// ReferencedUpstreams returns IDs of upstreams referenced by the route,
+// including the ones in the traffic-split plugin.
+func (r *Route) ReferencedUpstreams() []string {
+	var ids []string
+	if r.UpstreamId != "" {
+		ids = append(ids, r.UpstreamId)
+	}
+	plugin, ok := r.Plugins["traffic-split"]
+	if !ok || plugin == nil {
+		return ids
+	}
+	var cfg TrafficSplitConfig
+	switch v := plugin.(type) {
+	case *TrafficSplitConfig:
+		if v == nil {
+			return ids
+		}
+		cfg = *v
+	case TrafficSplitConfig:
+		cfg = v
+	default:
+		// Plugins are decoded as generic maps if they come from APISIX
+		// or are deep copied.
+		data, err := json.Marshal(plugin)
+		if err != nil {
+			return ids
+		}
+		if err := json.Unmarshal(data, &cfg); err != nil {
+			return ids
+		}
+	}
+	for _, rule := range cfg.Rules {
+		for _, wu := range rule.WeightedUpstreams {
+			if wu.UpstreamID == "" {
+				continue
+			}
+			found := false
+			for _, id := range ids {
+				if id == wu.UpstreamID {
+					found = true
+					break
+				}
+			}
+			if !found {
+				ids = append(ids, wu.UpstreamID)
+			}
+		}
+	}
+	return ids
+}
+
 // Vars represents the route match expressions of APISIX.
 type Vars [][]StringOrSlice
 
@@ -305,6 +385,25 @@ type Ssl struct {
 	Client *MutualTLSClientConfig `json:"client,omitempty" yaml:"client,omitempty"`
 }
 
+// MarshalLogObject implements the zapcore.ObjectMarshaler interface, the
+// private key is redacted.
+func (s *Ssl) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddString("id", s.ID)
+	zap.Strings("snis", s.Snis).AddTo(enc)
+	enc.AddString("cert", s.Cert)
+	if s.Key != "" {
+		enc.AddString("key", log.Redacted)
+	}
+	enc.AddInt("status", s.Status)
+	if err := enc.AddReflected("labels", s.Labels); err != nil {
+		return err
+	}
+	if s.Client != nil {
+		return enc.AddReflected("client", s.Client)
+	}
+	return nil
+}
+
 // MutualTLSClientConfig apisix SSL client field
 // +k8s:deepcopy-gen=true
 type MutualTLSClientConfig struct {
@@ -340,6 +439,23 @@ type Consumer struct {
 	Plugins  Plugins           `json:"plugins,omitempty" yaml:"plugins,omitempty"`
 }
 
+// MarshalLogObject implements the zapcore.ObjectMarshaler interface, only
+// names of plugins are logged as their configurations contain credentials.
+func (c *Consumer) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddString("username", c.Username)
+	enc.AddString("desc", c.Desc)
+	if err := enc.AddReflected("labels", c.Labels); err != nil {
+		return err
+	}
+	plugins := make([]string, 0, len(c.Plugins))
+	for name := range c.Plugins {
+		plugins = append(plugins, name)
+	}
+	sort.Strings(plugins)
+	zap.Strings("plugins", plugins).AddTo(enc)
+	return nil
+}
+
 // NewDefaultUpstream returns an empty Upstream with default values.
 func NewDefaultUpstream() *Upstream {
 	return &Upstream{
@@ -350,7 +466,7 @@ func NewDefaultUpstream() *Upstream {
 		Metadata: Metadata{
 			Desc: "Created by apisix-ingress-controller, DO NOT modify it manually",
 			Labels: map[string]string{
-				"managed-by": "apisix-ingress-controller",
+				LabelManagedBy: LabelManagedByValue,
 			},
 		},
 	}
@@ -362,7 +478,7 @@ func NewDefaultRoute() *Route {
 		Metadata: Metadata{
 			Desc: "Created by apisix-ingress-controller, DO NOT modify it manually",
 			Labels: map[string]string{
-				"managed-by": "apisix-ingress-controller",
+				LabelManagedBy: LabelManagedByValue,
 			},
 		},
 	}
@@ -373,7 +489,7 @@ func NewDefaultStreamRoute() *StreamRoute {
 	return &StreamRoute{
 		Desc: "Created by apisix-ingress-controller, DO NOT modify it manually",
 		Labels: map[string]string{
-			"managed-by": "apisix-ingress-controller",
+			LabelManagedBy: LabelManagedByValue,
 		},
 	}
 }
@@ -383,11 +499,51 @@ func NewDefaultConsumer() *Consumer {
 	return &Consumer{
 		Desc: "Created by apisix-ingress-controller, DO NOT modify it manually",
 		Labels: map[string]string{
-			"managed-by": "apisix-ingress-controller",
+			LabelManagedBy: LabelManagedByValue,
 		},
 	}
 }
 
+// TrimSourceLabels returns a copy of the labels without the source labels
+// (LabelSourceKind, LabelSourceNamespace, LabelSourceName and LabelSourceUID).
+func TrimSourceLabels(labels map[string]string) map[string]string {
+	if labels == nil {
+		return nil
+	}
+	trimmed := make(map[string]string, len(labels))
+	for k, v := range labels {
+		switch k {
+		case LabelSourceKind, LabelSourceNamespace, LabelSourceName, LabelSourceUID:
+			continue
+		}
+		trimmed[k] = v
+	}
+	return trimmed
+}
+
+// ComposeSourceNameLabel returns the value of LabelSourceName for the name
+// of the source Kubernetes object. Names longer than MaxLabelValueLength
+// are truncated and suffixed with a digest of the whole name.
+func ComposeSourceNameLabel(name string) string {
+	if len(name) <= MaxLabelValueLength {
+		return name
+	}
+	sum := sha256.Sum256([]byte(name))
+	digest := hex.EncodeToString(sum[:8])
+	return name[:MaxLabelValueLength-len(digest)-1] + "-" + digest
+}
+
+// SourceKey returns the key of the source Kubernetes object recorded in the
+// labels, in the form of kind/namespace/name where the name is the value of
+// LabelSourceName. An empty string is returned if there is no source.
+func SourceKey(labels map[string]string) string {
+	kind, name := labels[LabelSourceKind], labels[LabelSourceName]
+	if kind == "" || name == "" {
+		return ""
+	}
+	return kind + "/" + labels[LabelSourceNamespace] + "/" + name
+}
+
 // ComposeUpstreamName uses namespace, name, subset (optional) and port info to compose
 // the upstream name.
 func ComposeUpstreamName(namespace, name, subset string, port int32) string {
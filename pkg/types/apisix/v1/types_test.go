@@ -0,0 +1,68 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package v1
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestRouteReferencedUpstreams(t *testing.T) {
+	r := &Route{}
+	assert.Nil(t, r.ReferencedUpstreams())
+
+	r.UpstreamId = "1"
+	r.Plugins = Plugins{
+		"traffic-split": &TrafficSplitConfig{
+			Rules: []TrafficSplitConfigRule{
+				{
+					WeightedUpstreams: []TrafficSplitConfigRuleWeightedUpstream{
+						{UpstreamID: "2", Weight: 10},
+						{UpstreamID: "1", Weight: 10},
+						// The upstream of the route.
+						{Weight: 80},
+					},
+				},
+			},
+		},
+	}
+	assert.Equal(t, []string{"1", "2"}, r.ReferencedUpstreams())
+
+	// Plugins are generic maps after deep copied.
+	assert.Equal(t, []string{"1", "2"}, r.DeepCopy().ReferencedUpstreams())
+}
+
+func TestMarshalLogObjectRedaction(t *testing.T) {
+	enc := zapcore.NewMapObjectEncoder()
+	ssl := &Ssl{ID: "1", Snis: []string{"a.com"}, Cert: "cert", Key: "private key"}
+	assert.Nil(t, ssl.MarshalLogObject(enc))
+	assert.Equal(t, "cert", enc.Fields["cert"])
+	assert.Equal(t, "******", enc.Fields["key"])
+
+	enc = zapcore.NewMapObjectEncoder()
+	consumer := &Consumer{
+		Username: "jack",
+		Plugins: Plugins{
+			"key-auth":   map[string]interface{}{"key": "secret"},
+			"basic-auth": map[string]interface{}{"username": "jack", "password": "secret"},
+		},
+	}
+	assert.Nil(t, consumer.MarshalLogObject(enc))
+	assert.Equal(t, "jack", enc.Fields["username"])
+	assert.Equal(t, []interface{}{"basic-auth", "key-auth"}, enc.Fields["plugins"])
+	assert.NotContains(t, enc.Fields, "key")
+}
@@ -29,7 +29,7 @@ type TimeDuration struct {
 	time.Duration `json:",inline"`
 }
 
-func (d *TimeDuration) MarshalJSON() ([]byte, error) {
+func (d TimeDuration) MarshalJSON() ([]byte, error) {
 	return json.Marshal(d.Duration.String())
 }
 
@@ -53,7 +53,7 @@ func (d *TimeDuration) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
-func (d *TimeDuration) MarshalYAML() (interface{}, error) {
+func (d TimeDuration) MarshalYAML() (interface{}, error) {
 	return d.Duration.String(), nil
 }
 
@@ -59,7 +59,11 @@ func TestTimeDurationMarshalYAML(t *testing.T) {
 	}
 	data, err := yaml.Marshal(value)
 	assert.Nil(t, err, "failed to marshal value: %s", err)
-	assert.Contains(t, string(data), "15s", "bad marshalled json: %s", string(data))
+	assert.Contains(t, string(data), "interval: 15s", "bad marshalled yaml: %s", string(data))
+
+	data, err = yaml.Marshal(*value)
+	assert.Nil(t, err, "failed to marshal value: %s", err)
+	assert.Contains(t, string(data), "interval: 15s", "bad marshalled yaml: %s", string(data))
 }
 
 func TestTimeDurationUnmarshalYAML(t *testing.T) {
@@ -447,18 +447,19 @@ spec:
 		assert.Equal(ginkgo.GinkgoT(), routes[0].Hosts, []string{"httpbin.com"})
 		assert.Equal(ginkgo.GinkgoT(), routes[0].Desc,
 			"Created by apisix-ingress-controller, DO NOT modify it manually")
-		assert.Equal(ginkgo.GinkgoT(), routes[0].Labels, map[string]string{
-			"managed-by": "apisix-ingress-controller",
-		})
+		assert.Equal(ginkgo.GinkgoT(), routes[0].Labels["managed-by"], "apisix-ingress-controller")
+		assert.Equal(ginkgo.GinkgoT(), routes[0].Labels["source-kind"], "ApisixRoute")
+		assert.Equal(ginkgo.GinkgoT(), routes[0].Labels["source-namespace"], s.Namespace())
+		assert.Equal(ginkgo.GinkgoT(), routes[0].Labels["source-name"], "httpbin-route")
+		assert.NotEmpty(ginkgo.GinkgoT(), routes[0].Labels["source-uid"])
 
 		ups, err := s.ListApisixUpstreams()
 		assert.Nil(ginkgo.GinkgoT(), err, "listing upstreams")
 		assert.Len(ginkgo.GinkgoT(), ups, 1)
 		assert.Equal(ginkgo.GinkgoT(), ups[0].Desc,
 			"Created by apisix-ingress-controller, DO NOT modify it manually")
-		assert.Equal(ginkgo.GinkgoT(), ups[0].Labels, map[string]string{
-			"managed-by": "apisix-ingress-controller",
-		})
+		assert.Equal(ginkgo.GinkgoT(), ups[0].Labels["managed-by"], "apisix-ingress-controller")
+		assert.Equal(ginkgo.GinkgoT(), ups[0].Labels["source-kind"], "ApisixRoute")
 
 		resp := s.NewAPISIXClient().GET("/ip").WithHeader("Host", "httpbin.com").Expect()
 		resp.Status(http.StatusOK)
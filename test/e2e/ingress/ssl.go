@@ -161,9 +161,9 @@ RU+QPRECgYB6XW24EI5+w3STbpnc6VoTS+sy9I9abTJPYo9LpCJwfMYc9Tg9Cx2K
 		assert.Nil(ginkgo.GinkgoT(), err, "list tls error")
 		assert.Len(ginkgo.GinkgoT(), tls, 1, "tls number not expect")
 		assert.Equal(ginkgo.GinkgoT(), tls[0].Snis[0], host, "tls host is error")
-		assert.Equal(ginkgo.GinkgoT(), tls[0].Labels, map[string]string{
-			"managed-by": "apisix-ingress-controller",
-		})
+		assert.Equal(ginkgo.GinkgoT(), tls[0].Labels["managed-by"], "apisix-ingress-controller")
+		assert.Equal(ginkgo.GinkgoT(), tls[0].Labels["source-kind"], "ApisixTls")
+		assert.Equal(ginkgo.GinkgoT(), tls[0].Labels["source-name"], tlsName)
 	})
 	ginkgo.It("delete a SSL from ApisixTls ", func() {
 		secretName := "test-apisix-tls"